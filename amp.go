@@ -5,9 +5,11 @@ package turboamper
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -16,38 +18,15 @@ import (
 )
 
 // AMP gives you amp-representation of html and its type
-// If it cannot recognize your html, it returns simple error.
+// If it cannot recognize your html, it returns ErrUnknownEmbed,
+// if it recognizes but cannot convert it, it returns *EmbedError of the provider.
 func AMP(htmlText []byte) ([]byte, string, error) {
-	got, err := VkToAMP(htmlText)
-	if err == nil {
-		return got, `vkontakte`, nil
-	}
-	got, err = FbToAMP(htmlText)
-	if err == nil {
-		return got, `facebook`, nil
-	}
-	got, err = InstaToAMP(htmlText)
-	if err == nil {
-		return got, `instagram`, nil
-	}
-	got, err = TwitToAMP(htmlText)
-	if err == nil {
-		return got, `twitter`, nil
-	}
-	got, err = YoutubeToAMP(htmlText)
-	if err == nil {
-		return got, `youtube`, nil
-	}
-	got, err = IframeToAMP(htmlText)
-	if err == nil {
-		return got, `iframe`, nil
-	}
-	got, err = PlaybuzzToAMP(htmlText)
-	if err == nil {
-		return got, `playbuzz`, nil
+	got, embed, err := represent(htmlText, renderAMP)
+	if err != nil {
+		return nil, ``, err
 	}
 
-	return nil, ``, fmt.Errorf("unknown embed")
+	return got, embed.Provider, nil
 }
 
 type iframePost struct {
@@ -56,6 +35,7 @@ type iframePost struct {
 	AllowFS     bool
 	Frameborder int64
 	Src         string
+	Scheme      string
 }
 
 // printAMP returns ready to handle AMP with given parameters
@@ -65,7 +45,7 @@ func (ifrPost *iframePost) printAMP() []byte {
 		attributes += ` allowfullscreen`
 	}
 
-	template := `<amp-iframe width="480" height="315" sandbox="allow-scripts allow-same-origin" layout="responsive" frameborder="%d"%s src="%s"`
+	template := `<amp-iframe width="480" height="315" sandbox="allow-scripts allow-same-origin" layout="responsive" frameborder="%d"%s src="%s"></amp-iframe>`
 
 	amp := fmt.Sprintf(template, ifrPost.Frameborder, attributes, ifrPost.Src)
 
@@ -77,8 +57,39 @@ type youtubePost struct {
 	Frameborder int64
 	Width       int64
 	Height      int64
-	VideoID     string
-	Src         string
+	// VideoID is empty for playlists, they are given by list parameter
+	VideoID string
+	Src     string
+	// NoCookie is set for players of youtube-nocookie.com
+	NoCookie bool
+	Params   map[string]string
+}
+
+// youtubeParams are player parameters which are kept, others are tracking and so on
+var youtubeParams = map[string]bool{
+	"autoplay": true,
+	"controls": true,
+	"end":      true,
+	"list":     true,
+	"loop":     true,
+	"mute":     true,
+	"playlist": true,
+	"rel":      true,
+	"start":    true,
+}
+
+// src returns url of youtube player
+func (post *youtubePost) src() string {
+	host := "www.youtube.com"
+	if post.NoCookie {
+		host = "www.youtube-nocookie.com"
+	}
+	videoID := post.VideoID
+	if len(videoID) < 1 {
+		videoID = "videoseries"
+	}
+
+	return "https://" + host + "/embed/" + videoID + encodeParams(post.Params)
 }
 
 // printAMP returns ready to handle AMP with given parameters
@@ -90,9 +101,36 @@ func (post *youtubePost) printAMP() []byte {
 		post.Height = 315
 	}
 
-	template := `<amp-youtube layout="responsive" height="%d" width="%d" data-videoid="%s"></amp-youtube>`
+	keys := make([]string, 0, len(post.Params))
+	for key := range post.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 
-	amp := fmt.Sprintf(template, post.Height, post.Width, post.VideoID)
+	var attributes string
+	if post.NoCookie {
+		attributes += ` credentials="omit"`
+	}
+	for _, key := range keys {
+		val := post.Params[key]
+		if key == "autoplay" {
+			if isTrue(val) {
+				attributes += ` autoplay`
+			}
+			continue
+		}
+		attributes += fmt.Sprintf(` data-param-%s="%s"`, key, html.EscapeString(val))
+	}
+
+	// amp-youtube puts video id into path of player, so playlists are videoseries
+	videoID := post.VideoID
+	if len(videoID) < 1 {
+		videoID = "videoseries"
+	}
+
+	template := `<amp-youtube layout="responsive" height="%d" width="%d"%s data-videoid="%s"></amp-youtube>`
+
+	amp := fmt.Sprintf(template, post.Height, post.Width, attributes, videoID)
 
 	return []byte(amp)
 }
@@ -103,9 +141,16 @@ type tweetPost struct {
 	Width  int64
 	Height int64
 	Src    string
+	// Timeline is `profile`, `likes` or `list` for timelines, it is empty for tweets
+	Timeline string
+	// User is screen name of timeline or owner of list
+	User string
 }
 
 func (post *tweetPost) printAMP() []byte {
+	if len(post.Timeline) > 0 {
+		return post.printTimelineAMP()
+	}
 	if post.Width == 0 {
 		post.Width = 380
 	}
@@ -120,6 +165,32 @@ func (post *tweetPost) printAMP() []byte {
 	return []byte(amp)
 }
 
+// printTimelineAMP returns amp-twitter of profile, likes or list timeline
+func (post *tweetPost) printTimelineAMP() []byte {
+	if post.Width == 0 {
+		post.Width = 375
+	}
+	if post.Height == 0 {
+		post.Height = 472
+	}
+
+	var attributes string
+	switch {
+	case post.Timeline == "list" && len(post.User) > 0:
+		attributes = fmt.Sprintf(` data-timeline-owner-screen-name="%s" data-timeline-slug="%s"`, post.User, post.ID)
+	case post.Timeline == "list":
+		attributes = fmt.Sprintf(` data-timeline-list-id="%s"`, post.ID)
+	default:
+		attributes = fmt.Sprintf(` data-timeline-screen-name="%s"`, post.User)
+	}
+
+	template := `<amp-twitter layout="responsive" height="%d" width="%d" data-timeline-source-type="%s"%s></amp-twitter>`
+
+	amp := fmt.Sprintf(template, post.Height, post.Width, post.Timeline, attributes)
+
+	return []byte(amp)
+}
+
 // instaPost contents instagram data
 type instaPost struct {
 	IsCaptioned bool
@@ -127,6 +198,25 @@ type instaPost struct {
 	Width       int64
 	Height      int64
 	Src         string
+	// Kind is `post`, `reel` or `tv`
+	Kind string
+}
+
+// instaPaths are paths of instagram permalinks by kinds
+var instaPaths = map[string]string{
+	"post": "p",
+	"reel": "reel",
+	"tv":   "tv",
+}
+
+// url returns canonical url of instagram post, reel or video
+func (post *instaPost) url() string {
+	path, ok := instaPaths[post.Kind]
+	if !ok {
+		path = "p"
+	}
+
+	return "https://www.instagram.com/" + path + "/" + post.Shortcode + "/"
 }
 
 func (post *instaPost) printAMP() []byte {
@@ -171,15 +261,71 @@ func (post *playbuzzPost) printAMP() []byte {
 
 // vkPost contents widget data
 type vkPost struct {
+	// Element is id of placeholder element where widget is drawn
+	Element string
+	// Kind is `group` or `playlist` for such widgets, it is empty for posts
+	Kind string
+	// OwnerID is owner of post or playlist or id of group
 	OwnerID int64
-	PostID  int64
-	Hash    string
-	Width   int64
-	Height  int64
+	// PostID is id of post or playlist
+	PostID int64
+	Hash   string
+	Width  int64
+	Height int64
+	// Params are options of group widget, see vkGroupOptions
+	Params map[string]string
 }
 
-// printAMP returns ready to handle AMP with given parameters
+// vkGroupOptions are options of group widget which are passed to its iframe
+var vkGroupOptions = map[string]bool{
+	"mode":     true,
+	"no_cover": true,
+	"wide":     true,
+}
+
+// url returns canonical url of vkontakte post, group or playlist
+func (post *vkPost) url() string {
+	switch post.Kind {
+	case "group":
+		return fmt.Sprintf("https://vk.com/club%d", post.OwnerID)
+	case "playlist":
+		url := fmt.Sprintf("https://vk.com/music/playlist/%d_%d", post.OwnerID, post.PostID)
+		if len(post.Hash) > 0 {
+			url += "_" + post.Hash
+		}
+		return url
+	}
+
+	return fmt.Sprintf("https://vk.com/wall%d_%d", post.OwnerID, post.PostID)
+}
+
+// src returns url of iframe of group or playlist widget
+func (post *vkPost) src() string {
+	params := map[string]string{}
+	if post.Kind == "playlist" {
+		params["oid"] = strconv.FormatInt(post.OwnerID, 10)
+		params["pid"] = strconv.FormatInt(post.PostID, 10)
+		if len(post.Hash) > 0 {
+			params["hash"] = post.Hash
+		}
+
+		return "https://vk.com/widget_playlist.php" + encodeParams(params)
+	}
+
+	for key, val := range post.Params {
+		params[key] = val
+	}
+	params["gid"] = strconv.FormatInt(post.OwnerID, 10)
+
+	return "https://vk.com/widget_community.php" + encodeParams(params)
+}
+
+// printAMP returns ready to handle AMP with given parameters.
+// amp-vk shows posts only, so groups and playlists are amp-iframes of their widgets.
 func (post *vkPost) printAMP() []byte {
+	if len(post.Kind) > 0 {
+		return printPostAMP(post.src(), post.Width, post.Height)
+	}
 	if post.Width == 0 {
 		post.Width = 500
 	}
@@ -199,10 +345,36 @@ type fbPost struct {
 	Height  int64
 	Href    string
 	Src     string
+	// Kind is `comments`, `page` or `like` for social plugins, it is empty for posts and videos
+	Kind string
+	// Params are data attributes of social plugin, see fbAttrs
+	Params map[string]string
+}
+
+// fbClasses are classes of facebook SDK elements and kinds of their embeds
+var fbClasses = map[string]string{
+	"fb-post":     "",
+	"fb-video":    "video",
+	"fb-comments": "comments",
+	"fb-page":     "page",
+	"fb-like":     "like",
+}
+
+// fbClassRe matches classes of facebook SDK elements
+var fbClassRe = regexp.MustCompile(`\bfb-(?:post|video|comments|page|like)\b`)
+
+// fbAttrs are data attributes of social plugins supported by their AMP components
+var fbAttrs = map[string]map[string]bool{
+	"comments": {"numposts": true, "order-by": true, "colorscheme": true},
+	"page":     {"tabs": true, "hide-cover": true, "show-facepile": true, "small-header": true, "hide-cta": true},
+	"like":     {"layout": true, "action": true, "size": true, "share": true, "colorscheme": true},
 }
 
 // printAMP returns ready to handle AMP with given parameters
 func (post *fbPost) printAMP() []byte {
+	if len(post.Kind) > 0 {
+		return post.printPluginAMP()
+	}
 	var attributes string
 	if post.Width == 0 {
 		post.Width = 500
@@ -220,16 +392,70 @@ func (post *fbPost) printAMP() []byte {
 	return []byte(amp)
 }
 
-// FbToAMP convertes given facebook embeddable html to AMP
-func FbToAMP(htmlText []byte) ([]byte, error) {
+// printPluginAMP returns amp-facebook-comments, amp-facebook-page or amp-facebook-like
+func (post *fbPost) printPluginAMP() []byte {
+	keys := make([]string, 0, len(post.Params))
+	for key := range post.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var attributes string
+	for _, key := range keys {
+		attributes += fmt.Sprintf(` data-%s="%s"`, key, html.EscapeString(post.Params[key]))
+	}
+
+	// like button has its own width, so only height is fixed
+	if post.Kind == "like" {
+		template := `<amp-facebook-like height="28" layout="fixed-height"%s data-href="%s"></amp-facebook-like>`
+
+		return []byte(fmt.Sprintf(template, attributes, html.EscapeString(post.Href)))
+	}
+
+	width, height := post.Width, post.Height
+	switch {
+	case post.Kind == "comments" && width == 0:
+		width = 486
+	case width == 0:
+		width = 340
+	}
+	switch {
+	case post.Kind == "comments" && height == 0:
+		height = 657
+	case height == 0:
+		height = 130
+	}
+
+	template := `<amp-facebook-%s height="%d" width="%d" layout="responsive"%s data-href="%s"></amp-facebook-%s>`
+
+	amp := fmt.Sprintf(template, post.Kind, height, width, attributes, html.EscapeString(post.Href), post.Kind)
+
+	return []byte(amp)
+}
+
+// parseFb extracts facebook post data from given embeddable html
+func parseFb(htmlText []byte) (*fbPost, error) {
 	pointerNode, err := html.Parse(bytes.NewReader(htmlText))
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse fb iframe")
+		return nil, embedError(`facebook`, ErrMalformedEmbed, "")
 	}
 	var post fbPost
+	var sdk bool
 
 	var f func(*html.Node)
 	f = func(n *html.Node) {
+		if n.DataAtom == atom.Div {
+			for _, class := range strings.Fields(nodeAttr(n, "class")) {
+				kind, ok := fbClasses[class]
+				if !ok {
+					continue
+				}
+				sdk = true
+				post.Src = nodeAttr(n, "data-href")
+				post.parseSDK(n, kind)
+				return
+			}
+		}
 		if n.DataAtom == atom.Iframe {
 			for _, iframe := range n.Attr {
 				switch iframe.Key {
@@ -258,16 +484,29 @@ func FbToAMP(htmlText []byte) ([]byte, error) {
 	f(pointerNode)
 
 	if !(len(post.Src) > 0) {
-		return nil, fmt.Errorf("no src in the url")
+		return nil, embedError(`facebook`, ErrNoSource, "")
 	}
 
 	urlPtr, err := url.Parse(post.Src)
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse fb url")
+		return nil, embedError(`facebook`, ErrMalformedURL, post.Src)
+	}
+
+	if sdk {
+		// comments and like button may be made for any page
+		if urlPtr.Scheme != "https" && urlPtr.Scheme != "http" {
+			return nil, embedError(`facebook`, ErrMalformedURL, post.Src)
+		}
+		if post.Kind != "comments" && post.Kind != "like" && !strings.Contains(urlPtr.Hostname(), "facebook.com") {
+			return nil, embedError(`facebook`, ErrWrongHost, urlPtr.Hostname())
+		}
+		post.Href = post.Src
+
+		return &post, nil
 	}
 
 	if !strings.Contains(urlPtr.Hostname(), "facebook.com") {
-		return nil, fmt.Errorf("it is not facebook url")
+		return nil, embedError(`facebook`, ErrWrongHost, urlPtr.Hostname())
 	}
 
 	if strings.Contains(urlPtr.Path, "video.php") {
@@ -276,62 +515,209 @@ func FbToAMP(htmlText []byte) ([]byte, error) {
 
 	post.Href = urlPtr.Query().Get("href")
 
+	return &post, nil
+}
+
+// parseSDK extracts sizes and data attributes of facebook SDK element
+// What is that? Look https://developers.facebook.com/docs/plugins
+func (post *fbPost) parseSDK(n *html.Node, kind string) {
+	if kind == "video" {
+		post.IsVideo = true
+	} else {
+		post.Kind = kind
+	}
+
+	for _, a := range n.Attr {
+		key := strings.TrimPrefix(a.Key, "data-")
+		switch {
+		case key == "width":
+			if w, err := strconv.ParseInt(a.Val, 10, 0); err == nil {
+				post.Width = w
+			}
+		case key == "height":
+			if h, err := strconv.ParseInt(a.Val, 10, 0); err == nil {
+				post.Height = h
+			}
+		case fbAttrs[post.Kind][key] && len(a.Val) > 0:
+			if post.Params == nil {
+				post.Params = make(map[string]string)
+			}
+			post.Params[key] = a.Val
+		}
+	}
+}
+
+// FbToAMP convertes given facebook embeddable html to AMP
+func FbToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parseFb(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
 	return post.printAMP(), nil
 }
 
-// VkToAMP convertes given vkontakte widget post to AMP
-// What is that? Look https://vk.com/dev/widget_post
-func VkToAMP(htmlText []byte) ([]byte, error) {
-	if !bytes.Contains(htmlText, []byte(`VK.Widgets.Post`)) {
-		return nil, fmt.Errorf("given string is not a VK widget post")
+// vkCallRe matches calls of vkontakte widgets supported by turboamper
+var vkCallRe = regexp.MustCompile(`VK\.Widgets\.(Post|Group|Playlist)\s*\(`)
+
+// vkHashRe matches access hash of vkontakte post or playlist
+var vkHashRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// parseVk extracts vkontakte widget data from given html.
+// Arguments of widget call are tokenized, so quotes and spaces may be any.
+func parseVk(htmlText []byte) (*vkPost, error) {
+	loc := vkCallRe.FindSubmatchIndex(htmlText)
+	if loc == nil {
+		return nil, embedError(`vkontakte`, ErrMalformedEmbed, "")
 	}
 
-	re := regexp.MustCompile(`VK.Widgets.Post\("vk_post_(-?\d+)_(-?\d+)", (-?\d+), (-?\d+), '(\S+?)'`)
-	widgetParsed := re.FindSubmatch(htmlText)
-	if widgetParsed == nil {
-		return nil, fmt.Errorf("cannot parse vk widget")
+	args, ok := scanCall(htmlText, loc[1])
+	if !ok {
+		return nil, embedError(`vkontakte`, ErrMalformedEmbed, "")
+	}
+
+	var post *vkPost
+	var err error
+	switch string(htmlText[loc[2]:loc[3]]) {
+	case "Group":
+		post, err = parseVkGroup(args)
+	case "Playlist":
+		post, err = parseVkPlaylist(args)
+	default:
+		post, err = parseVkPost(htmlText, loc[0], args)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(args) > 0 && args[0].Kind == "string" {
+		post.Element = args[0].Val
+	}
+
+	// options are the last argument of post and playlist and the second one of group
+	for _, arg := range args {
+		if arg.Kind != "object" {
+			continue
+		}
+		if w, err := strconv.ParseInt(arg.Fields["width"], 10, 0); err == nil {
+			post.Width = w
+		}
+		if h, err := strconv.ParseInt(arg.Fields["height"], 10, 0); err == nil {
+			post.Height = h
+		}
+		if post.Kind != "group" {
+			continue
+		}
+		for key, val := range arg.Fields {
+			if vkGroupOptions[key] && jsNumberRe.MatchString(val) {
+				if post.Params == nil {
+					post.Params = make(map[string]string)
+				}
+				post.Params[key] = val
+			}
+		}
 	}
 
-	// 1st and 3rd, 2nd and 4th should match
-	if string(widgetParsed[1]) != string(widgetParsed[3]) || string(widgetParsed[2]) != string(widgetParsed[4]) {
-		return nil, fmt.Errorf("parsed string does not match Vk widget post format")
+	return post, nil
+}
+
+// parseVkPost extracts post from arguments of VK.Widgets.Post(element, owner, post, hash, options),
+// call is offset of the call in html to report it
+// What is that? Look https://vk.com/dev/widget_post
+func parseVkPost(htmlText []byte, call int, args []jsToken) (*vkPost, error) {
+	if len(args) < 4 || args[0].Kind != "string" || args[1].Kind != "number" || args[2].Kind != "number" || args[3].Kind != "string" {
+		return nil, embedError(`vkontakte`, ErrMalformedEmbed, "")
 	}
 
-	ownerID, err := strconv.ParseInt(string(widgetParsed[1]), 10, 0)
+	ownerID, err := strconv.ParseInt(args[1].Val, 10, 0)
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse owner id")
+		return nil, embedError(`vkontakte`, ErrMalformedEmbed, args[1].Val)
 	}
 
-	postID, err := strconv.ParseInt(string(widgetParsed[2]), 10, 0)
+	postID, err := strconv.ParseInt(args[2].Val, 10, 0)
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse owner id")
+		return nil, embedError(`vkontakte`, ErrMalformedEmbed, args[2].Val)
+	}
+
+	// default element is named by the post, so they should match
+	if strings.HasPrefix(args[0].Val, "vk_post_") && args[0].Val != fmt.Sprintf("vk_post_%d_%d", ownerID, postID) {
+		return nil, embedError(`vkontakte`, ErrMalformedEmbed, string(htmlText[call:args[3].End]))
 	}
 
-	data := &vkPost{OwnerID: ownerID, PostID: postID, Hash: string(widgetParsed[5])}
+	if !vkHashRe.MatchString(args[3].Val) {
+		return nil, embedError(`vkontakte`, ErrMalformedEmbed, args[3].Val)
+	}
+
+	return &vkPost{OwnerID: ownerID, PostID: postID, Hash: args[3].Val}, nil
+}
 
-	// let's extract width
-	whRe := regexp.MustCompile(`VK.Widgets.Post\(.+?{width: (\d+)(?:, height: (\d+))?}\)`)
-	widthHeight := whRe.FindSubmatch(htmlText)
-	if widthHeight != nil {
-		w, err := strconv.ParseInt(string(widthHeight[1]), 10, 0)
-		if err == nil {
-			data.Width = w
+// parseVkGroup extracts group from arguments of VK.Widgets.Group(element, options, group)
+// What is that? Look https://vk.com/dev/widget_community
+func parseVkGroup(args []jsToken) (*vkPost, error) {
+	// element is string and options are object, so group is the first number
+	for _, arg := range args {
+		if arg.Kind != "number" {
+			continue
+		}
+		groupID, err := strconv.ParseInt(arg.Val, 10, 0)
+		if err != nil || groupID < 1 {
+			return nil, embedError(`vkontakte`, ErrMalformedEmbed, arg.Val)
 		}
 
-		h, err := strconv.ParseInt(string(widthHeight[2]), 10, 0)
-		if err == nil {
-			data.Height = h
+		return &vkPost{Kind: "group", OwnerID: groupID}, nil
+	}
+
+	return nil, embedError(`vkontakte`, ErrMalformedEmbed, "")
+}
+
+// parseVkPlaylist extracts playlist from arguments of VK.Widgets.Playlist(element, owner, playlist, hash, options)
+func parseVkPlaylist(args []jsToken) (*vkPost, error) {
+	if len(args) < 3 || args[1].Kind != "number" || args[2].Kind != "number" {
+		return nil, embedError(`vkontakte`, ErrMalformedEmbed, "")
+	}
+
+	ownerID, err := strconv.ParseInt(args[1].Val, 10, 0)
+	if err != nil {
+		return nil, embedError(`vkontakte`, ErrMalformedEmbed, args[1].Val)
+	}
+
+	playlistID, err := strconv.ParseInt(args[2].Val, 10, 0)
+	if err != nil || playlistID < 1 {
+		return nil, embedError(`vkontakte`, ErrMalformedEmbed, args[2].Val)
+	}
+
+	post := &vkPost{Kind: "playlist", OwnerID: ownerID, PostID: playlistID}
+
+	// hash is needed for playlists which are not public
+	if len(args) > 3 && args[3].Kind == "string" {
+		if !vkHashRe.MatchString(args[3].Val) {
+			return nil, embedError(`vkontakte`, ErrMalformedEmbed, args[3].Val)
 		}
+		post.Hash = args[3].Val
 	}
 
-	return data.printAMP(), nil
+	return post, nil
 }
 
-// InstaToAMP convertes given instagram embeddable html to AMP
-func InstaToAMP(htmlText []byte) ([]byte, error) {
+// VkToAMP convertes given vkontakte widget post, group or playlist or video player to AMP
+// What is that? Look https://vk.com/dev/widget_post
+func VkToAMP(htmlText []byte) ([]byte, error) {
+	if !vkCallRe.Match(htmlText) && bytes.Contains(htmlText, []byte(`video_ext.php`)) {
+		return VkVideoToAMP(htmlText)
+	}
+
+	post, err := parseVk(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+// parseInsta extracts instagram post data from given embeddable html
+func parseInsta(htmlText []byte) (*instaPost, error) {
 	pointerNode, err := html.Parse(bytes.NewReader(htmlText))
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse insta html")
+		return nil, embedError(`instagram`, ErrMalformedEmbed, "")
 	}
 	var post instaPost
 
@@ -342,6 +728,8 @@ func InstaToAMP(htmlText []byte) ([]byte, error) {
 				switch bq.Key {
 				case "data-instgrm-permalink":
 					post.Src = bq.Val
+				case "data-instgrm-captioned":
+					post.IsCaptioned = true
 				case "width":
 					w, err := strconv.ParseInt(bq.Val, 10, 0)
 					if err == nil {
@@ -365,55 +753,92 @@ func InstaToAMP(htmlText []byte) ([]byte, error) {
 	f(pointerNode)
 
 	if !(len(post.Src) > 0) {
-		return nil, fmt.Errorf("no src in the url")
+		return nil, embedError(`instagram`, ErrNoSource, "")
 	}
 
 	urlPtr, err := url.Parse(post.Src)
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse url")
+		return nil, embedError(`instagram`, ErrMalformedURL, post.Src)
 	}
 
 	if !strings.Contains(urlPtr.Hostname(), "instagram.com") {
-		return nil, fmt.Errorf("it is not instagram url")
+		return nil, embedError(`instagram`, ErrWrongHost, urlPtr.Hostname())
 	}
 
-	if bytes.Contains(htmlText, []byte(` data-instgrm-captioned`)) {
-		post.IsCaptioned = true
-	}
-
-	re := regexp.MustCompile(`p/(\S+?)/`)
+	// permalink may have name of author before kind, e.g. /rgru/reel/C7kQ2xNPxYz/
+	re := regexp.MustCompile(`^/(?:[A-Za-z0-9._]{1,30}/)?(p|reels?|tv)/([A-Za-z0-9_-]+)/?$`)
 	submatch := re.FindStringSubmatch(urlPtr.Path)
 	if submatch == nil {
-		return nil, fmt.Errorf("instagram url is malformed")
+		return nil, embedError(`instagram`, ErrMalformedURL, post.Src)
+	}
+	post.Shortcode = submatch[2]
+
+	switch submatch[1] {
+	case "p":
+		post.Kind = "post"
+	case "tv":
+		post.Kind = "tv"
+	default:
+		post.Kind = "reel"
+	}
+
+	return &post, nil
+}
+
+// InstaToAMP convertes given instagram embeddable html to AMP
+func InstaToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parseInsta(htmlText)
+	if err != nil {
+		return nil, err
 	}
-	post.Shortcode = submatch[1]
 
 	return post.printAMP(), nil
 }
 
-// TwitToAMP convertes given twitter embeddable html to AMP
-func TwitToAMP(htmlText []byte) ([]byte, error) {
+// twitRe matches urls of twitter and x.com
+var twitRe = regexp.MustCompile(`(?:twitter|//(?:www\.|mobile\.)?x)\.com/`)
+
+// parseTwit extracts tweet or timeline data from given embeddable html
+func parseTwit(htmlText []byte) (*tweetPost, error) {
 	pointerNode, err := html.Parse(bytes.NewReader(htmlText))
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse twitter html")
+		return nil, embedError(`twitter`, ErrMalformedEmbed, "")
 	}
 	var post tweetPost
+	var timeline string
+
+	re := regexp.MustCompile(`^https://(?:(?:www|mobile)\.)?(?:twitter|x)\.com/(?:[A-Za-z0-9_]{1,15}/status(?:es)?|i/web/status)/(\d+)`)
 
 	var f func(*html.Node)
 	f = func(n *html.Node) {
 		if n.DataAtom == atom.A {
+			var href string
+			var isTimeline bool
 			for _, a := range n.Attr {
-				if a.Key == "href" {
-					re := regexp.MustCompile(`https://twitter.com/[a-zA-Z_]{1,15}/status/(\d+)`)
-					submatch := re.FindStringSubmatch(a.Val)
-					if submatch == nil {
-						continue
+				switch a.Key {
+				case "href":
+					href = a.Val
+				case "class":
+					isTimeline = strings.Contains(" "+a.Val+" ", " twitter-timeline ")
+				case "data-width":
+					if w, err := strconv.ParseInt(a.Val, 10, 0); err == nil {
+						post.Width = w
+					}
+				case "data-height":
+					if h, err := strconv.ParseInt(a.Val, 10, 0); err == nil {
+						post.Height = h
 					}
-					post.ID = submatch[1]
-					post.Src = a.Val
-					return
 				}
 			}
+			if isTimeline {
+				timeline = href
+				return
+			}
+			if submatch := re.FindStringSubmatch(href); submatch != nil {
+				post.ID = submatch[1]
+				post.Src = href
+				return
+			}
 		}
 		for c := n.FirstChild; c != nil; c = c.NextSibling {
 			f(c)
@@ -421,18 +846,68 @@ func TwitToAMP(htmlText []byte) ([]byte, error) {
 	}
 	f(pointerNode)
 
+	if len(timeline) > 0 {
+		return parseTimeline(timeline, post.Width, post.Height)
+	}
+
 	if !(len(post.Src) > 0) {
-		return nil, fmt.Errorf("no twitter ID in the url")
+		return nil, embedError(`twitter`, ErrNoSource, "")
+	}
+	// sizes belong to timelines only
+	post.Width, post.Height = 0, 0
+
+	return &post, nil
+}
+
+// parseTimeline extracts profile, likes or list timeline from given url
+// What is that? Look https://developer.x.com/en/docs/twitter-for-websites/timelines/overview
+func parseTimeline(href string, width, height int64) (*tweetPost, error) {
+	urlPtr, err := url.Parse(href)
+	if err != nil {
+		return nil, embedError(`twitter`, ErrMalformedURL, href)
+	}
+
+	if !regexp.MustCompile(`^(?:(?:www|mobile)\.)?(?:twitter|x)\.com$`).MatchString(urlPtr.Hostname()) {
+		return nil, embedError(`twitter`, ErrWrongHost, urlPtr.Hostname())
+	}
+
+	path := strings.TrimSuffix(urlPtr.Path, "/")
+	for _, tl := range timelines {
+		if submatch := tl.re.FindStringSubmatch(path); submatch != nil {
+			return &tweetPost{Src: href, Width: width, Height: height, Timeline: tl.source, User: submatch[1], ID: submatch[2]}, nil
+		}
+	}
+
+	return nil, embedError(`twitter`, ErrMalformedURL, href)
+}
+
+// timelines are paths of twitter timelines with their source types,
+// every path gives screen name and list id or slug, they may be empty
+var timelines = []struct {
+	re     *regexp.Regexp
+	source string
+}{
+	{regexp.MustCompile(`^/i/lists/()(\d+)$`), "list"},
+	{regexp.MustCompile(`^/([A-Za-z0-9_]{1,15})/lists/([A-Za-z0-9_-]+)$`), "list"},
+	{regexp.MustCompile(`^/([A-Za-z0-9_]{1,15})/likes()$`), "likes"},
+	{regexp.MustCompile(`^/([A-Za-z0-9_]{1,15})()$`), "profile"},
+}
+
+// TwitToAMP convertes given twitter embeddable html to AMP
+func TwitToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parseTwit(htmlText)
+	if err != nil {
+		return nil, err
 	}
 
 	return post.printAMP(), nil
 }
 
-// YoutubeToAMP convertes given youtube embeddable html to AMP
-func YoutubeToAMP(htmlText []byte) ([]byte, error) {
+// parseYoutube extracts youtube video data from given embeddable html
+func parseYoutube(htmlText []byte) (*youtubePost, error) {
 	pointerNode, err := html.Parse(bytes.NewReader(htmlText))
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse youtube iframe")
+		return nil, embedError(`youtube`, ErrMalformedEmbed, "")
 	}
 	var post youtubePost
 
@@ -453,6 +928,8 @@ func YoutubeToAMP(htmlText []byte) ([]byte, error) {
 					if err == nil {
 						post.Height = h
 					}
+				case "allowfullscreen":
+					post.AllowFS = true
 				}
 			}
 			if len(post.Src) > 0 {
@@ -466,34 +943,95 @@ func YoutubeToAMP(htmlText []byte) ([]byte, error) {
 	f(pointerNode)
 
 	if !(len(post.Src) > 0) {
-		return nil, fmt.Errorf("no src in the url")
+		return nil, embedError(`youtube`, ErrNoSource, "")
 	}
 
 	urlPtr, err := url.Parse(post.Src)
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse youtube url")
+		return nil, embedError(`youtube`, ErrMalformedURL, post.Src)
 	}
 
-	if !strings.Contains(urlPtr.Hostname(), "youtube.com") {
-		return nil, fmt.Errorf("it is not youtube url")
+	host := urlPtr.Hostname()
+	re := regexp.MustCompile(`^/(?:embed|shorts|live|v)/([A-Za-z0-9_-]{11})/?$`)
+	switch {
+	case host == "youtu.be":
+		re = regexp.MustCompile(`^/([A-Za-z0-9_-]{11})/?$`)
+	case regexp.MustCompile(`^(?:www\.)?youtube-nocookie\.com$`).MatchString(host):
+		post.NoCookie = true
+	case !regexp.MustCompile(`^(?:(?:www|m)\.)?youtube\.com$`).MatchString(host):
+		return nil, embedError(`youtube`, ErrWrongHost, host)
 	}
 
-	re := regexp.MustCompile(`embed/([A-Za-z0-9_-]{11})`)
-	submatch := re.FindStringSubmatch(urlPtr.Path)
+	query := urlPtr.Query()
+	// videoseries looks like video id, but it is player of playlist
+	switch submatch := re.FindStringSubmatch(urlPtr.Path); {
+	case urlPtr.Path == "/embed/videoseries", urlPtr.Path == "/playlist":
+	case submatch != nil:
+		post.VideoID = submatch[1]
+	case urlPtr.Path == "/watch" && regexp.MustCompile(`^[A-Za-z0-9_-]{11}$`).MatchString(query.Get("v")):
+		post.VideoID = query.Get("v")
+	default:
+		return nil, embedError(`youtube`, ErrMalformedURL, post.Src)
+	}
+
+	for key, val := range playerParams(query) {
+		if !youtubeParams[key] {
+			continue
+		}
+		if post.Params == nil {
+			post.Params = make(map[string]string)
+		}
+		post.Params[key] = val
+	}
+
+	// links have start time as t=90 or t=1m30s
+	if t := query.Get("t"); len(t) > 0 && len(post.Params["start"]) < 1 {
+		if start, ok := youtubeStart(t); ok {
+			if post.Params == nil {
+				post.Params = make(map[string]string)
+			}
+			post.Params["start"] = start
+		}
+	}
+
+	if len(post.VideoID) < 1 && !regexp.MustCompile(`^[A-Za-z0-9_-]+$`).MatchString(post.Params["list"]) {
+		return nil, embedError(`youtube`, ErrMalformedURL, post.Src)
+	}
+
+	return &post, nil
+}
+
+// youtubeStart returns start time in seconds from t parameter of youtube link
+func youtubeStart(t string) (string, bool) {
+	submatch := regexp.MustCompile(`^(?:(\d+)h)?(?:(\d+)m)?(?:(\d+)s?)?$`).FindStringSubmatch(t)
 	if submatch == nil {
-		return nil, fmt.Errorf("youtube url is malformed")
+		return "", false
+	}
+
+	var seconds int64
+	for i, unit := range []int64{3600, 60, 1} {
+		n, _ := strconv.ParseInt("0"+submatch[i+1], 10, 0)
+		seconds += n * unit
+	}
+
+	return strconv.FormatInt(seconds, 10), seconds > 0
+}
+
+// YoutubeToAMP convertes given youtube embeddable html to AMP
+func YoutubeToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parseYoutube(htmlText)
+	if err != nil {
+		return nil, err
 	}
-	post.VideoID = submatch[1]
 
 	return post.printAMP(), nil
 }
 
-// IframeToAMP convertes some custom iframe embeddable html to AMP
-// Tested on Russia Today
-func IframeToAMP(htmlText []byte) ([]byte, error) {
+// parseIframe extracts custom iframe data from given embeddable html
+func parseIframe(htmlText []byte) (*iframePost, error) {
 	pointerNode, err := html.Parse(bytes.NewReader(htmlText))
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse iframe")
+		return nil, embedError(`iframe`, ErrMalformedEmbed, "")
 	}
 	var post iframePost
 
@@ -504,12 +1042,22 @@ func IframeToAMP(htmlText []byte) ([]byte, error) {
 				switch iframe.Key {
 				case "src":
 					post.Src = iframe.Val
-				case "allowfullscreen":
-					post.AllowFS = true
-				case "frameborder":
-					fb, err := strconv.ParseInt(iframe.Val, 10, 0)
+				case "width":
+					w, err := strconv.ParseInt(iframe.Val, 10, 0)
 					if err == nil {
-						post.Frameborder = fb
+						post.Width = w
+					}
+				case "height":
+					h, err := strconv.ParseInt(iframe.Val, 10, 0)
+					if err == nil {
+						post.Height = h
+					}
+				case "allowfullscreen":
+					post.AllowFS = true
+				case "frameborder":
+					fb, err := strconv.ParseInt(iframe.Val, 10, 0)
+					if err == nil {
+						post.Frameborder = fb
 					}
 				}
 			}
@@ -524,27 +1072,39 @@ func IframeToAMP(htmlText []byte) ([]byte, error) {
 	f(pointerNode)
 
 	if len(post.Src) < 1 {
-		return nil, fmt.Errorf("no src in the url")
+		return nil, embedError(`iframe`, ErrNoSource, "")
 	}
 
 	urlPtr, err := url.Parse(post.Src)
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse iframe url")
+		return nil, embedError(`iframe`, ErrMalformedURL, post.Src)
+	}
+	post.Scheme = urlPtr.Scheme
+
+	return &post, nil
+}
+
+// IframeToAMP convertes some custom iframe embeddable html to AMP
+// Tested on Russia Today
+func IframeToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parseIframe(htmlText)
+	if err != nil {
+		return nil, err
 	}
 
-	if urlPtr.Scheme != `https` {
-		return nil, fmt.Errorf("amp supports only https iframe scheme")
+	if post.Scheme != `https` {
+		return nil, embedError(`iframe`, ErrInsecureScheme, post.Src)
 	}
 
 	return post.printAMP(), nil
 }
 
-// PlaybuzzToAMP convert playbuzz code
-func PlaybuzzToAMP(htmlText []byte) ([]byte, error) {
+// parsePlaybuzz extracts playbuzz item from given html
+func parsePlaybuzz(htmlText []byte) (*playbuzzPost, error) {
 	r := regexp.MustCompile(`<div(.+?)(class="playbuzz") data-id="(.+?)"(.+?)<\/div>`)
 	widgetParsed := r.FindSubmatch(htmlText)
 	if widgetParsed == nil {
-		return nil, fmt.Errorf("cannot parse playbuzz widget")
+		return nil, embedError(`playbuzz`, ErrMalformedEmbed, "")
 	}
 
 	var post playbuzzPost
@@ -552,5 +1112,1995 @@ func PlaybuzzToAMP(htmlText []byte) ([]byte, error) {
 		post.DataItem = string(widgetParsed[3])
 	}
 
+	return &post, nil
+}
+
+// PlaybuzzToAMP convert playbuzz code
+func PlaybuzzToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parsePlaybuzz(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+// tiktokPost contents tiktok video data
+type tiktokPost struct {
+	VideoID string
+	Width   int64
+	Height  int64
+	Src     string
+}
+
+// printAMP returns ready to handle AMP with given parameters
+func (post *tiktokPost) printAMP() []byte {
+	if post.Width == 0 {
+		post.Width = 325
+	}
+	if post.Height == 0 {
+		post.Height = 575
+	}
+	template := `<amp-tiktok layout="responsive" height="%d" width="%d" data-src="%s"></amp-tiktok>`
+
+	amp := fmt.Sprintf(template, post.Height, post.Width, post.VideoID)
+
+	return []byte(amp)
+}
+
+// parseTiktok extracts tiktok video data from given embeddable html
+func parseTiktok(htmlText []byte) (*tiktokPost, error) {
+	pointerNode, err := html.Parse(bytes.NewReader(htmlText))
+	if err != nil {
+		return nil, embedError(`tiktok`, ErrMalformedEmbed, "")
+	}
+	var post tiktokPost
+
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.DataAtom == atom.Blockquote {
+			isTiktok := false
+			for _, bq := range n.Attr {
+				switch bq.Key {
+				case "class":
+					isTiktok = strings.Contains(bq.Val, "tiktok-embed")
+				case "cite":
+					post.Src = bq.Val
+				case "data-video-id":
+					post.VideoID = bq.Val
+				}
+			}
+			if isTiktok {
+				return
+			}
+			post.Src, post.VideoID = "", ""
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(pointerNode)
+
+	if len(post.Src) < 1 && len(post.VideoID) < 1 {
+		return nil, embedError(`tiktok`, ErrNoSource, "")
+	}
+
+	if len(post.Src) > 0 {
+		urlPtr, err := url.Parse(post.Src)
+		if err != nil {
+			return nil, embedError(`tiktok`, ErrMalformedURL, post.Src)
+		}
+
+		if !strings.Contains(urlPtr.Hostname(), "tiktok.com") {
+			return nil, embedError(`tiktok`, ErrWrongHost, urlPtr.Hostname())
+		}
+
+		re := regexp.MustCompile(`/video/(\d+)`)
+		submatch := re.FindStringSubmatch(urlPtr.Path)
+		if submatch == nil {
+			return nil, embedError(`tiktok`, ErrMalformedURL, post.Src)
+		}
+
+		// data-video-id and cite should match
+		if len(post.VideoID) > 0 && post.VideoID != submatch[1] {
+			return nil, embedError(`tiktok`, ErrMalformedEmbed, post.VideoID)
+		}
+		post.VideoID = submatch[1]
+	}
+
+	if _, err := strconv.ParseUint(post.VideoID, 10, 64); err != nil {
+		return nil, embedError(`tiktok`, ErrMalformedEmbed, post.VideoID)
+	}
+
+	return &post, nil
+}
+
+// TiktokToAMP convertes given tiktok embeddable html to AMP
+func TiktokToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parseTiktok(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+// printPostAMP returns amp-iframe of social network post with given src
+func printPostAMP(src string, width, height int64) []byte {
+	if width == 0 {
+		width = 480
+	}
+	if height == 0 {
+		height = 400
+	}
+	template := `<amp-iframe width="%d" height="%d" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" src="%s"></amp-iframe>`
+
+	amp := fmt.Sprintf(template, width, height, src)
+
+	return []byte(amp)
+}
+
+// telegramPost contents telegram post data
+type telegramPost struct {
+	// Channel is username of channel or group
+	Channel string
+	PostID  int64
+	Width   int64
+	Height  int64
+	Src     string
+}
+
+// src returns url of embeddable telegram post
+func (post *telegramPost) src() string {
+	return fmt.Sprintf("https://t.me/%s/%d?embed=1", post.Channel, post.PostID)
+}
+
+// printAMP returns ready to handle AMP with given parameters
+func (post *telegramPost) printAMP() []byte {
+	return printPostAMP(post.src(), post.Width, post.Height)
+}
+
+// parseTelegram extracts telegram post data from given widget script or iframe
+// What is that? Look https://core.telegram.org/widgets/post
+func parseTelegram(htmlText []byte) (*telegramPost, error) {
+	pointerNode, err := html.Parse(bytes.NewReader(htmlText))
+	if err != nil {
+		return nil, embedError(`telegram`, ErrMalformedEmbed, "")
+	}
+	var post telegramPost
+	var path string
+
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.DataAtom == atom.Script || n.DataAtom == atom.Iframe {
+			for _, a := range n.Attr {
+				switch {
+				case a.Key == "data-telegram-post" && n.DataAtom == atom.Script:
+					path = a.Val
+				case a.Key == "src" && n.DataAtom == atom.Iframe:
+					post.Src = a.Val
+				case a.Key == "width" && n.DataAtom == atom.Iframe:
+					w, err := strconv.ParseInt(a.Val, 10, 0)
+					if err == nil {
+						post.Width = w
+					}
+				case a.Key == "height" && n.DataAtom == atom.Iframe:
+					h, err := strconv.ParseInt(a.Val, 10, 0)
+					if err == nil {
+						post.Height = h
+					}
+				}
+			}
+			if len(path) > 0 || len(post.Src) > 0 {
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(pointerNode)
+
+	if len(path) < 1 && len(post.Src) < 1 {
+		return nil, embedError(`telegram`, ErrNoSource, "")
+	}
+
+	if len(post.Src) > 0 {
+		urlPtr, err := url.Parse(post.Src)
+		if err != nil {
+			return nil, embedError(`telegram`, ErrMalformedURL, post.Src)
+		}
+
+		if host := urlPtr.Hostname(); host != "t.me" && host != "telegram.me" {
+			return nil, embedError(`telegram`, ErrWrongHost, host)
+		}
+		path = strings.TrimPrefix(urlPtr.Path, "/")
+	}
+
+	re := regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9_]{3,31})/(\d+)$`)
+	submatch := re.FindStringSubmatch(path)
+	if submatch == nil {
+		return nil, embedError(`telegram`, ErrMalformedEmbed, path)
+	}
+
+	postID, err := strconv.ParseInt(submatch[2], 10, 0)
+	if err != nil {
+		return nil, embedError(`telegram`, ErrMalformedEmbed, submatch[2])
+	}
+	post.Channel = submatch[1]
+	post.PostID = postID
+
+	return &post, nil
+}
+
+// TelegramToAMP convertes given telegram widget post or iframe to AMP
+func TelegramToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parseTelegram(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+// printPlayerAMP returns amp-iframe of video player with given src
+func printPlayerAMP(src string, width, height int64) []byte {
+	if width == 0 {
+		width = 640
+	}
+	if height == 0 {
+		height = 360
+	}
+	template := `<amp-iframe width="%d" height="%d" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" allow="autoplay; encrypted-media; fullscreen; picture-in-picture" allowfullscreen src="%s"></amp-iframe>`
+
+	amp := fmt.Sprintf(template, width, height, src)
+
+	return []byte(amp)
+}
+
+// parsePlayer extracts src and size of iframe from given embeddable html
+func parsePlayer(provider string, htmlText []byte) (*iframePost, error) {
+	pointerNode, err := html.Parse(bytes.NewReader(htmlText))
+	if err != nil {
+		return nil, embedError(provider, ErrMalformedEmbed, "")
+	}
+	var post iframePost
+
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.DataAtom == atom.Iframe {
+			for _, iframe := range n.Attr {
+				switch iframe.Key {
+				case "src":
+					post.Src = iframe.Val
+				case "width":
+					w, err := strconv.ParseInt(iframe.Val, 10, 0)
+					if err == nil {
+						post.Width = w
+					}
+				case "height":
+					h, err := strconv.ParseInt(iframe.Val, 10, 0)
+					if err == nil {
+						post.Height = h
+					}
+				}
+			}
+			if len(post.Src) > 0 {
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(pointerNode)
+
+	if len(post.Src) < 1 {
+		return nil, embedError(provider, ErrNoSource, "")
+	}
+
+	return &post, nil
+}
+
+// rutubePost contents rutube video data
+type rutubePost struct {
+	VideoID string
+	// Key is access key of private video
+	Key    string
+	Width  int64
+	Height int64
+}
+
+// src returns url of rutube player
+func (post *rutubePost) src() string {
+	src := "https://rutube.ru/play/embed/" + post.VideoID
+	if len(post.Key) > 0 {
+		src += "?p=" + url.QueryEscape(post.Key)
+	}
+
+	return src
+}
+
+// printAMP returns ready to handle AMP with given parameters
+func (post *rutubePost) printAMP() []byte {
+	return printPlayerAMP(post.src(), post.Width, post.Height)
+}
+
+// parseRutube extracts rutube video data from given embeddable html
+func parseRutube(htmlText []byte) (*rutubePost, error) {
+	player, err := parsePlayer(`rutube`, htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	urlPtr, err := url.Parse(player.Src)
+	if err != nil {
+		return nil, embedError(`rutube`, ErrMalformedURL, player.Src)
+	}
+
+	if host := urlPtr.Hostname(); host != "rutube.ru" && host != "www.rutube.ru" {
+		return nil, embedError(`rutube`, ErrWrongHost, host)
+	}
+
+	re := regexp.MustCompile(`^/play/embed/([0-9a-f]{32}|\d+)/?$`)
+	submatch := re.FindStringSubmatch(urlPtr.Path)
+	if submatch == nil {
+		return nil, embedError(`rutube`, ErrMalformedURL, player.Src)
+	}
+
+	post := &rutubePost{VideoID: submatch[1], Width: player.Width, Height: player.Height}
+
+	// private videos are opened by access key
+	if key := urlPtr.Query().Get("p"); len(key) > 0 {
+		if !regexp.MustCompile(`^[A-Za-z0-9_-]+$`).MatchString(key) {
+			return nil, embedError(`rutube`, ErrMalformedURL, player.Src)
+		}
+		post.Key = key
+	}
+
+	return post, nil
+}
+
+// RutubeToAMP convertes given rutube embeddable html to AMP
+func RutubeToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parseRutube(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+// vkVideoPost contents vkontakte video data
+type vkVideoPost struct {
+	OwnerID int64
+	VideoID int64
+	Hash    string
+	Width   int64
+	Height  int64
+}
+
+// src returns url of vkontakte video player
+func (post *vkVideoPost) src() string {
+	src := fmt.Sprintf("https://vk.com/video_ext.php?oid=%d&id=%d", post.OwnerID, post.VideoID)
+	if len(post.Hash) > 0 {
+		src += "&hash=" + post.Hash
+	}
+
+	return src
+}
+
+// printAMP returns ready to handle AMP with given parameters
+func (post *vkVideoPost) printAMP() []byte {
+	return printPlayerAMP(post.src(), post.Width, post.Height)
+}
+
+// parseVkVideo extracts vkontakte video data from given embeddable html
+func parseVkVideo(htmlText []byte) (*vkVideoPost, error) {
+	player, err := parsePlayer(`vkvideo`, htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	urlPtr, err := url.Parse(player.Src)
+	if err != nil {
+		return nil, embedError(`vkvideo`, ErrMalformedURL, player.Src)
+	}
+
+	switch urlPtr.Hostname() {
+	case "vk.com", "m.vk.com", "vk.ru", "vkvideo.ru":
+	default:
+		return nil, embedError(`vkvideo`, ErrWrongHost, urlPtr.Hostname())
+	}
+
+	if urlPtr.Path != "/video_ext.php" {
+		return nil, embedError(`vkvideo`, ErrMalformedURL, player.Src)
+	}
+
+	query := urlPtr.Query()
+	ownerID, err := strconv.ParseInt(query.Get("oid"), 10, 0)
+	if err != nil {
+		return nil, embedError(`vkvideo`, ErrMalformedEmbed, query.Get("oid"))
+	}
+
+	videoID, err := strconv.ParseInt(query.Get("id"), 10, 0)
+	if err != nil || videoID < 1 {
+		return nil, embedError(`vkvideo`, ErrMalformedEmbed, query.Get("id"))
+	}
+
+	hash := query.Get("hash")
+	if len(hash) > 0 && !regexp.MustCompile(`^[0-9a-f]+$`).MatchString(hash) {
+		return nil, embedError(`vkvideo`, ErrMalformedEmbed, hash)
+	}
+
+	return &vkVideoPost{OwnerID: ownerID, VideoID: videoID, Hash: hash, Width: player.Width, Height: player.Height}, nil
+}
+
+// VkVideoToAMP convertes given vkontakte video player to AMP
+func VkVideoToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parseVkVideo(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+// playerParams returns player parameters from query of embed url.
+// Parameters with strange names are skipped, so they can be used as attributes.
+func playerParams(query url.Values) map[string]string {
+	re := regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+	var params map[string]string
+	for key, values := range query {
+		if !re.MatchString(key) || len(values) < 1 {
+			continue
+		}
+		if params == nil {
+			params = make(map[string]string)
+		}
+		params[key] = values[0]
+	}
+
+	return params
+}
+
+// encodeParams returns query of player url with given parameters
+func encodeParams(params map[string]string) string {
+	if len(params) < 1 {
+		return ""
+	}
+
+	query := make(url.Values)
+	for key, val := range params {
+		query.Set(key, val)
+	}
+
+	return "?" + query.Encode()
+}
+
+// isTrue tells if player parameter is turned on
+func isTrue(val string) bool {
+	return val == "1" || val == "true"
+}
+
+// vimeoPost contents vimeo video data
+type vimeoPost struct {
+	VideoID string
+	// Hash is access hash of unlisted video
+	Hash   string
+	Params map[string]string
+	Width  int64
+	Height int64
+}
+
+// src returns url of vimeo player
+func (post *vimeoPost) src() string {
+	params := make(map[string]string, len(post.Params)+1)
+	for key, val := range post.Params {
+		params[key] = val
+	}
+	if len(post.Hash) > 0 {
+		params["h"] = post.Hash
+	}
+
+	return "https://player.vimeo.com/video/" + post.VideoID + encodeParams(params)
+}
+
+// printAMP returns ready to handle AMP with given parameters
+func (post *vimeoPost) printAMP() []byte {
+	// amp-vimeo cannot play unlisted videos
+	if len(post.Hash) > 0 {
+		return printPlayerAMP(post.src(), post.Width, post.Height)
+	}
+
+	if post.Width == 0 {
+		post.Width = 640
+	}
+	if post.Height == 0 {
+		post.Height = 360
+	}
+	var attributes string
+	if isTrue(post.Params["autoplay"]) {
+		attributes += ` autoplay`
+	}
+	if isTrue(post.Params["dnt"]) {
+		attributes += ` data-do-not-track`
+	}
+	template := `<amp-vimeo layout="responsive" height="%d" width="%d"%s data-videoid="%s"></amp-vimeo>`
+
+	amp := fmt.Sprintf(template, post.Height, post.Width, attributes, post.VideoID)
+
+	return []byte(amp)
+}
+
+// parseVimeo extracts vimeo video data from given embeddable html
+func parseVimeo(htmlText []byte) (*vimeoPost, error) {
+	player, err := parsePlayer(`vimeo`, htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	urlPtr, err := url.Parse(player.Src)
+	if err != nil {
+		return nil, embedError(`vimeo`, ErrMalformedURL, player.Src)
+	}
+
+	if urlPtr.Hostname() != "player.vimeo.com" {
+		return nil, embedError(`vimeo`, ErrWrongHost, urlPtr.Hostname())
+	}
+
+	re := regexp.MustCompile(`^/video/(\d+)/?$`)
+	submatch := re.FindStringSubmatch(urlPtr.Path)
+	if submatch == nil {
+		return nil, embedError(`vimeo`, ErrMalformedURL, player.Src)
+	}
+
+	post := &vimeoPost{VideoID: submatch[1], Params: playerParams(urlPtr.Query()), Width: player.Width, Height: player.Height}
+	if hash, ok := post.Params["h"]; ok {
+		if !regexp.MustCompile(`^[0-9a-f]+$`).MatchString(hash) {
+			return nil, embedError(`vimeo`, ErrMalformedURL, player.Src)
+		}
+		post.Hash = hash
+		delete(post.Params, "h")
+	}
+
+	return post, nil
+}
+
+// VimeoToAMP convertes given vimeo embeddable html to AMP
+func VimeoToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parseVimeo(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+// dailymotionAttrs are player parameters which amp-dailymotion takes as data attributes,
+// other ones are passed by data-param-*
+var dailymotionAttrs = map[string]bool{
+	"endscreen-enable": true,
+	"info":             true,
+	"mute":             true,
+	"sharing-enable":   true,
+	"start":            true,
+	"ui-highlight":     true,
+	"ui-logo":          true,
+}
+
+// dailymotionPost contents dailymotion video data
+type dailymotionPost struct {
+	VideoID string
+	Params  map[string]string
+	Width   int64
+	Height  int64
+}
+
+// src returns url of dailymotion player
+func (post *dailymotionPost) src() string {
+	return "https://www.dailymotion.com/embed/video/" + post.VideoID + encodeParams(post.Params)
+}
+
+// printAMP returns ready to handle AMP with given parameters
+func (post *dailymotionPost) printAMP() []byte {
+	if post.Width == 0 {
+		post.Width = 640
+	}
+	if post.Height == 0 {
+		post.Height = 360
+	}
+
+	keys := make([]string, 0, len(post.Params))
+	for key := range post.Params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var attributes string
+	for _, key := range keys {
+		val := post.Params[key]
+		switch {
+		case key == "autoplay":
+			if isTrue(val) {
+				attributes += ` autoplay`
+			}
+		case dailymotionAttrs[key]:
+			attributes += fmt.Sprintf(` data-%s="%s"`, key, html.EscapeString(val))
+		default:
+			attributes += fmt.Sprintf(` data-param-%s="%s"`, strings.ToLower(key), html.EscapeString(val))
+		}
+	}
+	template := `<amp-dailymotion layout="responsive" height="%d" width="%d"%s data-videoid="%s"></amp-dailymotion>`
+
+	amp := fmt.Sprintf(template, post.Height, post.Width, attributes, post.VideoID)
+
+	return []byte(amp)
+}
+
+// parseDailymotion extracts dailymotion video data from given embeddable html
+func parseDailymotion(htmlText []byte) (*dailymotionPost, error) {
+	player, err := parsePlayer(`dailymotion`, htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	urlPtr, err := url.Parse(player.Src)
+	if err != nil {
+		return nil, embedError(`dailymotion`, ErrMalformedURL, player.Src)
+	}
+
+	if host := urlPtr.Hostname(); host != "www.dailymotion.com" && host != "dailymotion.com" {
+		return nil, embedError(`dailymotion`, ErrWrongHost, host)
+	}
+
+	re := regexp.MustCompile(`^/embed/video/(x[0-9a-z]+)/?$`)
+	submatch := re.FindStringSubmatch(urlPtr.Path)
+	if submatch == nil {
+		return nil, embedError(`dailymotion`, ErrMalformedURL, player.Src)
+	}
+
+	return &dailymotionPost{VideoID: submatch[1], Params: playerParams(urlPtr.Query()), Width: player.Width, Height: player.Height}, nil
+}
+
+// DailymotionToAMP convertes given dailymotion embeddable html to AMP
+func DailymotionToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parseDailymotion(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+// pinterestPost contents pinterest widget data
+type pinterestPost struct {
+	// Kind is widget type: embedPin, embedBoard or embedUser
+	Kind  string
+	PinID string
+	User  string
+	Board string
+	Width int64
+	Href  string
+}
+
+// url returns canonical url of pinterest pin, board or profile
+func (post *pinterestPost) url() string {
+	switch post.Kind {
+	case "embedPin":
+		return "https://www.pinterest.com/pin/" + post.PinID + "/"
+	case "embedBoard":
+		return "https://www.pinterest.com/" + post.User + "/" + post.Board + "/"
+	}
+
+	return "https://www.pinterest.com/" + post.User + "/"
+}
+
+// printAMP returns ready to handle AMP with given parameters.
+// amp-pinterest cannot show boards and profiles, so they are shown as follow buttons.
+func (post *pinterestPost) printAMP() []byte {
+	if post.Kind != "embedPin" {
+		label := post.User
+		if post.Kind == "embedBoard" {
+			label = post.Board
+		}
+		template := `<amp-pinterest height="28" width="160" data-do="buttonFollow" data-href="%s" data-label="%s"></amp-pinterest>`
+
+		return []byte(fmt.Sprintf(template, post.url(), html.EscapeString(label)))
+	}
+
+	if post.Width == 0 {
+		post.Width = 236
+	}
+	var attributes string
+	switch {
+	case post.Width >= 600:
+		attributes += ` data-width="large"`
+	case post.Width >= 345:
+		attributes += ` data-width="medium"`
+	}
+	template := `<amp-pinterest height="%d" width="%d"%s data-do="embedPin" data-url="%s"></amp-pinterest>`
+
+	amp := fmt.Sprintf(template, post.Width*326/236, post.Width, attributes, post.url())
+
+	return []byte(amp)
+}
+
+// pinterestWidths are widths of pins by data-pin-width
+var pinterestWidths = map[string]int64{
+	"small":  236,
+	"medium": 345,
+	"large":  600,
+}
+
+// parsePinterest extracts pinterest widget data from given embeddable html
+// What is that? Look https://developers.pinterest.com/tools/widget-builder/
+func parsePinterest(htmlText []byte) (*pinterestPost, error) {
+	pointerNode, err := html.Parse(bytes.NewReader(htmlText))
+	if err != nil {
+		return nil, embedError(`pinterest`, ErrMalformedEmbed, "")
+	}
+	var post pinterestPost
+
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.DataAtom == atom.A {
+			for _, a := range n.Attr {
+				switch a.Key {
+				case "data-pin-do":
+					post.Kind = a.Val
+				case "href":
+					post.Href = a.Val
+				case "data-pin-width":
+					post.Width = pinterestWidths[a.Val]
+				}
+			}
+			if len(post.Kind) > 0 {
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(pointerNode)
+
+	if len(post.Href) < 1 {
+		return nil, embedError(`pinterest`, ErrNoSource, "")
+	}
+
+	urlPtr, err := url.Parse(post.Href)
+	if err != nil {
+		return nil, embedError(`pinterest`, ErrMalformedURL, post.Href)
+	}
+
+	hostRe := regexp.MustCompile(`^(?:[a-z]{2,3}\.)?pinterest\.[a-z]{2,3}(?:\.[a-z]{2})?$`)
+	if !hostRe.MatchString(urlPtr.Hostname()) {
+		return nil, embedError(`pinterest`, ErrWrongHost, urlPtr.Hostname())
+	}
+
+	var re *regexp.Regexp
+	switch post.Kind {
+	case "embedPin":
+		re = regexp.MustCompile(`^/pin/(\d+)/?$`)
+	case "embedBoard":
+		re = regexp.MustCompile(`^/([A-Za-z0-9_]{3,30})/([A-Za-z0-9_-]+)/?$`)
+	case "embedUser":
+		re = regexp.MustCompile(`^/([A-Za-z0-9_]{3,30})/?$`)
+	default:
+		return nil, embedError(`pinterest`, ErrUnsupported, post.Kind)
+	}
+
+	submatch := re.FindStringSubmatch(urlPtr.Path)
+	if submatch == nil {
+		return nil, embedError(`pinterest`, ErrMalformedURL, post.Href)
+	}
+
+	switch post.Kind {
+	case "embedPin":
+		post.PinID = submatch[1]
+	case "embedBoard":
+		post.User, post.Board = submatch[1], submatch[2]
+	case "embedUser":
+		post.User = submatch[1]
+	}
+
+	return &post, nil
+}
+
+// PinterestToAMP convertes given pinterest pin, board or profile widget to AMP
+func PinterestToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parsePinterest(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+// redditAttrs are attributes of reddit comment embed and their amp-reddit names
+var redditAttrs = map[string]string{
+	"uuid":    "data-uuid",
+	"created": "data-embedcreated",
+	"parent":  "data-embedparent",
+	"live":    "data-embedlive",
+}
+
+// redditPost contents reddit post or comment data
+type redditPost struct {
+	// Kind is post or comment
+	Kind      string
+	Subreddit string
+	PostID    string
+	Slug      string
+	CommentID string
+	// Params are attributes of comment embed, see redditAttrs
+	Params map[string]string
+	Width  int64
+	Height int64
+	Href   string
+}
+
+// url returns canonical url of reddit post or comment
+func (post *redditPost) url() string {
+	src := "https://www.reddit.com/r/" + post.Subreddit + "/comments/" + post.PostID + "/"
+	if len(post.Slug) > 0 {
+		src += post.Slug + "/"
+	}
+	if post.Kind == "comment" {
+		if len(post.Slug) < 1 {
+			src += "comment/"
+		}
+		src += post.CommentID + "/"
+	}
+
+	return src
+}
+
+// printAMP returns ready to handle AMP with given parameters
+func (post *redditPost) printAMP() []byte {
+	if post.Width == 0 {
+		post.Width = 300
+	}
+	if post.Height == 0 {
+		post.Height = 400
+	}
+
+	keys := make([]string, 0, len(post.Params))
+	for key := range post.Params {
+		if _, ok := redditAttrs[key]; ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var attributes string
+	for _, key := range keys {
+		attributes += fmt.Sprintf(` %s="%s"`, redditAttrs[key], html.EscapeString(post.Params[key]))
+	}
+	template := `<amp-reddit layout="responsive" height="%d" width="%d" data-embedtype="%s"%s data-src="%s"></amp-reddit>`
+
+	amp := fmt.Sprintf(template, post.Height, post.Width, post.Kind, attributes, post.url())
+
+	return []byte(amp)
+}
+
+// parseReddit extracts reddit post or comment data from given embeddable html
+func parseReddit(htmlText []byte) (*redditPost, error) {
+	pointerNode, err := html.Parse(bytes.NewReader(htmlText))
+	if err != nil {
+		return nil, embedError(`reddit`, ErrMalformedEmbed, "")
+	}
+	var post redditPost
+	var inside bool
+
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.DataAtom == atom.Blockquote || n.DataAtom == atom.Div {
+			for _, a := range n.Attr {
+				switch {
+				case a.Key == "class":
+					for _, class := range strings.Fields(a.Val) {
+						if class == "reddit-card" || class == "reddit-embed-bq" || class == "reddit-embed" {
+							inside = true
+						}
+					}
+				case a.Key == "data-embed-height":
+					h, err := strconv.ParseInt(a.Val, 10, 0)
+					if err == nil {
+						post.Height = h
+					}
+				case strings.HasPrefix(a.Key, "data-embed-"):
+					if _, ok := redditAttrs[strings.TrimPrefix(a.Key, "data-embed-")]; ok {
+						if post.Params == nil {
+							post.Params = make(map[string]string)
+						}
+						post.Params[strings.TrimPrefix(a.Key, "data-embed-")] = a.Val
+					}
+				}
+			}
+		}
+		// the first link of embed is its permalink
+		if inside && n.DataAtom == atom.A {
+			for _, a := range n.Attr {
+				if a.Key == "href" {
+					post.Href = a.Val
+					return
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil && len(post.Href) < 1; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(pointerNode)
+
+	if len(post.Href) < 1 {
+		return nil, embedError(`reddit`, ErrNoSource, "")
+	}
+
+	if err := post.parseHref(); err != nil {
+		return nil, err
+	}
+
+	return &post, nil
+}
+
+// parseHref extracts subreddit, post and comment from permalink of the embed
+func (post *redditPost) parseHref() error {
+	urlPtr, err := url.Parse(post.Href)
+	if err != nil {
+		return embedError(`reddit`, ErrMalformedURL, post.Href)
+	}
+
+	if !regexp.MustCompile(`^(?:www\.|old\.|new\.)?reddit\.com$`).MatchString(urlPtr.Hostname()) {
+		return embedError(`reddit`, ErrWrongHost, urlPtr.Hostname())
+	}
+
+	re := regexp.MustCompile(`^/r/([A-Za-z0-9_]+)/comments/([a-z0-9]+)(?:/([A-Za-z0-9_%.-]+))?(?:/([a-z0-9]+))?/?$`)
+	submatch := re.FindStringSubmatch(urlPtr.EscapedPath())
+	if submatch == nil {
+		return embedError(`reddit`, ErrMalformedURL, post.Href)
+	}
+	post.Subreddit, post.PostID, post.Slug = submatch[1], submatch[2], submatch[3]
+
+	post.Kind = "post"
+	if len(submatch[4]) > 0 {
+		post.Kind = "comment"
+		post.CommentID = submatch[4]
+		// new links to comments have no slug: /r/x/comments/post/comment/id/
+		if post.Slug == "comment" {
+			post.Slug = ""
+		}
+	}
+
+	return nil
+}
+
+// RedditToAMP convertes given reddit post or comment embeddable html to AMP
+func RedditToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parseReddit(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+// printAudioAMP returns fixed-height amp-iframe of audio player with given src
+func printAudioAMP(src string, height int64) []byte {
+	template := `<amp-iframe height="%d" layout="fixed-height" sandbox="allow-scripts allow-same-origin allow-popups" frameborder="0" allow="autoplay" src="%s"></amp-iframe>`
+
+	amp := fmt.Sprintf(template, height, src)
+
+	return []byte(amp)
+}
+
+// soundcloudPost contents soundcloud track or playlist data
+type soundcloudPost struct {
+	// Kind is track or playlist
+	Kind string
+	ID   string
+	// SecretToken opens private tracks
+	SecretToken string
+	// Color is hex color of player without #
+	Color  string
+	Visual bool
+	Height int64
+}
+
+// src returns url of soundcloud player
+func (post *soundcloudPost) src() string {
+	api := "https://api.soundcloud.com/" + post.Kind + "s/" + post.ID
+	if len(post.SecretToken) > 0 {
+		api += "?secret_token=" + post.SecretToken
+	}
+
+	query := url.Values{"url": {api}}
+	if len(post.Color) > 0 {
+		query.Set("color", "#"+post.Color)
+	}
+	if post.Visual {
+		query.Set("visual", "true")
+	}
+
+	return "https://w.soundcloud.com/player/?" + query.Encode()
+}
+
+// printAMP returns ready to handle AMP with given parameters
+func (post *soundcloudPost) printAMP() []byte {
+	if post.Height == 0 {
+		post.Height = 166
+		if post.Visual {
+			post.Height = 300
+		}
+	}
+	attributes := fmt.Sprintf(` data-%sid="%s"`, post.Kind, post.ID)
+	if len(post.SecretToken) > 0 {
+		attributes += ` data-secret-token="` + post.SecretToken + `"`
+	}
+	if post.Visual {
+		attributes += ` data-visual="true"`
+	}
+	if len(post.Color) > 0 {
+		attributes += ` data-color="` + post.Color + `"`
+	}
+	template := `<amp-soundcloud height="%d" layout="fixed-height"%s></amp-soundcloud>`
+
+	amp := fmt.Sprintf(template, post.Height, attributes)
+
+	return []byte(amp)
+}
+
+// parseSoundcloud extracts soundcloud track or playlist data from given embeddable html
+func parseSoundcloud(htmlText []byte) (*soundcloudPost, error) {
+	player, err := parsePlayer(`soundcloud`, htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	urlPtr, err := url.Parse(player.Src)
+	if err != nil {
+		return nil, embedError(`soundcloud`, ErrMalformedURL, player.Src)
+	}
+
+	if urlPtr.Hostname() != "w.soundcloud.com" {
+		return nil, embedError(`soundcloud`, ErrWrongHost, urlPtr.Hostname())
+	}
+
+	// the player shows material of api url
+	query := urlPtr.Query()
+	apiPtr, err := url.Parse(query.Get("url"))
+	if err != nil || strings.TrimSuffix(urlPtr.Path, "/") != "/player" {
+		return nil, embedError(`soundcloud`, ErrMalformedURL, player.Src)
+	}
+
+	if apiPtr.Hostname() != "api.soundcloud.com" {
+		return nil, embedError(`soundcloud`, ErrWrongHost, apiPtr.Hostname())
+	}
+
+	re := regexp.MustCompile(`^/(track|playlist)s/(?:soundcloud:(?:track|playlist)s:)?(\d+)/?$`)
+	submatch := re.FindStringSubmatch(apiPtr.Path)
+	if submatch == nil {
+		return nil, embedError(`soundcloud`, ErrMalformedURL, query.Get("url"))
+	}
+
+	post := &soundcloudPost{Kind: submatch[1], ID: submatch[2], Height: player.Height, Visual: query.Get("visual") == "true"}
+
+	tokenRe := regexp.MustCompile(`^s-[A-Za-z0-9]+$`)
+	for _, token := range []string{apiPtr.Query().Get("secret_token"), query.Get("secret_token")} {
+		if len(token) < 1 {
+			continue
+		}
+		if !tokenRe.MatchString(token) {
+			return nil, embedError(`soundcloud`, ErrMalformedEmbed, token)
+		}
+		post.SecretToken = token
+	}
+
+	if color := strings.TrimPrefix(query.Get("color"), "#"); regexp.MustCompile(`^[0-9a-fA-F]{6}$`).MatchString(color) {
+		post.Color = color
+	}
+
+	return post, nil
+}
+
+// SoundcloudToAMP convertes given soundcloud player to AMP
+func SoundcloudToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parseSoundcloud(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+// yandexMusicPost contents yandex music track, album or playlist data
+type yandexMusicPost struct {
+	// Kind is track, album or playlist
+	Kind string
+	// ID is id of track, album or kind of playlist
+	ID string
+	// OwnerID is album of track or user of playlist
+	OwnerID string
+	Height  int64
+}
+
+// src returns url of yandex music player
+func (post *yandexMusicPost) src() string {
+	switch post.Kind {
+	case "track":
+		return "https://music.yandex.ru/iframe/#track/" + post.ID + "/" + post.OwnerID
+	case "playlist":
+		return "https://music.yandex.ru/iframe/#playlist/" + post.OwnerID + "/" + post.ID
+	}
+
+	return "https://music.yandex.ru/iframe/#album/" + post.ID
+}
+
+// url returns canonical url of yandex music track, album or playlist
+func (post *yandexMusicPost) url() string {
+	switch post.Kind {
+	case "track":
+		return "https://music.yandex.ru/album/" + post.OwnerID + "/track/" + post.ID
+	case "playlist":
+		return "https://music.yandex.ru/users/" + post.OwnerID + "/playlists/" + post.ID
+	}
+
+	return "https://music.yandex.ru/album/" + post.ID
+}
+
+// height returns height of the player, albums and playlists have a list of tracks
+func (post *yandexMusicPost) height() int64 {
+	switch {
+	case post.Height > 0:
+		return post.Height
+	case post.Kind == "track":
+		return 180
+	}
+
+	return 450
+}
+
+// printAMP returns ready to handle AMP with given parameters
+func (post *yandexMusicPost) printAMP() []byte {
+	return printAudioAMP(post.src(), post.height())
+}
+
+// parseYandexMusic extracts yandex music track, album or playlist data from given embeddable html
+func parseYandexMusic(htmlText []byte) (*yandexMusicPost, error) {
+	player, err := parsePlayer(`yandexmusic`, htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	urlPtr, err := url.Parse(player.Src)
+	if err != nil {
+		return nil, embedError(`yandexmusic`, ErrMalformedURL, player.Src)
+	}
+
+	if !regexp.MustCompile(`^music\.yandex\.(ru|com|by|kz|uz)$`).MatchString(urlPtr.Hostname()) {
+		return nil, embedError(`yandexmusic`, ErrWrongHost, urlPtr.Hostname())
+	}
+
+	// material is put into fragment or path: /iframe/#track/1/2 or /iframe/track/1/2
+	path := strings.TrimPrefix(urlPtr.Path, "/iframe")
+	if len(urlPtr.Fragment) > 0 {
+		path += "/" + urlPtr.Fragment
+	}
+	if !strings.HasPrefix(urlPtr.Path, "/iframe") {
+		return nil, embedError(`yandexmusic`, ErrMalformedURL, player.Src)
+	}
+
+	re := regexp.MustCompile(`^/+(?:(track)/(\d+)/(\d+)|(album)/(\d+)|(playlist)/([A-Za-z0-9._-]+)/(\d+))/?$`)
+	submatch := re.FindStringSubmatch(path)
+	if submatch == nil {
+		return nil, embedError(`yandexmusic`, ErrMalformedURL, player.Src)
+	}
+
+	post := &yandexMusicPost{Height: player.Height}
+	switch {
+	case len(submatch[1]) > 0:
+		post.Kind, post.ID, post.OwnerID = submatch[1], submatch[2], submatch[3]
+	case len(submatch[4]) > 0:
+		post.Kind, post.ID = submatch[4], submatch[5]
+	default:
+		post.Kind, post.OwnerID, post.ID = submatch[6], submatch[7], submatch[8]
+	}
+
+	return post, nil
+}
+
+// YandexMusicToAMP convertes given yandex music player to AMP
+func YandexMusicToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parseYandexMusic(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+// applePodcastsPost contents apple podcasts show or episode data
+type applePodcastsPost struct {
+	// Kind is podcast or episode
+	Kind    string
+	Country string
+	Slug    string
+	// PodcastID is id of the show and EpisodeID is id of its episode
+	PodcastID string
+	EpisodeID string
+	Height    int64
+}
+
+// path returns path of the podcast on apple podcasts
+func (post *applePodcastsPost) path() string {
+	path := "/" + post.Country + "/podcast/" + post.Slug + "/id" + post.PodcastID
+	if post.Kind == "episode" {
+		path += "?i=" + post.EpisodeID
+	}
+
+	return path
+}
+
+// src returns url of apple podcasts player
+func (post *applePodcastsPost) src() string {
+	return "https://embed.podcasts.apple.com" + post.path()
+}
+
+// height returns height of the player, shows have a list of episodes
+func (post *applePodcastsPost) height() int64 {
+	switch {
+	case post.Height > 0:
+		return post.Height
+	case post.Kind == "episode":
+		return 175
+	}
+
+	return 450
+}
+
+// printAMP returns ready to handle AMP with given parameters
+func (post *applePodcastsPost) printAMP() []byte {
+	return printAudioAMP(post.src(), post.height())
+}
+
+// parseApplePodcasts extracts apple podcasts show or episode data from given embeddable html
+func parseApplePodcasts(htmlText []byte) (*applePodcastsPost, error) {
+	player, err := parsePlayer(`applepodcasts`, htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	post := &applePodcastsPost{Height: player.Height}
+	if err := post.parseSrc(player.Src, "embed.podcasts.apple.com"); err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+// parseSrc extracts show and episode from url of given host
+func (post *applePodcastsPost) parseSrc(src, host string) error {
+	urlPtr, err := url.Parse(src)
+	if err != nil {
+		return embedError(`applepodcasts`, ErrMalformedURL, src)
+	}
+
+	if urlPtr.Hostname() != host {
+		return embedError(`applepodcasts`, ErrWrongHost, urlPtr.Hostname())
+	}
+
+	re := regexp.MustCompile(`^/([a-z]{2})/podcast/([A-Za-z0-9%_-]+)/id(\d+)/?$`)
+	submatch := re.FindStringSubmatch(urlPtr.EscapedPath())
+	if submatch == nil {
+		return embedError(`applepodcasts`, ErrMalformedURL, src)
+	}
+
+	post.Kind, post.Country, post.Slug, post.PodcastID = "podcast", submatch[1], submatch[2], submatch[3]
+	if episode := urlPtr.Query().Get("i"); len(episode) > 0 {
+		if _, err := strconv.ParseUint(episode, 10, 64); err != nil {
+			return embedError(`applepodcasts`, ErrMalformedEmbed, episode)
+		}
+		post.Kind, post.EpisodeID = "episode", episode
+	}
+
+	return nil
+}
+
+// ApplePodcastsToAMP convertes given apple podcasts player to AMP
+func ApplePodcastsToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parseApplePodcasts(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+// threadsPost contents threads post data
+type threadsPost struct {
+	User      string
+	Shortcode string
+	Href      string
+}
+
+// url returns canonical url of threads post
+func (post *threadsPost) url() string {
+	return "https://www.threads.com/@" + post.User + "/post/" + post.Shortcode
+}
+
+// printAMP returns ready to handle AMP with given parameters
+func (post *threadsPost) printAMP() []byte {
+	return printPostAMP(post.url()+"/embed", 0, 0)
+}
+
+// parseThreads extracts threads post data from given embeddable html
+func parseThreads(htmlText []byte) (*threadsPost, error) {
+	pointerNode, err := html.Parse(bytes.NewReader(htmlText))
+	if err != nil {
+		return nil, embedError(`threads`, ErrMalformedEmbed, "")
+	}
+	var post threadsPost
+
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.DataAtom == atom.Blockquote {
+			for _, bq := range n.Attr {
+				if bq.Key == "data-text-post-permalink" {
+					post.Href = bq.Val
+					return
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(pointerNode)
+
+	if len(post.Href) < 1 {
+		return nil, embedError(`threads`, ErrNoSource, "")
+	}
+
+	urlPtr, err := url.Parse(post.Href)
+	if err != nil {
+		return nil, embedError(`threads`, ErrMalformedURL, post.Href)
+	}
+
+	if !regexp.MustCompile(`^(?:www\.)?threads\.(?:net|com)$`).MatchString(urlPtr.Hostname()) {
+		return nil, embedError(`threads`, ErrWrongHost, urlPtr.Hostname())
+	}
+
+	re := regexp.MustCompile(`^/@([A-Za-z0-9._]{1,30})/post/([A-Za-z0-9_-]+)/?$`)
+	submatch := re.FindStringSubmatch(urlPtr.Path)
+	if submatch == nil {
+		return nil, embedError(`threads`, ErrMalformedURL, post.Href)
+	}
+	post.User, post.Shortcode = submatch[1], submatch[2]
+
+	return &post, nil
+}
+
+// ThreadsToAMP convertes given threads embeddable html to AMP
+func ThreadsToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parseThreads(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+// mastodonRe matches path of mastodon status embed on any instance
+var mastodonRe = regexp.MustCompile(`/(?:@[A-Za-z0-9_]+(?:@[A-Za-z0-9.-]+)?|users/[A-Za-z0-9_]+/statuses)/\d+/embed`)
+
+// mastodonPost contents mastodon status data
+type mastodonPost struct {
+	// Host is mastodon instance
+	Host string
+	// User is account name, it has domain for accounts of other instances
+	User     string
+	StatusID string
+	Width    int64
+	Height   int64
+}
+
+// url returns canonical url of mastodon status
+func (post *mastodonPost) url() string {
+	return "https://" + post.Host + "/@" + post.User + "/" + post.StatusID
+}
+
+// printAMP returns ready to handle AMP with given parameters
+func (post *mastodonPost) printAMP() []byte {
+	return printPostAMP(post.url()+"/embed", post.Width, post.Height)
+}
+
+// parseMastodon extracts mastodon status data from given iframe or blockquote
+// What is that? Look https://docs.joinmastodon.org/methods/oembed/
+func parseMastodon(htmlText []byte) (*mastodonPost, error) {
+	pointerNode, err := html.Parse(bytes.NewReader(htmlText))
+	if err != nil {
+		return nil, embedError(`mastodon`, ErrMalformedEmbed, "")
+	}
+	var post mastodonPost
+	var src string
+
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.DataAtom == atom.Iframe || n.DataAtom == atom.Blockquote {
+			for _, a := range n.Attr {
+				switch {
+				case a.Key == "src" && n.DataAtom == atom.Iframe, a.Key == "data-embed-url":
+					src = a.Val
+				case a.Key == "width":
+					w, err := strconv.ParseInt(a.Val, 10, 0)
+					if err == nil {
+						post.Width = w
+					}
+				case a.Key == "height":
+					h, err := strconv.ParseInt(a.Val, 10, 0)
+					if err == nil {
+						post.Height = h
+					}
+				}
+			}
+			if len(src) > 0 {
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(pointerNode)
+
+	if len(src) < 1 {
+		return nil, embedError(`mastodon`, ErrNoSource, "")
+	}
+
+	if err := post.parseSrc(src); err != nil {
+		return nil, err
+	}
+
+	return &post, nil
+}
+
+// parseSrc extracts instance, account and status from url of status embed
+func (post *mastodonPost) parseSrc(src string) error {
+	urlPtr, err := url.Parse(src)
+	if err != nil {
+		return embedError(`mastodon`, ErrMalformedURL, src)
+	}
+
+	if urlPtr.Scheme != "https" {
+		return embedError(`mastodon`, ErrInsecureScheme, src)
+	}
+
+	// any instance can be used, so only the shape of the host is checked
+	if !regexp.MustCompile(`^[a-z0-9-]+(?:\.[a-z0-9-]+)+$`).MatchString(urlPtr.Host) {
+		return embedError(`mastodon`, ErrWrongHost, urlPtr.Host)
+	}
+
+	re := regexp.MustCompile(`^/(?:@([A-Za-z0-9_]+(?:@[a-z0-9.-]+)?)|users/([A-Za-z0-9_]+)/statuses)/(\d+)/embed/?$`)
+	submatch := re.FindStringSubmatch(urlPtr.Path)
+	if submatch == nil {
+		return embedError(`mastodon`, ErrMalformedURL, src)
+	}
+
+	post.Host, post.User, post.StatusID = urlPtr.Host, submatch[1]+submatch[2], submatch[3]
+
+	return nil
+}
+
+// MastodonToAMP convertes given mastodon status embed of any instance to AMP
+func MastodonToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parseMastodon(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+// blueskyPost contents bluesky post data
+type blueskyPost struct {
+	// DID is decentralized identifier of author
+	DID string
+	// RKey is record key of the post
+	RKey string
+	URI  string
+}
+
+// url returns canonical url of bluesky post
+func (post *blueskyPost) url() string {
+	return "https://bsky.app/profile/" + post.DID + "/post/" + post.RKey
+}
+
+// src returns url of bluesky post embed
+func (post *blueskyPost) src() string {
+	return "https://embed.bsky.app/embed/" + post.DID + "/app.bsky.feed.post/" + post.RKey
+}
+
+// printAMP returns ready to handle AMP with given parameters
+func (post *blueskyPost) printAMP() []byte {
+	return printPostAMP(post.src(), 0, 0)
+}
+
+// parseBluesky extracts bluesky post data from given embeddable html
+// What is that? Look https://embed.bsky.app
+func parseBluesky(htmlText []byte) (*blueskyPost, error) {
+	pointerNode, err := html.Parse(bytes.NewReader(htmlText))
+	if err != nil {
+		return nil, embedError(`bluesky`, ErrMalformedEmbed, "")
+	}
+	var post blueskyPost
+
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.DataAtom == atom.Blockquote {
+			for _, bq := range n.Attr {
+				if bq.Key == "data-bluesky-uri" {
+					post.URI = bq.Val
+					return
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(pointerNode)
+
+	if len(post.URI) < 1 {
+		return nil, embedError(`bluesky`, ErrNoSource, "")
+	}
+
+	re := regexp.MustCompile(`^at://(did:(?:plc:[a-z2-7]{24}|web:[a-z0-9.-]+))/app\.bsky\.feed\.post/([A-Za-z0-9._:~-]{1,512})$`)
+	submatch := re.FindStringSubmatch(post.URI)
+	if submatch == nil {
+		return nil, embedError(`bluesky`, ErrMalformedURL, post.URI)
+	}
+	post.DID, post.RKey = submatch[1], submatch[2]
+
+	return &post, nil
+}
+
+// BlueskyToAMP convertes given bluesky embeddable html to AMP
+func BlueskyToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parseBluesky(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+// okPost contents odnoklassniki video or topic data
+type okPost struct {
+	// Element is id of placeholder element of content widget
+	Element string
+	// Kind is `video`, `groupTopic` or `userTopic`
+	Kind    string
+	ID      int64
+	OwnerID int64
+	Width   int64
+	Height  int64
+}
+
+// url returns canonical url of odnoklassniki video or topic
+func (post *okPost) url() string {
+	switch post.Kind {
+	case "groupTopic":
+		return fmt.Sprintf("https://ok.ru/group/%d/topic/%d", post.OwnerID, post.ID)
+	case "userTopic":
+		return fmt.Sprintf("https://ok.ru/profile/%d/statuses/%d", post.OwnerID, post.ID)
+	}
+
+	return fmt.Sprintf("https://ok.ru/video/%d", post.ID)
+}
+
+// src returns url of odnoklassniki video player
+func (post *okPost) src() string {
+	return fmt.Sprintf("https://ok.ru/videoembed/%d", post.ID)
+}
+
+// printAMP returns ready to handle AMP with given parameters.
+// Topics are drawn by odnoklassniki script only and AMP has no component for them,
+// so they are downgraded to links to the topic.
+func (post *okPost) printAMP() []byte {
+	if post.Kind != "video" {
+		return []byte(fmt.Sprintf(`<a href="%s">%s</a>`, post.url(), post.url()))
+	}
+
+	return printPlayerAMP(post.src(), post.Width, post.Height)
+}
+
+// parseOk extracts odnoklassniki data from given video iframe or content widget
+func parseOk(htmlText []byte) (*okPost, error) {
+	if bytes.Contains(htmlText, []byte(`insertContentWidget`)) {
+		return parseOkWidget(htmlText)
+	}
+
+	player, err := parsePlayer(`odnoklassniki`, htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	urlPtr, err := url.Parse(player.Src)
+	if err != nil {
+		return nil, embedError(`odnoklassniki`, ErrMalformedURL, player.Src)
+	}
+
+	if !regexp.MustCompile(`^(?:(?:www|m)\.)?(?:ok|odnoklassniki)\.ru$`).MatchString(urlPtr.Hostname()) {
+		return nil, embedError(`odnoklassniki`, ErrWrongHost, urlPtr.Hostname())
+	}
+
+	submatch := regexp.MustCompile(`^/videoembed/(\d+)/?$`).FindStringSubmatch(urlPtr.Path)
+	if submatch == nil {
+		return nil, embedError(`odnoklassniki`, ErrMalformedURL, player.Src)
+	}
+
+	videoID, err := strconv.ParseInt(submatch[1], 10, 0)
+	if err != nil || videoID < 1 {
+		return nil, embedError(`odnoklassniki`, ErrMalformedEmbed, submatch[1])
+	}
+
+	return &okPost{Kind: "video", ID: videoID, Width: player.Width, Height: player.Height}, nil
+}
+
+// parseOkWidget extracts odnoklassniki data from content widget script.
+// The widget gets JSON strings of media and settings, e.g.
+// OK.CONNECT.insertContentWidget("ok_post", "{\"topicId\":\"1\",\"groupId\":\"2\"}", "{\"width\":500}")
+// What is that? Look https://apiok.ru/ext/widgets
+func parseOkWidget(htmlText []byte) (*okPost, error) {
+	var media, settings map[string]interface{}
+	var element string
+
+	re := regexp.MustCompile(`"((?:[^"\\]|\\.)*)"`)
+	for _, literal := range re.FindAll(htmlText, -1) {
+		val, err := strconv.Unquote(string(literal))
+		if err != nil {
+			continue
+		}
+		var obj map[string]interface{}
+		dec := json.NewDecoder(strings.NewReader(val))
+		dec.UseNumber()
+		if !strings.HasPrefix(val, "{") || dec.Decode(&obj) != nil {
+			// placeholder is the argument just before media
+			if media == nil {
+				element = val
+			}
+			continue
+		}
+		switch {
+		case media == nil && (obj["topicId"] != nil || obj["movieId"] != nil):
+			media = obj
+		case media != nil && settings == nil:
+			settings = obj
+		}
+	}
+
+	if media == nil {
+		return nil, embedError(`odnoklassniki`, ErrNoSource, "")
+	}
+
+	post := okPost{Element: element}
+	var err error
+
+	// every identifier is number and owner and topic should be given together
+	id := func(key string) int64 {
+		if err != nil {
+			return 0
+		}
+		val := fmt.Sprint(media[key])
+		num, e := strconv.ParseInt(val, 10, 0)
+		if e != nil || num < 1 {
+			err = embedError(`odnoklassniki`, ErrMalformedEmbed, val)
+		}
+		return num
+	}
+
+	switch {
+	case media["movieId"] != nil:
+		post.Kind, post.ID = "video", id("movieId")
+	case media["groupId"] != nil:
+		post.Kind, post.ID, post.OwnerID = "groupTopic", id("topicId"), id("groupId")
+	case media["userId"] != nil:
+		post.Kind, post.ID, post.OwnerID = "userTopic", id("topicId"), id("userId")
+	default:
+		return nil, embedError(`odnoklassniki`, ErrMalformedEmbed, fmt.Sprint(media["topicId"]))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if w, e := strconv.ParseInt(fmt.Sprint(settings["width"]), 10, 0); e == nil {
+		post.Width = w
+	}
+	if h, e := strconv.ParseInt(fmt.Sprint(settings["height"]), 10, 0); e == nil {
+		post.Height = h
+	}
+
+	return &post, nil
+}
+
+// OkToAMP convertes given odnoklassniki video iframe or content widget to AMP
+func OkToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parseOk(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+// printChartAMP returns resizable amp-iframe of chart with given src.
+// Charts know their height only after drawing, so they may ask to resize
+// and overflow button lets reader see the whole chart otherwise.
+func printChartAMP(src string, height int64) []byte {
+	template := `<amp-iframe height="%d" layout="fixed-height" sandbox="allow-scripts allow-same-origin allow-popups" resizable frameborder="0" src="%s"><div overflow tabindex="0" role="button" aria-label="Show more">Show more</div></amp-iframe>`
+
+	amp := fmt.Sprintf(template, height, src)
+
+	return []byte(amp)
+}
+
+// parseChart extracts chart source and height from given iframe or from
+// dataAttr of element which chart script replaces with iframe
+func parseChart(provider string, htmlText []byte, dataAttr string) (*iframePost, error) {
+	pointerNode, err := html.Parse(bytes.NewReader(htmlText))
+	if err != nil {
+		return nil, embedError(provider, ErrMalformedEmbed, "")
+	}
+	var post iframePost
+
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			for _, a := range n.Attr {
+				switch {
+				case a.Key == dataAttr, a.Key == "src" && n.DataAtom == atom.Iframe:
+					post.Src = a.Val
+				case a.Key == "height", a.Key == "data-height":
+					h, err := strconv.ParseInt(strings.TrimSuffix(a.Val, "px"), 10, 0)
+					if err == nil {
+						post.Height = h
+					}
+				}
+			}
+			if len(post.Src) > 0 {
+				return
+			}
+			post.Height = 0
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(pointerNode)
+
+	if len(post.Src) < 1 {
+		return nil, embedError(provider, ErrNoSource, "")
+	}
+
+	return &post, nil
+}
+
+// datawrapperPost contents datawrapper chart data
+type datawrapperPost struct {
+	ChartID string
+	// Version is published version of chart, the latest one is shown without it
+	Version string
+	Height  int64
+}
+
+// src returns url of datawrapper chart
+func (post *datawrapperPost) src() string {
+	src := "https://datawrapper.dwcdn.net/" + post.ChartID + "/"
+	if len(post.Version) > 0 {
+		src += post.Version + "/"
+	}
+
+	return src
+}
+
+// height returns height of chart or default one
+func (post *datawrapperPost) height() int64 {
+	if post.Height == 0 {
+		return 400
+	}
+
+	return post.Height
+}
+
+// printAMP returns ready to handle AMP with given parameters
+func (post *datawrapperPost) printAMP() []byte {
+	return printChartAMP(post.src(), post.height())
+}
+
+// parseDatawrapper extracts datawrapper chart data from given iframe
+// What is that? Look https://academy.datawrapper.de/article/180-how-to-embed-charts
+func parseDatawrapper(htmlText []byte) (*datawrapperPost, error) {
+	chart, err := parseChart(`datawrapper`, htmlText, "")
+	if err != nil {
+		return nil, err
+	}
+
+	post := &datawrapperPost{Height: chart.Height}
+	if err := post.parseSrc(chart.Src); err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+// parseSrc extracts chart and version from url of datawrapper chart
+func (post *datawrapperPost) parseSrc(src string) error {
+	urlPtr, err := url.Parse(src)
+	if err != nil {
+		return embedError(`datawrapper`, ErrMalformedURL, src)
+	}
+
+	if urlPtr.Hostname() != "datawrapper.dwcdn.net" {
+		return embedError(`datawrapper`, ErrWrongHost, urlPtr.Hostname())
+	}
+
+	submatch := regexp.MustCompile(`^/([A-Za-z0-9]{5})/(?:(\d+)/?)?$`).FindStringSubmatch(urlPtr.Path)
+	if submatch == nil {
+		return embedError(`datawrapper`, ErrMalformedURL, src)
+	}
+	post.ChartID, post.Version = submatch[1], submatch[2]
+
+	return nil
+}
+
+// DatawrapperToAMP convertes given datawrapper chart to AMP
+func DatawrapperToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parseDatawrapper(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+// flourishPost contents flourish visualisation or story data
+type flourishPost struct {
+	// Kind is `visualisation` or `story`
+	Kind   string
+	ID     string
+	Height int64
+}
+
+// url returns canonical url of flourish visualisation or story
+func (post *flourishPost) url() string {
+	return "https://public.flourish.studio/" + post.Kind + "/" + post.ID + "/"
+}
+
+// src returns url of flourish embed
+func (post *flourishPost) src() string {
+	return "https://flo.uri.sh/" + post.Kind + "/" + post.ID + "/embed"
+}
+
+// height returns height of visualisation or default one
+func (post *flourishPost) height() int64 {
+	if post.Height == 0 {
+		return 575
+	}
+
+	return post.Height
+}
+
+// printAMP returns ready to handle AMP with given parameters
+func (post *flourishPost) printAMP() []byte {
+	return printChartAMP(post.src(), post.height())
+}
+
+// parseFlourish extracts flourish data from given embed div or iframe
+// What is that? Look https://help.flourish.studio/article/22-how-to-embed-your-visualisation
+func parseFlourish(htmlText []byte) (*flourishPost, error) {
+	chart, err := parseChart(`flourish`, htmlText, "data-src")
+	if err != nil {
+		return nil, err
+	}
+
+	urlPtr, err := url.Parse(chart.Src)
+	if err != nil {
+		return nil, embedError(`flourish`, ErrMalformedURL, chart.Src)
+	}
+
+	// embed div has just path in data-src, e.g. visualisation/1234567
+	path := "/" + strings.TrimPrefix(urlPtr.Path, "/")
+	re := regexp.MustCompile(`^/(visualisation|story)/(\d+)/?$`)
+	if len(urlPtr.Host) > 0 {
+		if urlPtr.Hostname() != "flo.uri.sh" {
+			return nil, embedError(`flourish`, ErrWrongHost, urlPtr.Hostname())
+		}
+		re = regexp.MustCompile(`^/(visualisation|story)/(\d+)/embed$`)
+	}
+
+	submatch := re.FindStringSubmatch(path)
+	if submatch == nil {
+		return nil, embedError(`flourish`, ErrMalformedURL, chart.Src)
+	}
+
+	return &flourishPost{Kind: submatch[1], ID: submatch[2], Height: chart.Height}, nil
+}
+
+// FlourishToAMP convertes given flourish embed to AMP
+func FlourishToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parseFlourish(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+// infogramPost contents infogram chart data
+type infogramPost struct {
+	ID     string
+	Height int64
+}
+
+// url returns canonical url of infogram chart
+func (post *infogramPost) url() string {
+	return "https://infogram.com/" + post.ID
+}
+
+// src returns url of infogram embed
+func (post *infogramPost) src() string {
+	return "https://e.infogram.com/" + post.ID + "?src=embed"
+}
+
+// height returns height of chart or default one
+func (post *infogramPost) height() int64 {
+	if post.Height == 0 {
+		return 500
+	}
+
+	return post.Height
+}
+
+// printAMP returns ready to handle AMP with given parameters
+func (post *infogramPost) printAMP() []byte {
+	return printChartAMP(post.src(), post.height())
+}
+
+// parseInfogram extracts infogram chart data from given embed div or iframe
+func parseInfogram(htmlText []byte) (*infogramPost, error) {
+	chart, err := parseChart(`infogram`, htmlText, "data-id")
+	if err != nil {
+		return nil, err
+	}
+
+	id := chart.Src
+	if strings.Contains(chart.Src, "//") {
+		urlPtr, err := url.Parse(chart.Src)
+		if err != nil {
+			return nil, embedError(`infogram`, ErrMalformedURL, chart.Src)
+		}
+		if urlPtr.Hostname() != "e.infogram.com" {
+			return nil, embedError(`infogram`, ErrWrongHost, urlPtr.Hostname())
+		}
+		id = strings.TrimPrefix(urlPtr.Path, "/")
+	}
+
+	if !regexp.MustCompile(`^(?:_/)?[A-Za-z0-9_-]+$`).MatchString(id) {
+		return nil, embedError(`infogram`, ErrMalformedEmbed, chart.Src)
+	}
+
+	return &infogramPost{ID: id, Height: chart.Height}, nil
+}
+
+// InfogramToAMP convertes given infogram embed to AMP
+func InfogramToAMP(htmlText []byte) ([]byte, error) {
+	post, err := parseInfogram(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
 	return post.printAMP(), nil
 }
@@ -0,0 +1,493 @@
+package turboamper
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Article contents result of whole article conversion
+type Article struct {
+	// Body is converted html of the article
+	Body []byte
+	// Embeds are embeds found and converted in the article, in order of appearance
+	Embeds []*Embed
+	// Failures are errors of embeds which cannot be converted.
+	// Every failure is *EmbedError.
+	Failures []error
+	// Elements are AMP custom elements used in Body, see CustomElements.
+	// It is empty for Yandex Turbo.
+	Elements []string
+}
+
+// embedClasses are classes of elements which are embeds themselves, not just containers
+var embedClasses = map[string]bool{
+	"playbuzz":         true,
+	"reddit-embed":     true,
+	"flourish-embed":   true,
+	"infogram-embed":   true,
+	"twitter-timeline": true,
+	"fb-post":          true,
+	"fb-video":         true,
+	"fb-comments":      true,
+	"fb-page":          true,
+	"fb-like":          true,
+}
+
+// sdkHosts are hosts of scripts which draw embeds shown by Yandex Turbo as is
+var sdkHosts = map[string]bool{
+	"vk.com":                true,
+	"www.instagram.com":     true,
+	"platform.twitter.com":  true,
+	"connect.facebook.net":  true,
+	"embed.reddit.com":      true,
+	"embed.redditmedia.com": true,
+}
+
+// iframeSDKHosts are hosts of scripts which draw embeds converted to iframes or links
+// for Yandex Turbo, so the scripts are not needed there and removed silently
+var iframeSDKHosts = map[string]bool{
+	"www.tiktok.com":         true,
+	"assets.pinterest.com":   true,
+	"www.threads.net":        true,
+	"www.threads.com":        true,
+	"embed.bsky.app":         true,
+	"connect.ok.ru":          true,
+	"public.flourish.studio": true,
+}
+
+// iframeSDKPaths are paths of such scripts on any host, e.g. mastodon instances.
+// Scripts of sdkHosts with the same path are kept.
+var iframeSDKPaths = map[string]bool{
+	"/embed.js": true,
+}
+
+// loaderScripts are markers of inline scripts which load or resize embeds
+// converted to iframes, so the scripts are removed silently
+var loaderScripts = [][]byte{
+	[]byte(`datawrapper-height`),
+	[]byte(`infogram-async`),
+}
+
+// embedAttrs are attributes of elements which are embeds themselves, e.g. widget scripts
+var embedAttrs = map[string]bool{
+	"data-telegram-post": true,
+	"data-pin-do":        true,
+}
+
+// quoteClasses are classes of blockquotes which are embeds, other blockquotes are just quotes
+var quoteClasses = map[string]bool{
+	"instagram-media":       true,
+	"twitter-tweet":         true,
+	"twitter-video":         true,
+	"tiktok-embed":          true,
+	"text-post-media":       true,
+	"mastodon-embed":        true,
+	"bluesky-embed":         true,
+	"reddit-card":           true,
+	"reddit-embed-bq":       true,
+	"fb-xfbml-parse-ignore": true,
+}
+
+// quoteAttrs are attributes of blockquotes which are embeds
+var quoteAttrs = map[string]bool{
+	"data-instgrm-permalink":   true,
+	"data-text-post-permalink": true,
+	"data-bluesky-uri":         true,
+}
+
+// ampMedia are media tags and AMP components which replace them in strict conversion
+var ampMedia = map[atom.Atom]string{
+	atom.Img:   "amp-img",
+	atom.Video: "amp-video",
+	atom.Audio: "amp-audio",
+}
+
+// mediaAttrs are attributes of media tags kept by their AMP components
+var mediaAttrs = map[string]bool{
+	"src":      true,
+	"srcset":   true,
+	"sizes":    true,
+	"alt":      true,
+	"title":    true,
+	"id":       true,
+	"class":    true,
+	"poster":   true,
+	"controls": true,
+	"autoplay": true,
+	"loop":     true,
+	"muted":    true,
+}
+
+// Sizes of media which have no width and height, AMP cannot lay them out without sizes
+const (
+	defaultMediaWidth  = 640
+	defaultMediaHeight = 360
+	audioHeight        = 50
+)
+
+// ArticleToAMP convertes every embed of given html article to AMP in place.
+// Embeds which cannot be converted are reported in Failures; they are kept as is
+// if AMP allows them or removed otherwise.
+func ArticleToAMP(body []byte) (*Article, error) {
+	return articleToAMP(body, false)
+}
+
+// articleToAMP convertes article to AMP, strict conversion makes the whole body valid AMP:
+// media are converted to AMP components, other markup AMP forbids is reported and removed.
+func articleToAMP(body []byte, strict bool) (*Article, error) {
+	conv := articleConverter{render: renderAMP, strict: strict}
+
+	article, err := conv.convert(body)
+	if err != nil {
+		return nil, err
+	}
+	article.Elements = CustomElements(article.Body)
+
+	return article, nil
+}
+
+// ArticleToTurbo convertes every embed of given html article to Yandex Turbo in place.
+// Embeds shown by Turbo as is are validated and kept with scripts of their SDK.
+// Embeds which cannot be shown are reported in Failures; iframes, scripts
+// and widget placeholders are removed, other elements are kept as is.
+func ArticleToTurbo(body []byte) (*Article, error) {
+	conv := articleConverter{render: Provider.Turbo, keepSDK: true}
+
+	return conv.convert(body)
+}
+
+type articleConverter struct {
+	render func(Provider, *Embed) ([]byte, error)
+	// keepSDK keeps scripts loaded from sdkHosts
+	keepSDK bool
+	// strict converts media and removes markup AMP forbids, see articleToAMP
+	strict  bool
+	root    *html.Node
+	article Article
+}
+
+// convert parses given body, walks through its nodes and renders result
+func (conv *articleConverter) convert(body []byte) (*Article, error) {
+	nodes, err := html.ParseFragment(bytes.NewReader(body), bodyContext())
+	if err != nil {
+		return nil, err
+	}
+
+	conv.root = &html.Node{Type: html.DocumentNode}
+	for _, n := range nodes {
+		conv.root.AppendChild(n)
+	}
+
+	conv.walk(conv.root)
+
+	var buf bytes.Buffer
+	for c := conv.root.FirstChild; c != nil; c = c.NextSibling {
+		if err := html.Render(&buf, c); err != nil {
+			return nil, err
+		}
+	}
+	conv.article.Body = buf.Bytes()
+
+	return &conv.article, nil
+}
+
+// walk converts embeds among children of given node
+func (conv *articleConverter) walk(n *html.Node) {
+	// converting may replace any node, so let's remember children first
+	var children []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		children = append(children, c)
+	}
+
+	for _, c := range children {
+		if c.Parent != n || c.Type != html.ElementNode {
+			continue
+		}
+		if conv.strict {
+			removeHandlers(c)
+		}
+		_, forbidden := forbiddenTags[c.Data]
+		switch {
+		case hasEmbedAttr(c), c.DataAtom == atom.Iframe, isEmbedQuote(c), hasEmbedClass(c):
+			conv.element(c)
+		case c.DataAtom == atom.Script:
+			conv.script(c)
+		case conv.strict && ampMedia[c.DataAtom] != "":
+			convertMedia(c)
+		case conv.strict && forbidden:
+			conv.fail(ErrUnsupported, renderNode(c))
+			n.RemoveChild(c)
+		default:
+			conv.walk(c)
+		}
+	}
+}
+
+// element converts embed element or reports failure.
+// Iframes, widget scripts and placeholders are removed if they cannot be converted.
+func (conv *articleConverter) element(n *html.Node) {
+	snippet := renderNode(n)
+	got, embed, err := represent(snippet, conv.render)
+	if err == nil {
+		conv.article.Embeds = append(conv.article.Embeds, embed)
+		if !bytes.Equal(got, snippet) {
+			replaceNode(n, got)
+		}
+		return
+	}
+
+	if n.DataAtom == atom.Blockquote {
+		// quote is kept, but its contents should be converted as well
+		if !errors.Is(err, ErrUnknownEmbed) {
+			conv.fail(err, snippet)
+		}
+		conv.walk(n)
+		return
+	}
+
+	conv.fail(err, snippet)
+	n.Parent.RemoveChild(n)
+}
+
+// script converts inline widget script or removes it.
+// Scripts loading SDK of social networks are kept if keepSDK is set
+// or removed silently otherwise.
+func (conv *articleConverter) script(n *html.Node) {
+	for _, a := range n.Attr {
+		if a.Key != "src" {
+			continue
+		}
+		if !conv.keepSDK {
+			n.Parent.RemoveChild(n)
+			return
+		}
+		urlPtr, err := url.Parse(a.Val)
+		switch {
+		case err == nil && sdkHosts[urlPtr.Hostname()]:
+		case err == nil && (iframeSDKHosts[urlPtr.Hostname()] || iframeSDKPaths[urlPtr.Path]):
+			n.Parent.RemoveChild(n)
+		default:
+			conv.fail(ErrUnsupported, []byte(a.Val))
+			n.Parent.RemoveChild(n)
+		}
+		return
+	}
+
+	snippet := renderNode(n)
+	for _, marker := range loaderScripts {
+		if bytes.Contains(snippet, marker) {
+			n.Parent.RemoveChild(n)
+			return
+		}
+	}
+
+	got, embed, err := represent(snippet, conv.render)
+	if err != nil {
+		if errors.Is(err, ErrUnknownEmbed) {
+			err = ErrUnsupported
+		}
+		conv.fail(err, snippet)
+		n.Parent.RemoveChild(n)
+		return
+	}
+
+	conv.article.Embeds = append(conv.article.Embeds, embed)
+	if bytes.Equal(got, snippet) {
+		return
+	}
+
+	// vkontakte and odnoklassniki widgets are drawn by script in placeholder element
+	if placeholder := widgetPlaceholder(conv.root, embed); placeholder != nil {
+		replaceNode(placeholder, got)
+		n.Parent.RemoveChild(n)
+		return
+	}
+
+	replaceNode(n, got)
+}
+
+// widgetPlaceholder finds element named in vkontakte or odnoklassniki widget call
+func widgetPlaceholder(root *html.Node, embed *Embed) *html.Node {
+	var element string
+	switch embed.Provider {
+	case `vkontakte`:
+		if post, err := parseVk(embed.Raw); err == nil {
+			element = post.Element
+		}
+	case `odnoklassniki`:
+		if post, err := parseOk(embed.Raw); err == nil {
+			element = post.Element
+		}
+	}
+	if len(element) == 0 {
+		return nil
+	}
+
+	return findByID(root, element)
+}
+
+// convertMedia replaces img, video or audio by its AMP component in place.
+// Sources and tracks of video and audio are kept as its children.
+func convertMedia(n *html.Node) {
+	var width, height int
+	var attrs []html.Attribute
+	for _, a := range n.Attr {
+		switch {
+		case a.Key == "width":
+			width, _ = strconv.Atoi(strings.TrimSuffix(a.Val, "px"))
+		case a.Key == "height":
+			height, _ = strconv.Atoi(strings.TrimSuffix(a.Val, "px"))
+		case mediaAttrs[a.Key]:
+			attrs = append(attrs, a)
+		}
+	}
+
+	layout := []html.Attribute{{Key: "height", Val: strconv.Itoa(audioHeight)}, {Key: "layout", Val: "fixed-height"}}
+	if n.DataAtom != atom.Audio {
+		if width <= 0 || height <= 0 {
+			width, height = defaultMediaWidth, defaultMediaHeight
+		}
+		layout = []html.Attribute{{Key: "width", Val: strconv.Itoa(width)}, {Key: "height", Val: strconv.Itoa(height)}, {Key: "layout", Val: "responsive"}}
+	}
+
+	n.Data, n.DataAtom, n.Attr = ampMedia[n.DataAtom], 0, append(layout, attrs...)
+}
+
+// removeHandlers removes event handler attributes which AMP does not allow
+func removeHandlers(n *html.Node) {
+	attrs := n.Attr[:0]
+	for _, a := range n.Attr {
+		if len(a.Key) <= 2 || !strings.HasPrefix(a.Key, "on") {
+			attrs = append(attrs, a)
+		}
+	}
+	n.Attr = attrs
+}
+
+// fail reports embed failure
+func (conv *articleConverter) fail(err error, snippet []byte) {
+	var embedErr *EmbedError
+	if !errors.As(err, &embedErr) {
+		err = &EmbedError{Err: err, Value: string(snippet)}
+	}
+
+	conv.article.Failures = append(conv.article.Failures, err)
+}
+
+// hasEmbedClass tells if the element has one of embedClasses
+func hasEmbedClass(n *html.Node) bool {
+	for _, a := range n.Attr {
+		if a.Key != "class" {
+			continue
+		}
+		for _, class := range strings.Fields(a.Val) {
+			if embedClasses[class] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// isEmbedQuote tells if the element is a blockquote of embed, i.e. it has one of quoteClasses or quoteAttrs
+func isEmbedQuote(n *html.Node) bool {
+	if n.DataAtom != atom.Blockquote {
+		return false
+	}
+	for _, a := range n.Attr {
+		if quoteAttrs[a.Key] {
+			return true
+		}
+		if a.Key != "class" {
+			continue
+		}
+		for _, class := range strings.Fields(a.Val) {
+			if quoteClasses[class] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// hasEmbedAttr tells if the element has one of embedAttrs
+func hasEmbedAttr(n *html.Node) bool {
+	for _, a := range n.Attr {
+		if embedAttrs[a.Key] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nodeAttr returns value of attribute of the element or empty string
+func nodeAttr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+
+	return ""
+}
+
+// bodyContext returns context for parsing fragments of article
+func bodyContext() *html.Node {
+	return &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+}
+
+// findByID returns element with given id
+func findByID(n *html.Node, id string) *html.Node {
+	if n.Type == html.ElementNode {
+		for _, a := range n.Attr {
+			if a.Key == "id" && a.Val == id {
+				return n
+			}
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findByID(c, id); found != nil {
+			return found
+		}
+	}
+
+	return nil
+}
+
+// renderNode returns html of the node
+func renderNode(n *html.Node) []byte {
+	var buf bytes.Buffer
+	html.Render(&buf, n)
+
+	return buf.Bytes()
+}
+
+// insertBefore inserts given html before the node
+func insertBefore(n *html.Node, htmlText []byte) {
+	context := n.Parent
+	if context.Type != html.ElementNode {
+		context = bodyContext()
+	}
+	nodes, err := html.ParseFragment(bytes.NewReader(htmlText), context)
+	if err != nil {
+		return
+	}
+	for _, c := range nodes {
+		n.Parent.InsertBefore(c, n)
+	}
+}
+
+// replaceNode replaces the node with given html
+func replaceNode(n *html.Node, htmlText []byte) {
+	insertBefore(n, htmlText)
+	n.Parent.RemoveChild(n)
+}
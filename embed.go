@@ -0,0 +1,93 @@
+package turboamper
+
+// Embed contents structured data of recognized embed.
+// It can be stored and rendered later by AMP and Turbo methods without parsing html again.
+type Embed struct {
+	// Provider is a name of provider which extracted the embed, e.g. `vkontakte`
+	Provider string `json:"provider"`
+	// URL is canonical url of embedded material
+	URL string `json:"url,omitempty"`
+	// ID is identifier of embedded material: post id, video id, shortcode etc.
+	ID string `json:"id,omitempty"`
+	// OwnerID is identifier of material owner, if provider has it
+	OwnerID string `json:"owner_id,omitempty"`
+	// Hash is access hash of material, if provider has it
+	Hash string `json:"hash,omitempty"`
+	// Kind is kind of material if provider has several ones, e.g. `embedBoard`
+	Kind string `json:"kind,omitempty"`
+
+	Width       int64 `json:"width,omitempty"`
+	Height      int64 `json:"height,omitempty"`
+	Frameborder int64 `json:"frameborder,omitempty"`
+
+	AllowFullscreen bool `json:"allowfullscreen,omitempty"`
+	Captioned       bool `json:"captioned,omitempty"`
+	Video           bool `json:"video,omitempty"`
+	// Media is kind of embedded media: video or audio, it is empty for posts
+	Media string `json:"media,omitempty"`
+	// NoCookie tells that player should not set cookies, e.g. youtube-nocookie.com
+	NoCookie bool `json:"nocookie,omitempty"`
+
+	// Params are player parameters taken from query of embed url, e.g. autoplay
+	Params map[string]string `json:"params,omitempty"`
+
+	// Elements are AMP custom elements of amp-representation of the embed, see CustomElements.
+	// They are filled by Parse, AMP method and ArticleToAMP.
+	Elements []string `json:"elements,omitempty"`
+
+	// Raw is original html of the embed
+	Raw []byte `json:"raw,omitempty"`
+}
+
+// Parse recognizes given html and gives you its structured data.
+// If it cannot recognize your html, it returns ErrUnknownEmbed,
+// if it recognizes but cannot parse it, it returns *EmbedError of the provider.
+func Parse(htmlText []byte) (*Embed, error) {
+	_, embed, err := represent(htmlText, func(p Provider, embed *Embed) ([]byte, error) {
+		// embed which has no amp-representation is still parsed
+		renderAMP(p, embed)
+		return nil, nil
+	})
+
+	return embed, err
+}
+
+// AMP gives you amp-representation of the embed
+func (embed *Embed) AMP() ([]byte, error) {
+	p, err := lookup(embed.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	return renderAMP(p, embed)
+}
+
+// renderAMP gives you amp-representation of the embed by given provider and fills its Elements
+func renderAMP(p Provider, embed *Embed) ([]byte, error) {
+	got, err := p.AMP(embed)
+	if err != nil {
+		return nil, err
+	}
+	embed.Elements = CustomElements(got)
+
+	return got, nil
+}
+
+// Turbo gives you YandexTurbo-representation of the embed
+func (embed *Embed) Turbo() ([]byte, error) {
+	p, err := lookup(embed.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Turbo(embed)
+}
+
+// raw returns original html of the embed for providers which Yandex Turbo shows as is
+func (embed *Embed) raw() ([]byte, error) {
+	if len(embed.Raw) < 1 {
+		return nil, embedError(embed.Provider, ErrNoSource, "")
+	}
+
+	return embed.Raw, nil
+}
@@ -0,0 +1,58 @@
+package turboamper
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Errors which can be matched with errors.Is
+var (
+	// ErrUnknownEmbed means that no provider recognized given html
+	ErrUnknownEmbed = errors.New("unknown embed")
+	// ErrNoSource means that embed has no link to embedded material
+	ErrNoSource = errors.New("no source of embed")
+	// ErrMalformedEmbed means that embed code cannot be parsed
+	ErrMalformedEmbed = errors.New("malformed embed")
+	// ErrMalformedURL means that url of embed cannot be parsed or has unexpected format
+	ErrMalformedURL = errors.New("malformed url")
+	// ErrWrongHost means that url of embed does not belong to the provider
+	ErrWrongHost = errors.New("wrong host")
+	// ErrInsecureScheme means that url of embed is not https
+	ErrInsecureScheme = errors.New("insecure scheme")
+	// ErrUnsupported means that embed cannot be represented in requested format
+	ErrUnsupported = errors.New("unsupported embed")
+	// ErrInvalidCSS means that custom style of AMP page is not allowed by AMP
+	ErrInvalidCSS = errors.New("invalid custom css")
+)
+
+// EmbedError describes why embed of some provider cannot be converted.
+// Use errors.As to get it and errors.Is to match its kind.
+type EmbedError struct {
+	// Provider is a name of provider, e.g. `youtube`
+	Provider string
+	// Value is offending value: url, host, id or piece of widget code
+	Value string
+	// Err is one of Err* values
+	Err error
+}
+
+func (e *EmbedError) Error() string {
+	if e.Provider == "" {
+		return fmt.Sprintf("%v: %s", e.Err, e.Value)
+	}
+	if e.Value == "" {
+		return fmt.Sprintf("%s: %v", e.Provider, e.Err)
+	}
+
+	return fmt.Sprintf("%s: %v: %s", e.Provider, e.Err, e.Value)
+}
+
+// Unwrap gives you kind of the error
+func (e *EmbedError) Unwrap() error {
+	return e.Err
+}
+
+// embedError returns EmbedError of given kind
+func embedError(provider string, err error, value string) error {
+	return &EmbedError{Provider: provider, Err: err, Value: value}
+}
@@ -0,0 +1,208 @@
+package turboamper
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// TurboChannel contents channel data of Yandex Turbo RSS feed
+type TurboChannel struct {
+	Title       string
+	Link        string
+	Description string
+	// Language is language of the channel, e.g. `ru`
+	Language   string
+	Analytics  []TurboAnalytics
+	AdNetworks []TurboAdNetwork
+}
+
+// TurboAnalytics is a counter of Turbo pages, see https://yandex.ru/dev/turbo/doc/settings/analytics.html
+type TurboAnalytics struct {
+	// Type is a counter type: Yandex, Google, LiveInternet, MailRu, Rambler, Mediascope or custom
+	Type   string
+	ID     string
+	Params string
+	// URL is a counter url for custom type
+	URL string
+}
+
+// TurboAdNetwork is an ad block of Turbo pages, see https://yandex.ru/dev/turbo/doc/settings/ad.html
+type TurboAdNetwork struct {
+	// Type is a network type: Yandex or AdFox
+	Type string
+	ID   string
+	// AdID is an identifier of ad place used by figure data-turbo-ad-id in content
+	AdID string
+}
+
+// TurboItem is an article of Yandex Turbo RSS feed
+type TurboItem struct {
+	Link    string
+	Title   string
+	PubDate time.Time
+	Author  string
+	// Image is url of an article cover put into header
+	Image string
+	// Body is html of the article; it is converted by ArticleToTurbo
+	Body []byte
+}
+
+// TurboFeed builds Yandex Turbo RSS feed
+type TurboFeed struct {
+	Channel TurboChannel
+	Items   []TurboItem
+}
+
+// Add appends items to the feed
+func (feed *TurboFeed) Add(items ...TurboItem) {
+	feed.Items = append(feed.Items, items...)
+}
+
+// Write writes the feed to w.
+// Embeds of items which cannot be converted are returned by item links.
+func (feed *TurboFeed) Write(w io.Writer) (map[string][]error, error) {
+	tw, err := NewTurboWriter(w, feed.Channel)
+	if err != nil {
+		return nil, err
+	}
+
+	failures := make(map[string][]error)
+	for _, item := range feed.Items {
+		itemFailures, err := tw.WriteItem(item)
+		if err != nil {
+			return failures, err
+		}
+		if len(itemFailures) > 0 {
+			failures[item.Link] = itemFailures
+		}
+	}
+
+	return failures, tw.Close()
+}
+
+// TurboWriter streams Yandex Turbo RSS feed to io.Writer item by item,
+// so large feeds are not kept in memory.
+type TurboWriter struct {
+	w   io.Writer
+	err error
+}
+
+// NewTurboWriter writes feed header with channel data to w and gives you TurboWriter.
+// Call WriteItem for every article and Close at the end.
+func NewTurboWriter(w io.Writer, channel TurboChannel) (*TurboWriter, error) {
+	tw := &TurboWriter{w: w}
+
+	tw.write(xml.Header)
+	tw.write(`<rss xmlns:yandex="http://news.yandex.ru" xmlns:media="http://search.yahoo.com/mrss/" xmlns:turbo="http://turbo.yandex.ru" version="2.0">` + "\n")
+	tw.write("<channel>\n")
+	tw.element("title", channel.Title)
+	tw.element("link", channel.Link)
+	tw.element("description", channel.Description)
+	if channel.Language != "" {
+		tw.element("language", channel.Language)
+	}
+	for _, a := range channel.Analytics {
+		tw.write(`<turbo:analytics type="` + escapeXML(a.Type) + `"`)
+		tw.attr("id", a.ID)
+		tw.attr("params", a.Params)
+		tw.attr("url", a.URL)
+		tw.write("></turbo:analytics>\n")
+	}
+	for _, ad := range channel.AdNetworks {
+		tw.write(`<turbo:adNetwork type="` + escapeXML(ad.Type) + `"`)
+		tw.attr("id", ad.ID)
+		tw.attr("turbo-ad-id", ad.AdID)
+		tw.write("></turbo:adNetwork>\n")
+	}
+
+	return tw, tw.err
+}
+
+// WriteItem converts the article by ArticleToTurbo and writes it to the feed.
+// Embeds which cannot be converted are returned as failures, see Article.
+func (tw *TurboWriter) WriteItem(item TurboItem) ([]error, error) {
+	if tw.err != nil {
+		return nil, tw.err
+	}
+
+	article, err := ArticleToTurbo(item.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	tw.write(`<item turbo="true">` + "\n")
+	tw.element("link", item.Link)
+	if !item.PubDate.IsZero() {
+		tw.element("pubDate", item.PubDate.Format(time.RFC1123Z))
+	}
+	if item.Author != "" {
+		tw.element("author", item.Author)
+	}
+	tw.write("<turbo:content><![CDATA[\n")
+	tw.cdata(item.header())
+	tw.cdata(article.Body)
+	tw.write("\n]]></turbo:content>\n")
+	tw.write("</item>\n")
+
+	return article.Failures, tw.err
+}
+
+// Close writes the end of the feed. It does not close underlying writer.
+func (tw *TurboWriter) Close() error {
+	tw.write("</channel>\n</rss>\n")
+
+	return tw.err
+}
+
+// write writes s unless previous write failed
+func (tw *TurboWriter) write(s string) {
+	if tw.err != nil {
+		return
+	}
+	_, tw.err = io.WriteString(tw.w, s)
+}
+
+// element writes xml element with escaped text
+func (tw *TurboWriter) element(name, text string) {
+	tw.write(fmt.Sprintf("<%s>%s</%s>\n", name, escapeXML(text), name))
+}
+
+// attr writes xml attribute if value is given
+func (tw *TurboWriter) attr(name, value string) {
+	if value != "" {
+		tw.write(" " + name + `="` + escapeXML(value) + `"`)
+	}
+}
+
+// cdata writes text to CDATA section splitting its end marker
+func (tw *TurboWriter) cdata(text []byte) {
+	tw.write(string(bytes.ReplaceAll(text, []byte("]]>"), []byte("]]]]><![CDATA[>"))))
+}
+
+// header returns Turbo header of the item
+func (item *TurboItem) header() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("<header>")
+	if item.Image != "" {
+		buf.WriteString(`<figure><img src="` + html.EscapeString(item.Image) + `"></figure>`)
+	}
+	buf.WriteString("<h1>" + html.EscapeString(item.Title) + "</h1>")
+	buf.WriteString("</header>\n")
+
+	return buf.Bytes()
+}
+
+// escapeXML returns s escaped for xml text and attributes
+func escapeXML(s string) string {
+	var buf bytes.Buffer
+	if err := xml.EscapeText(&buf, []byte(s)); err != nil {
+		return ""
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,147 @@
+package turboamper
+
+import (
+	"regexp"
+	"strings"
+)
+
+// jsToken is an argument of javascript call or a value of object literal
+type jsToken struct {
+	// Kind is `string`, `number`, `object` or `ident` for anything else
+	Kind string
+	// Val is value of string or number, source text otherwise
+	Val string
+	// Fields are fields of object literal
+	Fields map[string]string
+	// End is offset in the source just after the token
+	End int
+}
+
+var jsNumberRe = regexp.MustCompile(`^-?\d+$`)
+
+// scanCall splits arguments of javascript call. pos is offset of the source
+// just after opening bracket of the call. Strings in any quotes, numbers and
+// object literals are understood, other expressions are kept as source text.
+// It returns false if the call is not closed.
+func scanCall(src []byte, pos int) ([]jsToken, bool) {
+	var args []jsToken
+	for {
+		pos = skipSpace(src, pos)
+		if pos >= len(src) {
+			return nil, false
+		}
+		switch src[pos] {
+		case ')':
+			return args, true
+		case ',':
+			pos++
+			continue
+		}
+
+		token, ok := scanToken(src, pos)
+		if !ok {
+			return nil, false
+		}
+		args = append(args, token)
+		pos = token.End
+	}
+}
+
+// scanToken reads a token which starts at given offset
+func scanToken(src []byte, pos int) (jsToken, bool) {
+	switch src[pos] {
+	case '"', '\'':
+		val, end, ok := scanString(src, pos)
+		return jsToken{Kind: "string", Val: val, End: end}, ok
+	case '{':
+		return scanObject(src, pos)
+	}
+
+	end := pos
+	for end < len(src) && !strings.ContainsRune(",:)} \t\r\n", rune(src[end])) {
+		end++
+	}
+	token := jsToken{Kind: "ident", Val: string(src[pos:end]), End: end}
+	if jsNumberRe.MatchString(token.Val) {
+		token.Kind = "number"
+	}
+
+	return token, end > pos
+}
+
+// scanString reads string literal in single or double quotes
+func scanString(src []byte, pos int) (string, int, bool) {
+	quote := src[pos]
+	var val strings.Builder
+	for i := pos + 1; i < len(src); i++ {
+		switch c := src[i]; {
+		case c == quote:
+			return val.String(), i + 1, true
+		case c == '\\' && i+1 < len(src):
+			i++
+			switch src[i] {
+			case 'n':
+				val.WriteByte('\n')
+			case 't':
+				val.WriteByte('\t')
+			default:
+				val.WriteByte(src[i])
+			}
+		case c == '\n':
+			return "", 0, false
+		default:
+			val.WriteByte(c)
+		}
+	}
+
+	return "", 0, false
+}
+
+// scanObject reads object literal, values of nested objects are kept as source text
+func scanObject(src []byte, pos int) (jsToken, bool) {
+	token := jsToken{Kind: "object", Fields: make(map[string]string)}
+	start := pos
+	pos++
+	for {
+		pos = skipSpace(src, pos)
+		if pos >= len(src) {
+			return token, false
+		}
+		switch src[pos] {
+		case '}':
+			token.Val, token.End = string(src[start:pos+1]), pos+1
+			return token, true
+		case ',':
+			pos++
+			continue
+		}
+
+		key, ok := scanToken(src, pos)
+		if !ok || key.Kind == "object" {
+			return token, false
+		}
+		pos = skipSpace(src, key.End)
+		if pos >= len(src) || src[pos] != ':' {
+			return token, false
+		}
+		pos = skipSpace(src, pos+1)
+		if pos >= len(src) {
+			return token, false
+		}
+		val, ok := scanToken(src, pos)
+		if !ok {
+			return token, false
+		}
+		token.Fields[key.Val] = val.Val
+		pos = val.End
+	}
+}
+
+// skipSpace returns offset of the first not space byte
+func skipSpace(src []byte, pos int) int {
+	for pos < len(src) && strings.IndexByte(" \t\r\n", src[pos]) >= 0 {
+		pos++
+	}
+
+	return pos
+}
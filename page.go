@@ -0,0 +1,165 @@
+package turboamper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// ampBoilerplate is required AMP boilerplate style, see https://amp.dev/documentation/guides-and-tutorials/learn/spec/amp-boilerplate/
+const ampBoilerplate = `<style amp-boilerplate>body{-webkit-animation:-amp-start 8s steps(1,end) 0s 1 normal both;-moz-animation:-amp-start 8s steps(1,end) 0s 1 normal both;-ms-animation:-amp-start 8s steps(1,end) 0s 1 normal both;animation:-amp-start 8s steps(1,end) 0s 1 normal both}@-webkit-keyframes -amp-start{from{visibility:hidden}to{visibility:visible}}@-moz-keyframes -amp-start{from{visibility:hidden}to{visibility:visible}}@-ms-keyframes -amp-start{from{visibility:hidden}to{visibility:visible}}@-o-keyframes -amp-start{from{visibility:hidden}to{visibility:visible}}@keyframes -amp-start{from{visibility:hidden}to{visibility:visible}}</style><noscript><style amp-boilerplate>body{-webkit-animation:none;-moz-animation:none;-ms-animation:none;animation:none}</style></noscript>`
+
+// ampCSSLimit is maximum size of custom style of AMP page in bytes,
+// see https://amp.dev/documentation/guides-and-tutorials/develop/style_and_layout/style_pages/
+const ampCSSLimit = 75000
+
+// importantRe matches !important which AMP does not allow in custom style
+var importantRe = regexp.MustCompile(`(?i)!\s*important`)
+
+// PageMeta contents optional metadata of AMP page
+type PageMeta struct {
+	// Lang is language of the page, e.g. `ru`
+	Lang        string
+	Description string
+	// Images are urls of article images for search engines
+	Images    []string
+	Published time.Time
+	Modified  time.Time
+	// Author is name of article author
+	Author string
+	// Publisher is name of publishing organization and PublisherLogo is url of its logo
+	Publisher     string
+	PublisherLogo string
+	// CSS is custom style of the page, it is put into <style amp-custom>.
+	// It should not be larger than AMP allows and contain !important.
+	CSS string
+	// ComponentVersion is version of AMP components scripts, DefaultComponentVersion by default
+	ComponentVersion string
+}
+
+// newsArticle is schema.org NewsArticle for JSON-LD block
+type newsArticle struct {
+	Context          string       `json:"@context"`
+	Type             string       `json:"@type"`
+	MainEntityOfPage string       `json:"mainEntityOfPage"`
+	Headline         string       `json:"headline"`
+	Description      string       `json:"description,omitempty"`
+	Image            []string     `json:"image,omitempty"`
+	DatePublished    string       `json:"datePublished,omitempty"`
+	DateModified     string       `json:"dateModified,omitempty"`
+	Author           *ldThing     `json:"author,omitempty"`
+	Publisher        *ldPublisher `json:"publisher,omitempty"`
+}
+
+type ldThing struct {
+	Type string `json:"@type"`
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+type ldPublisher struct {
+	ldThing
+	Logo *ldThing `json:"logo,omitempty"`
+}
+
+// AMPPage gives you complete AMP document with given title, canonical url and body.
+// Body is converted like by ArticleToAMP, so it may contain ordinary embeds; images,
+// videos and audios become amp-img, amp-video and amp-audio, other markup AMP forbids
+// (styles, objects, event handlers) is removed. Scripts of all used AMP components are
+// put into the head. Embeds and markup of body which cannot be converted are returned
+// as failures, see Article.
+// Custom style which AMP does not allow is rejected with ErrInvalidCSS.
+func AMPPage(title, canonical string, body []byte, meta PageMeta) ([]byte, []error, error) {
+	if err := checkCSS(meta.CSS); err != nil {
+		return nil, nil, err
+	}
+
+	article, err := articleToAMP(body, true)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ld, err := json.Marshal(meta.newsArticle(title, canonical))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("<!doctype html>\n<html ⚡")
+	if meta.Lang != "" {
+		buf.WriteString(` lang="` + html.EscapeString(meta.Lang) + `"`)
+	}
+	buf.WriteString(">\n<head>\n")
+	buf.WriteString(`<meta charset="utf-8">` + "\n")
+	buf.WriteString(`<script async src="https://cdn.ampproject.org/v0.js"></script>` + "\n")
+	if len(article.Elements) > 0 {
+		buf.Write(Scripts(article.Elements, meta.ComponentVersion))
+		buf.WriteByte('\n')
+	}
+	buf.WriteString("<title>" + html.EscapeString(title) + "</title>\n")
+	buf.WriteString(`<link rel="canonical" href="` + html.EscapeString(canonical) + `">` + "\n")
+	buf.WriteString(`<meta name="viewport" content="width=device-width">` + "\n")
+	if meta.Description != "" {
+		buf.WriteString(`<meta name="description" content="` + html.EscapeString(meta.Description) + `">` + "\n")
+	}
+	buf.WriteString(`<script type="application/ld+json">`)
+	buf.Write(ld)
+	buf.WriteString("</script>\n")
+	buf.WriteString(ampBoilerplate + "\n")
+	if meta.CSS != "" {
+		buf.WriteString("<style amp-custom>" + meta.CSS + "</style>\n")
+	}
+	buf.WriteString("</head>\n<body>\n")
+	buf.Write(article.Body)
+	buf.WriteString("\n</body>\n</html>\n")
+
+	return buf.Bytes(), article.Failures, nil
+}
+
+// checkCSS tells if custom style can be put into AMP page
+func checkCSS(css string) error {
+	switch {
+	case strings.Contains(strings.ToLower(css), "</style"):
+		return fmt.Errorf("%w: closing style tag", ErrInvalidCSS)
+	case importantRe.MatchString(css):
+		return fmt.Errorf("%w: !important is not allowed", ErrInvalidCSS)
+	case len(css) > ampCSSLimit:
+		return fmt.Errorf("%w: %d bytes is larger than %d", ErrInvalidCSS, len(css), ampCSSLimit)
+	}
+
+	return nil
+}
+
+// newsArticle returns JSON-LD data of the page
+func (meta *PageMeta) newsArticle(title, canonical string) *newsArticle {
+	ld := &newsArticle{
+		Context:          "https://schema.org",
+		Type:             "NewsArticle",
+		MainEntityOfPage: canonical,
+		Headline:         title,
+		Description:      meta.Description,
+		Image:            meta.Images,
+	}
+	if !meta.Published.IsZero() {
+		ld.DatePublished = meta.Published.Format(time.RFC3339)
+	}
+	if !meta.Modified.IsZero() {
+		ld.DateModified = meta.Modified.Format(time.RFC3339)
+	}
+	if meta.Author != "" {
+		ld.Author = &ldThing{Type: "Person", Name: meta.Author}
+	}
+	if meta.Publisher != "" {
+		ld.Publisher = &ldPublisher{ldThing: ldThing{Type: "Organization", Name: meta.Publisher}}
+		if meta.PublisherLogo != "" {
+			ld.Publisher.Logo = &ldThing{Type: "ImageObject", URL: meta.PublisherLogo}
+		}
+	}
+
+	return ld
+}
@@ -0,0 +1,1264 @@
+package turboamper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"sync"
+)
+
+// Provider recognizes embeds of some kind and represents them for AMP and Yandex Turbo.
+// Implement it and call Register to teach AMP() and Turbo() your own widgets.
+type Provider interface {
+	// Name returns type of embed, e.g. `vkontakte`.
+	Name() string
+	// Detect tells if given html looks like the provider embed.
+	// It should be cheap, heavy parsing is done by Extract.
+	Detect(htmlText []byte) bool
+	// Extract parses given html into Embed.
+	Extract(htmlText []byte) (*Embed, error)
+	// AMP returns amp-representation of extracted embed.
+	AMP(embed *Embed) ([]byte, error)
+	// Turbo returns YandexTurbo-representation of extracted embed.
+	Turbo(embed *Embed) ([]byte, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = []Provider{
+		vkProvider{},
+		fbProvider{},
+		instaProvider{},
+		twitProvider{},
+		youtubeProvider{},
+		playbuzzProvider{},
+		tiktokProvider{},
+		telegramProvider{},
+		rutubeProvider{},
+		vkVideoProvider{},
+		vimeoProvider{},
+		dailymotionProvider{},
+		pinterestProvider{},
+		redditProvider{},
+		soundcloudProvider{},
+		yandexMusicProvider{},
+		applePodcastsProvider{},
+		threadsProvider{},
+		mastodonProvider{},
+		blueskyProvider{},
+		okProvider{},
+		datawrapperProvider{},
+		flourishProvider{},
+		infogramProvider{},
+	}
+
+	// fallback is consulted when no registered provider recognized the embed
+	fallback Provider = iframeProvider{}
+)
+
+// Register makes provider available for AMP() and Turbo().
+// Providers are tried in order of registration, custom ones after the default ones,
+// but before the generic iframe.
+// If Register is called twice with the same name or if provider is nil, it panics.
+func Register(provider Provider) {
+	if provider == nil {
+		panic("turboamper: Register provider is nil")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	for _, p := range registry {
+		if p.Name() == provider.Name() {
+			panic("turboamper: Register called twice for provider " + provider.Name())
+		}
+	}
+	if fallback.Name() == provider.Name() {
+		panic("turboamper: Register called twice for provider " + provider.Name())
+	}
+
+	registry = append(registry, provider)
+}
+
+// Providers returns registered providers in order they are tried
+func Providers() []Provider {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	list := make([]Provider, 0, len(registry)+1)
+	list = append(list, registry...)
+
+	return append(list, fallback)
+}
+
+// represent tries registered providers one by one until one of them recognizes
+// and renders given html. If all of them fail, it returns error of the first provider
+// which recognized its embed or ErrUnknownEmbed.
+// Detect of many providers is loose, so provider recognized the embed only if
+// it failed with something else than ErrWrongHost or ErrNoSource. Fallback is not
+// tried for recognized embeds, they are not just iframes.
+func represent(htmlText []byte, render func(Provider, *Embed) ([]byte, error)) ([]byte, *Embed, error) {
+	var failure, loose error
+	for _, p := range Providers() {
+		if !p.Detect(htmlText) || (p == fallback && failure != nil) {
+			continue
+		}
+		embed, err := p.Extract(htmlText)
+		if err == nil {
+			if embed.Provider == "" {
+				embed.Provider = p.Name()
+			}
+			var got []byte
+			got, err = render(p, embed)
+			if err == nil {
+				return got, embed, nil
+			}
+		}
+		switch {
+		case failure == nil && recognized(err):
+			failure = err
+		case loose == nil || p == fallback:
+			loose = err
+		}
+	}
+
+	switch {
+	case failure != nil:
+		return nil, nil, failure
+	case loose != nil:
+		return nil, nil, loose
+	}
+
+	return nil, nil, ErrUnknownEmbed
+}
+
+// recognized tells if provider failed with its own embed, not just detected something alike
+func recognized(err error) bool {
+	return !errors.Is(err, ErrWrongHost) && !errors.Is(err, ErrNoSource)
+}
+
+// lookup returns registered provider with given name
+func lookup(name string) (Provider, error) {
+	for _, p := range Providers() {
+		if p.Name() == name {
+			return p, nil
+		}
+	}
+
+	return nil, embedError(name, ErrUnknownEmbed, "")
+}
+
+type vkProvider struct{}
+
+func (vkProvider) Name() string { return `vkontakte` }
+
+func (vkProvider) Detect(htmlText []byte) bool {
+	return vkCallRe.Match(htmlText)
+}
+
+func (p vkProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parseVk(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	embed := &Embed{
+		Provider: p.Name(),
+		URL:      post.url(),
+		ID:       strconv.FormatInt(post.PostID, 10),
+		OwnerID:  strconv.FormatInt(post.OwnerID, 10),
+		Hash:     post.Hash,
+		Kind:     post.Kind,
+		Width:    post.Width,
+		Height:   post.Height,
+		Params:   post.Params,
+		Raw:      htmlText,
+	}
+	if post.Kind == "group" {
+		embed.ID, embed.OwnerID = embed.OwnerID, ""
+	}
+
+	return embed, nil
+}
+
+func (vkProvider) AMP(embed *Embed) ([]byte, error) {
+	post := vkPost{Kind: embed.Kind, Hash: embed.Hash, Width: embed.Width, Height: embed.Height, Params: embed.Params}
+
+	// group has no owner, its id is the owner of the widget
+	if post.Kind == "group" {
+		groupID, err := strconv.ParseInt(embed.ID, 10, 0)
+		if err != nil {
+			return nil, embedError(`vkontakte`, ErrMalformedEmbed, embed.ID)
+		}
+		post.OwnerID = groupID
+
+		return post.printAMP(), nil
+	}
+
+	ownerID, err := strconv.ParseInt(embed.OwnerID, 10, 0)
+	if err != nil {
+		return nil, embedError(`vkontakte`, ErrMalformedEmbed, embed.OwnerID)
+	}
+
+	postID, err := strconv.ParseInt(embed.ID, 10, 0)
+	if err != nil {
+		return nil, embedError(`vkontakte`, ErrMalformedEmbed, embed.ID)
+	}
+	post.OwnerID, post.PostID = ownerID, postID
+
+	return post.printAMP(), nil
+}
+
+func (vkProvider) Turbo(embed *Embed) ([]byte, error) { return embed.raw() }
+
+type fbProvider struct{}
+
+func (fbProvider) Name() string { return `facebook` }
+
+func (fbProvider) Detect(htmlText []byte) bool {
+	return bytes.Contains(htmlText, []byte(`facebook.com`)) || fbClassRe.Match(htmlText)
+}
+
+func (p fbProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parseFb(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	embed := &Embed{
+		Provider: p.Name(),
+		URL:      post.Href,
+		Width:    post.Width,
+		Height:   post.Height,
+		Video:    post.IsVideo,
+		Kind:     post.Kind,
+		Params:   post.Params,
+		Raw:      htmlText,
+	}
+	if post.IsVideo {
+		embed.Media = "video"
+	}
+
+	return embed, nil
+}
+
+func (fbProvider) AMP(embed *Embed) ([]byte, error) {
+	post := fbPost{IsVideo: embed.Video, Width: embed.Width, Height: embed.Height, Href: embed.URL, Kind: embed.Kind, Params: embed.Params}
+
+	return post.printAMP(), nil
+}
+
+func (fbProvider) Turbo(embed *Embed) ([]byte, error) { return embed.raw() }
+
+type instaProvider struct{}
+
+func (instaProvider) Name() string { return `instagram` }
+
+func (instaProvider) Detect(htmlText []byte) bool {
+	return bytes.Contains(htmlText, []byte(`instagram.com`))
+}
+
+func (p instaProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parseInsta(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Embed{
+		Provider:  p.Name(),
+		URL:       post.url(),
+		ID:        post.Shortcode,
+		Kind:      post.Kind,
+		Width:     post.Width,
+		Height:    post.Height,
+		Captioned: post.IsCaptioned,
+		Raw:       htmlText,
+	}, nil
+}
+
+func (instaProvider) AMP(embed *Embed) ([]byte, error) {
+	post := instaPost{IsCaptioned: embed.Captioned, Shortcode: embed.ID, Width: embed.Width, Height: embed.Height}
+
+	return post.printAMP(), nil
+}
+
+func (instaProvider) Turbo(embed *Embed) ([]byte, error) { return embed.raw() }
+
+type twitProvider struct{}
+
+func (twitProvider) Name() string { return `twitter` }
+
+func (twitProvider) Detect(htmlText []byte) bool {
+	return twitRe.Match(htmlText)
+}
+
+func (p twitProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parseTwit(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	canonical := post.Src
+	if urlPtr, err := url.Parse(post.Src); err == nil {
+		canonical = "https://" + urlPtr.Host + urlPtr.Path
+	}
+
+	return &Embed{
+		Provider: p.Name(),
+		URL:      canonical,
+		ID:       post.ID,
+		OwnerID:  post.User,
+		Kind:     post.Timeline,
+		Width:    post.Width,
+		Height:   post.Height,
+		Raw:      htmlText,
+	}, nil
+}
+
+func (twitProvider) AMP(embed *Embed) ([]byte, error) {
+	post := tweetPost{ID: embed.ID, Width: embed.Width, Height: embed.Height, Timeline: embed.Kind, User: embed.OwnerID}
+
+	return post.printAMP(), nil
+}
+
+func (twitProvider) Turbo(embed *Embed) ([]byte, error) { return embed.raw() }
+
+type youtubeProvider struct{}
+
+func (youtubeProvider) Name() string { return `youtube` }
+
+func (youtubeProvider) Detect(htmlText []byte) bool {
+	return bytes.Contains(htmlText, []byte(`youtube.com`)) ||
+		bytes.Contains(htmlText, []byte(`youtube-nocookie.com`)) ||
+		bytes.Contains(htmlText, []byte(`youtu.be/`))
+}
+
+func (p youtubeProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parseYoutube(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	embed := &Embed{
+		Provider:        p.Name(),
+		URL:             "https://www.youtube.com/watch?v=" + post.VideoID,
+		ID:              post.VideoID,
+		Width:           post.Width,
+		Height:          post.Height,
+		Frameborder:     post.Frameborder,
+		AllowFullscreen: post.AllowFS,
+		Video:           true,
+		Media:           "video",
+		NoCookie:        post.NoCookie,
+		Params:          post.Params,
+		Raw:             htmlText,
+	}
+	if len(post.VideoID) < 1 {
+		embed.Kind = "playlist"
+		embed.ID = post.Params["list"]
+		embed.URL = "https://www.youtube.com/playlist?list=" + embed.ID
+	}
+
+	return embed, nil
+}
+
+// post restores youtube video data from the embed
+func (youtubeProvider) post(embed *Embed) *youtubePost {
+	post := &youtubePost{
+		VideoID:     embed.ID,
+		Width:       embed.Width,
+		Height:      embed.Height,
+		AllowFS:     embed.AllowFullscreen,
+		Frameborder: embed.Frameborder,
+		NoCookie:    embed.NoCookie,
+		Params:      embed.Params,
+	}
+	if embed.Kind == "playlist" {
+		post.VideoID = ""
+	}
+
+	return post
+}
+
+func (p youtubeProvider) AMP(embed *Embed) ([]byte, error) { return p.post(embed).printAMP(), nil }
+
+func (p youtubeProvider) Turbo(embed *Embed) ([]byte, error) { return p.post(embed).printTurbo(), nil }
+
+type playbuzzProvider struct{}
+
+func (playbuzzProvider) Name() string { return `playbuzz` }
+
+func (playbuzzProvider) Detect(htmlText []byte) bool {
+	return bytes.Contains(htmlText, []byte(`class="playbuzz"`))
+}
+
+func (p playbuzzProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parsePlaybuzz(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Embed{Provider: p.Name(), ID: post.DataItem, Raw: htmlText}, nil
+}
+
+func (playbuzzProvider) AMP(embed *Embed) ([]byte, error) {
+	post := playbuzzPost{DataItem: embed.ID, Width: embed.Width, Height: embed.Height}
+
+	return post.printAMP(), nil
+}
+
+// Turbo is not supported, Yandex Turbo cannot show playbuzz widgets
+func (playbuzzProvider) Turbo(embed *Embed) ([]byte, error) {
+	return nil, embedError(`playbuzz`, ErrUnsupported, "")
+}
+
+type tiktokProvider struct{}
+
+func (tiktokProvider) Name() string { return `tiktok` }
+
+func (tiktokProvider) Detect(htmlText []byte) bool {
+	return bytes.Contains(htmlText, []byte(`tiktok-embed`))
+}
+
+func (p tiktokProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parseTiktok(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	canonical := ""
+	if urlPtr, err := url.Parse(post.Src); err == nil && len(post.Src) > 0 {
+		canonical = "https://" + urlPtr.Host + urlPtr.Path
+	}
+
+	return &Embed{
+		Provider: p.Name(),
+		URL:      canonical,
+		ID:       post.VideoID,
+		Width:    post.Width,
+		Height:   post.Height,
+		Video:    true,
+		Media:    "video",
+		Raw:      htmlText,
+	}, nil
+}
+
+// post restores tiktok video data from the embed
+func (tiktokProvider) post(embed *Embed) *tiktokPost {
+	return &tiktokPost{VideoID: embed.ID, Width: embed.Width, Height: embed.Height}
+}
+
+func (p tiktokProvider) AMP(embed *Embed) ([]byte, error) { return p.post(embed).printAMP(), nil }
+
+func (p tiktokProvider) Turbo(embed *Embed) ([]byte, error) { return p.post(embed).printTurbo(), nil }
+
+type telegramProvider struct{}
+
+func (telegramProvider) Name() string { return `telegram` }
+
+func (telegramProvider) Detect(htmlText []byte) bool {
+	return bytes.Contains(htmlText, []byte(`data-telegram-post`)) ||
+		bytes.Contains(htmlText, []byte(`t.me/`)) ||
+		bytes.Contains(htmlText, []byte(`telegram.me/`))
+}
+
+func (p telegramProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parseTelegram(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Embed{
+		Provider: p.Name(),
+		URL:      fmt.Sprintf("https://t.me/%s/%d", post.Channel, post.PostID),
+		ID:       strconv.FormatInt(post.PostID, 10),
+		OwnerID:  post.Channel,
+		Width:    post.Width,
+		Height:   post.Height,
+		Raw:      htmlText,
+	}, nil
+}
+
+// post restores telegram post data from the embed
+func (telegramProvider) post(embed *Embed) (*telegramPost, error) {
+	postID, err := strconv.ParseInt(embed.ID, 10, 0)
+	if err != nil {
+		return nil, embedError(`telegram`, ErrMalformedEmbed, embed.ID)
+	}
+
+	return &telegramPost{Channel: embed.OwnerID, PostID: postID, Width: embed.Width, Height: embed.Height}, nil
+}
+
+func (p telegramProvider) AMP(embed *Embed) ([]byte, error) {
+	post, err := p.post(embed)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+func (p telegramProvider) Turbo(embed *Embed) ([]byte, error) {
+	post, err := p.post(embed)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printTurbo(), nil
+}
+
+type rutubeProvider struct{}
+
+func (rutubeProvider) Name() string { return `rutube` }
+
+func (rutubeProvider) Detect(htmlText []byte) bool {
+	return bytes.Contains(htmlText, []byte(`rutube.ru`))
+}
+
+func (p rutubeProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parseRutube(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Embed{
+		Provider: p.Name(),
+		URL:      "https://rutube.ru/video/" + post.VideoID + "/",
+		ID:       post.VideoID,
+		Hash:     post.Key,
+		Width:    post.Width,
+		Height:   post.Height,
+		Video:    true,
+		Media:    "video",
+		Raw:      htmlText,
+	}, nil
+}
+
+// post restores rutube video data from the embed
+func (rutubeProvider) post(embed *Embed) *rutubePost {
+	return &rutubePost{VideoID: embed.ID, Key: embed.Hash, Width: embed.Width, Height: embed.Height}
+}
+
+func (p rutubeProvider) AMP(embed *Embed) ([]byte, error) { return p.post(embed).printAMP(), nil }
+
+func (p rutubeProvider) Turbo(embed *Embed) ([]byte, error) { return p.post(embed).printTurbo(), nil }
+
+type vkVideoProvider struct{}
+
+func (vkVideoProvider) Name() string { return `vkvideo` }
+
+func (vkVideoProvider) Detect(htmlText []byte) bool {
+	return bytes.Contains(htmlText, []byte(`video_ext.php`))
+}
+
+func (p vkVideoProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parseVkVideo(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Embed{
+		Provider: p.Name(),
+		URL:      fmt.Sprintf("https://vk.com/video%d_%d", post.OwnerID, post.VideoID),
+		ID:       strconv.FormatInt(post.VideoID, 10),
+		OwnerID:  strconv.FormatInt(post.OwnerID, 10),
+		Hash:     post.Hash,
+		Width:    post.Width,
+		Height:   post.Height,
+		Video:    true,
+		Media:    "video",
+		Raw:      htmlText,
+	}, nil
+}
+
+// post restores vkontakte video data from the embed
+func (vkVideoProvider) post(embed *Embed) (*vkVideoPost, error) {
+	ownerID, err := strconv.ParseInt(embed.OwnerID, 10, 0)
+	if err != nil {
+		return nil, embedError(`vkvideo`, ErrMalformedEmbed, embed.OwnerID)
+	}
+
+	videoID, err := strconv.ParseInt(embed.ID, 10, 0)
+	if err != nil {
+		return nil, embedError(`vkvideo`, ErrMalformedEmbed, embed.ID)
+	}
+
+	return &vkVideoPost{OwnerID: ownerID, VideoID: videoID, Hash: embed.Hash, Width: embed.Width, Height: embed.Height}, nil
+}
+
+func (p vkVideoProvider) AMP(embed *Embed) ([]byte, error) {
+	post, err := p.post(embed)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+func (p vkVideoProvider) Turbo(embed *Embed) ([]byte, error) {
+	post, err := p.post(embed)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printTurbo(), nil
+}
+
+type vimeoProvider struct{}
+
+func (vimeoProvider) Name() string { return `vimeo` }
+
+func (vimeoProvider) Detect(htmlText []byte) bool {
+	return bytes.Contains(htmlText, []byte(`player.vimeo.com`))
+}
+
+func (p vimeoProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parseVimeo(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	canonical := "https://vimeo.com/" + post.VideoID
+	if len(post.Hash) > 0 {
+		canonical += "/" + post.Hash
+	}
+
+	return &Embed{
+		Provider: p.Name(),
+		URL:      canonical,
+		ID:       post.VideoID,
+		Hash:     post.Hash,
+		Width:    post.Width,
+		Height:   post.Height,
+		Video:    true,
+		Media:    "video",
+		Params:   post.Params,
+		Raw:      htmlText,
+	}, nil
+}
+
+// post restores vimeo video data from the embed
+func (vimeoProvider) post(embed *Embed) *vimeoPost {
+	return &vimeoPost{VideoID: embed.ID, Hash: embed.Hash, Params: embed.Params, Width: embed.Width, Height: embed.Height}
+}
+
+func (p vimeoProvider) AMP(embed *Embed) ([]byte, error) { return p.post(embed).printAMP(), nil }
+
+func (p vimeoProvider) Turbo(embed *Embed) ([]byte, error) { return p.post(embed).printTurbo(), nil }
+
+type dailymotionProvider struct{}
+
+func (dailymotionProvider) Name() string { return `dailymotion` }
+
+func (dailymotionProvider) Detect(htmlText []byte) bool {
+	return bytes.Contains(htmlText, []byte(`dailymotion.com/embed`))
+}
+
+func (p dailymotionProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parseDailymotion(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Embed{
+		Provider: p.Name(),
+		URL:      "https://www.dailymotion.com/video/" + post.VideoID,
+		ID:       post.VideoID,
+		Width:    post.Width,
+		Height:   post.Height,
+		Video:    true,
+		Media:    "video",
+		Params:   post.Params,
+		Raw:      htmlText,
+	}, nil
+}
+
+// post restores dailymotion video data from the embed
+func (dailymotionProvider) post(embed *Embed) *dailymotionPost {
+	return &dailymotionPost{VideoID: embed.ID, Params: embed.Params, Width: embed.Width, Height: embed.Height}
+}
+
+func (p dailymotionProvider) AMP(embed *Embed) ([]byte, error) {
+	return p.post(embed).printAMP(), nil
+}
+
+func (p dailymotionProvider) Turbo(embed *Embed) ([]byte, error) {
+	return p.post(embed).printTurbo(), nil
+}
+
+type pinterestProvider struct{}
+
+func (pinterestProvider) Name() string { return `pinterest` }
+
+func (pinterestProvider) Detect(htmlText []byte) bool {
+	return bytes.Contains(htmlText, []byte(`data-pin-do`))
+}
+
+func (p pinterestProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parsePinterest(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	embed := &Embed{Provider: p.Name(), URL: post.url(), Kind: post.Kind, Width: post.Width, Raw: htmlText}
+	switch post.Kind {
+	case "embedPin":
+		embed.ID = post.PinID
+	case "embedBoard":
+		embed.ID, embed.OwnerID = post.Board, post.User
+	case "embedUser":
+		embed.ID, embed.OwnerID = post.User, post.User
+	}
+
+	return embed, nil
+}
+
+// post restores pinterest widget data from the embed
+func (pinterestProvider) post(embed *Embed) *pinterestPost {
+	post := &pinterestPost{Kind: embed.Kind, Width: embed.Width, User: embed.OwnerID}
+	switch embed.Kind {
+	case "embedPin":
+		post.PinID = embed.ID
+	case "embedBoard":
+		post.Board = embed.ID
+	}
+
+	return post
+}
+
+func (p pinterestProvider) AMP(embed *Embed) ([]byte, error) { return p.post(embed).printAMP(), nil }
+
+func (p pinterestProvider) Turbo(embed *Embed) ([]byte, error) {
+	return p.post(embed).printTurbo(), nil
+}
+
+type redditProvider struct{}
+
+func (redditProvider) Name() string { return `reddit` }
+
+func (redditProvider) Detect(htmlText []byte) bool {
+	return bytes.Contains(htmlText, []byte(`reddit-card`)) || bytes.Contains(htmlText, []byte(`reddit-embed`))
+}
+
+func (p redditProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parseReddit(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	embed := &Embed{
+		Provider: p.Name(),
+		URL:      post.url(),
+		ID:       post.PostID,
+		OwnerID:  post.Subreddit,
+		Kind:     post.Kind,
+		Width:    post.Width,
+		Height:   post.Height,
+		Params:   post.Params,
+		Raw:      htmlText,
+	}
+	if post.Kind == "comment" {
+		embed.ID = post.CommentID
+	}
+
+	return embed, nil
+}
+
+func (redditProvider) AMP(embed *Embed) ([]byte, error) {
+	// permalink of stored embed keeps subreddit, post and comment
+	post := redditPost{Href: embed.URL, Params: embed.Params, Width: embed.Width, Height: embed.Height}
+	if err := post.parseHref(); err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+func (redditProvider) Turbo(embed *Embed) ([]byte, error) { return embed.raw() }
+
+type soundcloudProvider struct{}
+
+func (soundcloudProvider) Name() string { return `soundcloud` }
+
+func (soundcloudProvider) Detect(htmlText []byte) bool {
+	return bytes.Contains(htmlText, []byte(`w.soundcloud.com/player`))
+}
+
+func (p soundcloudProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parseSoundcloud(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	embed := &Embed{
+		Provider: p.Name(),
+		URL:      "https://api.soundcloud.com/" + post.Kind + "s/" + post.ID,
+		ID:       post.ID,
+		Hash:     post.SecretToken,
+		Kind:     post.Kind,
+		Height:   post.Height,
+		Media:    "audio",
+		Raw:      htmlText,
+	}
+	if len(post.Color) > 0 || post.Visual {
+		embed.Params = map[string]string{"color": post.Color, "visual": strconv.FormatBool(post.Visual)}
+	}
+
+	return embed, nil
+}
+
+// post restores soundcloud track or playlist data from the embed
+func (soundcloudProvider) post(embed *Embed) *soundcloudPost {
+	return &soundcloudPost{
+		Kind:        embed.Kind,
+		ID:          embed.ID,
+		SecretToken: embed.Hash,
+		Color:       embed.Params["color"],
+		Visual:      embed.Params["visual"] == "true",
+		Height:      embed.Height,
+	}
+}
+
+func (p soundcloudProvider) AMP(embed *Embed) ([]byte, error) { return p.post(embed).printAMP(), nil }
+
+func (p soundcloudProvider) Turbo(embed *Embed) ([]byte, error) {
+	return p.post(embed).printTurbo(), nil
+}
+
+type yandexMusicProvider struct{}
+
+func (yandexMusicProvider) Name() string { return `yandexmusic` }
+
+func (yandexMusicProvider) Detect(htmlText []byte) bool {
+	return bytes.Contains(htmlText, []byte(`/iframe`)) && bytes.Contains(htmlText, []byte(`music.yandex.`))
+}
+
+func (p yandexMusicProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parseYandexMusic(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Embed{
+		Provider: p.Name(),
+		URL:      post.url(),
+		ID:       post.ID,
+		OwnerID:  post.OwnerID,
+		Kind:     post.Kind,
+		Height:   post.Height,
+		Media:    "audio",
+		Raw:      htmlText,
+	}, nil
+}
+
+// post restores yandex music data from the embed
+func (yandexMusicProvider) post(embed *Embed) *yandexMusicPost {
+	return &yandexMusicPost{Kind: embed.Kind, ID: embed.ID, OwnerID: embed.OwnerID, Height: embed.Height}
+}
+
+func (p yandexMusicProvider) AMP(embed *Embed) ([]byte, error) { return p.post(embed).printAMP(), nil }
+
+func (p yandexMusicProvider) Turbo(embed *Embed) ([]byte, error) {
+	return p.post(embed).printTurbo(), nil
+}
+
+type applePodcastsProvider struct{}
+
+func (applePodcastsProvider) Name() string { return `applepodcasts` }
+
+func (applePodcastsProvider) Detect(htmlText []byte) bool {
+	return bytes.Contains(htmlText, []byte(`embed.podcasts.apple.com`))
+}
+
+func (p applePodcastsProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parseApplePodcasts(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	embed := &Embed{
+		Provider: p.Name(),
+		URL:      "https://podcasts.apple.com" + post.path(),
+		ID:       post.PodcastID,
+		Kind:     post.Kind,
+		Height:   post.Height,
+		Media:    "audio",
+		Raw:      htmlText,
+	}
+	if post.Kind == "episode" {
+		embed.ID, embed.OwnerID = post.EpisodeID, post.PodcastID
+	}
+
+	return embed, nil
+}
+
+// post restores apple podcasts data from the embed, its url keeps show and episode
+func (applePodcastsProvider) post(embed *Embed) (*applePodcastsPost, error) {
+	post := &applePodcastsPost{Height: embed.Height}
+	if err := post.parseSrc(embed.URL, "podcasts.apple.com"); err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+func (p applePodcastsProvider) AMP(embed *Embed) ([]byte, error) {
+	post, err := p.post(embed)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+func (p applePodcastsProvider) Turbo(embed *Embed) ([]byte, error) {
+	post, err := p.post(embed)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printTurbo(), nil
+}
+
+type threadsProvider struct{}
+
+func (threadsProvider) Name() string { return `threads` }
+
+func (threadsProvider) Detect(htmlText []byte) bool {
+	return bytes.Contains(htmlText, []byte(`data-text-post-permalink`))
+}
+
+func (p threadsProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parseThreads(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Embed{Provider: p.Name(), URL: post.url(), ID: post.Shortcode, OwnerID: post.User, Raw: htmlText}, nil
+}
+
+// post restores threads post data from the embed
+func (threadsProvider) post(embed *Embed) *threadsPost {
+	return &threadsPost{User: embed.OwnerID, Shortcode: embed.ID}
+}
+
+func (p threadsProvider) AMP(embed *Embed) ([]byte, error) { return p.post(embed).printAMP(), nil }
+
+func (p threadsProvider) Turbo(embed *Embed) ([]byte, error) { return p.post(embed).printTurbo(), nil }
+
+type mastodonProvider struct{}
+
+func (mastodonProvider) Name() string { return `mastodon` }
+
+func (mastodonProvider) Detect(htmlText []byte) bool {
+	return mastodonRe.Match(htmlText)
+}
+
+func (p mastodonProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parseMastodon(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Embed{
+		Provider: p.Name(),
+		URL:      post.url(),
+		ID:       post.StatusID,
+		OwnerID:  post.User,
+		Width:    post.Width,
+		Height:   post.Height,
+		Raw:      htmlText,
+	}, nil
+}
+
+// post restores mastodon status data from the embed, its url keeps instance
+func (mastodonProvider) post(embed *Embed) (*mastodonPost, error) {
+	post := &mastodonPost{Width: embed.Width, Height: embed.Height}
+	if err := post.parseSrc(embed.URL + "/embed"); err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+func (p mastodonProvider) AMP(embed *Embed) ([]byte, error) {
+	post, err := p.post(embed)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+func (p mastodonProvider) Turbo(embed *Embed) ([]byte, error) {
+	post, err := p.post(embed)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printTurbo(), nil
+}
+
+type blueskyProvider struct{}
+
+func (blueskyProvider) Name() string { return `bluesky` }
+
+func (blueskyProvider) Detect(htmlText []byte) bool {
+	return bytes.Contains(htmlText, []byte(`data-bluesky-uri`))
+}
+
+func (p blueskyProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parseBluesky(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Embed{Provider: p.Name(), URL: post.url(), ID: post.RKey, OwnerID: post.DID, Raw: htmlText}, nil
+}
+
+// post restores bluesky post data from the embed
+func (blueskyProvider) post(embed *Embed) *blueskyPost {
+	return &blueskyPost{DID: embed.OwnerID, RKey: embed.ID}
+}
+
+func (p blueskyProvider) AMP(embed *Embed) ([]byte, error) { return p.post(embed).printAMP(), nil }
+
+func (p blueskyProvider) Turbo(embed *Embed) ([]byte, error) { return p.post(embed).printTurbo(), nil }
+
+type okProvider struct{}
+
+func (okProvider) Name() string { return `odnoklassniki` }
+
+func (okProvider) Detect(htmlText []byte) bool {
+	return bytes.Contains(htmlText, []byte(`/videoembed/`)) || bytes.Contains(htmlText, []byte(`insertContentWidget`))
+}
+
+func (p okProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parseOk(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	embed := &Embed{
+		Provider: p.Name(),
+		URL:      post.url(),
+		ID:       strconv.FormatInt(post.ID, 10),
+		Kind:     post.Kind,
+		Width:    post.Width,
+		Height:   post.Height,
+		Raw:      htmlText,
+	}
+	if post.Kind == "video" {
+		embed.Video, embed.Media = true, "video"
+	} else {
+		embed.OwnerID = strconv.FormatInt(post.OwnerID, 10)
+	}
+
+	return embed, nil
+}
+
+// post restores odnoklassniki data from the embed
+func (okProvider) post(embed *Embed) (*okPost, error) {
+	post := &okPost{Kind: embed.Kind, Width: embed.Width, Height: embed.Height}
+
+	id, err := strconv.ParseInt(embed.ID, 10, 0)
+	if err != nil || id < 1 {
+		return nil, embedError(`odnoklassniki`, ErrMalformedEmbed, embed.ID)
+	}
+	post.ID = id
+
+	if post.Kind != "video" {
+		ownerID, err := strconv.ParseInt(embed.OwnerID, 10, 0)
+		if err != nil || ownerID < 1 {
+			return nil, embedError(`odnoklassniki`, ErrMalformedEmbed, embed.OwnerID)
+		}
+		post.OwnerID = ownerID
+	}
+
+	return post, nil
+}
+
+func (p okProvider) AMP(embed *Embed) ([]byte, error) {
+	post, err := p.post(embed)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+func (p okProvider) Turbo(embed *Embed) ([]byte, error) {
+	post, err := p.post(embed)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printTurbo(), nil
+}
+
+type datawrapperProvider struct{}
+
+func (datawrapperProvider) Name() string { return `datawrapper` }
+
+func (datawrapperProvider) Detect(htmlText []byte) bool {
+	return bytes.Contains(htmlText, []byte(`datawrapper.dwcdn.net`))
+}
+
+func (p datawrapperProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parseDatawrapper(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Embed{Provider: p.Name(), URL: post.src(), ID: post.ChartID, Height: post.Height, Raw: htmlText}, nil
+}
+
+// post restores datawrapper chart data from the embed, its url keeps version
+func (datawrapperProvider) post(embed *Embed) (*datawrapperPost, error) {
+	post := &datawrapperPost{Height: embed.Height}
+	if err := post.parseSrc(embed.URL); err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+func (p datawrapperProvider) AMP(embed *Embed) ([]byte, error) {
+	post, err := p.post(embed)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printAMP(), nil
+}
+
+func (p datawrapperProvider) Turbo(embed *Embed) ([]byte, error) {
+	post, err := p.post(embed)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printTurbo(), nil
+}
+
+type flourishProvider struct{}
+
+func (flourishProvider) Name() string { return `flourish` }
+
+func (flourishProvider) Detect(htmlText []byte) bool {
+	return bytes.Contains(htmlText, []byte(`flourish-embed`)) || bytes.Contains(htmlText, []byte(`flo.uri.sh`))
+}
+
+func (p flourishProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parseFlourish(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Embed{Provider: p.Name(), URL: post.url(), ID: post.ID, Kind: post.Kind, Height: post.Height, Raw: htmlText}, nil
+}
+
+// post restores flourish data from the embed
+func (flourishProvider) post(embed *Embed) *flourishPost {
+	return &flourishPost{Kind: embed.Kind, ID: embed.ID, Height: embed.Height}
+}
+
+func (p flourishProvider) AMP(embed *Embed) ([]byte, error) { return p.post(embed).printAMP(), nil }
+
+func (p flourishProvider) Turbo(embed *Embed) ([]byte, error) { return p.post(embed).printTurbo(), nil }
+
+type infogramProvider struct{}
+
+func (infogramProvider) Name() string { return `infogram` }
+
+func (infogramProvider) Detect(htmlText []byte) bool {
+	return bytes.Contains(htmlText, []byte(`infogram-embed`)) || bytes.Contains(htmlText, []byte(`e.infogram.com/`))
+}
+
+func (p infogramProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parseInfogram(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Embed{Provider: p.Name(), URL: post.url(), ID: post.ID, Height: post.Height, Raw: htmlText}, nil
+}
+
+// post restores infogram chart data from the embed
+func (infogramProvider) post(embed *Embed) *infogramPost {
+	return &infogramPost{ID: embed.ID, Height: embed.Height}
+}
+
+func (p infogramProvider) AMP(embed *Embed) ([]byte, error) { return p.post(embed).printAMP(), nil }
+
+func (p infogramProvider) Turbo(embed *Embed) ([]byte, error) { return p.post(embed).printTurbo(), nil }
+
+type iframeProvider struct{}
+
+func (iframeProvider) Name() string { return `iframe` }
+
+func (iframeProvider) Detect(htmlText []byte) bool {
+	return bytes.Contains(htmlText, []byte(`<iframe`))
+}
+
+func (p iframeProvider) Extract(htmlText []byte) (*Embed, error) {
+	post, err := parseIframe(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Embed{
+		Provider:        p.Name(),
+		URL:             post.Src,
+		Width:           post.Width,
+		Height:          post.Height,
+		Frameborder:     post.Frameborder,
+		AllowFullscreen: post.AllowFS,
+		Raw:             htmlText,
+	}, nil
+}
+
+// post restores iframe data from the embed
+func (iframeProvider) post(embed *Embed) (*iframePost, error) {
+	urlPtr, err := url.Parse(embed.URL)
+	if err != nil {
+		return nil, embedError(`iframe`, ErrMalformedURL, embed.URL)
+	}
+
+	return &iframePost{
+		Src:         embed.URL,
+		Scheme:      urlPtr.Scheme,
+		Width:       embed.Width,
+		Height:      embed.Height,
+		AllowFS:     embed.AllowFullscreen,
+		Frameborder: embed.Frameborder,
+	}, nil
+}
+
+func (p iframeProvider) AMP(embed *Embed) ([]byte, error) {
+	post, err := p.post(embed)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.Scheme != `https` {
+		return nil, embedError(`iframe`, ErrInsecureScheme, embed.URL)
+	}
+
+	return post.printAMP(), nil
+}
+
+func (p iframeProvider) Turbo(embed *Embed) ([]byte, error) {
+	post, err := p.post(embed)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.Scheme != `https` {
+		return nil, embedError(`iframe`, ErrInsecureScheme, embed.URL)
+	}
+
+	return post.printTurbo(), nil
+}
@@ -0,0 +1,70 @@
+package turboamper
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// DefaultComponentVersion is version of AMP components used by Scripts if no version given
+const DefaultComponentVersion = "0.1"
+
+// builtinElements are AMP elements which are included in the runtime and need no script
+var builtinElements = map[string]bool{
+	"amp-img":    true,
+	"amp-layout": true,
+	"amp-pixel":  true,
+}
+
+// CustomElements gives you sorted names of AMP custom elements used in given AMP html,
+// e.g. amp-vk or amp-youtube. Every one of them requires its script in the page head.
+func CustomElements(ampHTML []byte) []string {
+	found := make(map[string]bool)
+
+	z := html.NewTokenizer(bytes.NewReader(ampHTML))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		if tt != html.StartTagToken && tt != html.SelfClosingTagToken {
+			continue
+		}
+		name, _ := z.TagName()
+		element := string(name)
+		if strings.HasPrefix(element, "amp-") && !builtinElements[element] {
+			found[element] = true
+		}
+	}
+
+	elements := make([]string, 0, len(found))
+	for element := range found {
+		elements = append(elements, element)
+	}
+	sort.Strings(elements)
+
+	return elements
+}
+
+// Scripts gives you script tags of given AMP custom elements for the page head.
+// If version is empty, DefaultComponentVersion is used.
+func Scripts(elements []string, version string) []byte {
+	if version == "" {
+		version = DefaultComponentVersion
+	}
+
+	template := `<script async custom-element="%s" src="https://cdn.ampproject.org/v0/%s-%s.js"></script>`
+
+	var buf bytes.Buffer
+	for i, element := range elements {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		fmt.Fprintf(&buf, template, element, element, version)
+	}
+
+	return buf.Bytes()
+}
@@ -3,44 +3,18 @@ package turboamper
 import (
 	"bytes"
 	"fmt"
-	"net/url"
-	"regexp"
-	"strconv"
-	"strings"
-
-	"golang.org/x/net/html"
-	"golang.org/x/net/html/atom"
 )
 
 // Turbo gives you YandexTurbo-representation of html and its type
-// If it cannot recognize your html, it returns simple error.
+// If it cannot recognize your html, it returns ErrUnknownEmbed,
+// if it recognizes but cannot convert it, it returns *EmbedError of the provider.
 func Turbo(htmlText []byte) ([]byte, string, error) {
-	got, err := VkToTurbo(htmlText)
-	if err == nil {
-		return got, `vkontakte`, nil
-	}
-	got, err = FbToTurbo(htmlText)
-	if err == nil {
-		return got, `facebook`, nil
-	}
-	got, err = InstaToTurbo(htmlText)
-	if err == nil {
-		return got, `instagram`, nil
-	}
-	got, err = TwitToTurbo(htmlText)
-	if err == nil {
-		return got, `twitter`, nil
-	}
-	got, err = YoutubeToTurbo(htmlText)
-	if err == nil {
-		return got, `youtube`, nil
-	}
-	got, err = IframeToTurbo(htmlText)
-	if err == nil {
-		return got, `iframe`, nil
+	got, embed, err := represent(htmlText, Provider.Turbo)
+	if err != nil {
+		return nil, ``, err
 	}
 
-	return nil, ``, fmt.Errorf("unknown embed")
+	return got, embed.Provider, nil
 }
 
 // printTurbo returns ready to handle Turbo with given parameters
@@ -76,76 +50,204 @@ func (ypost *youtubePost) printTurbo() []byte {
 		attributes += ` allowfullscreen="true"`
 	}
 
-	template := `<iframe%s frameborder="%d" src="https://www.youtube.com/embed/%s"></iframe>`
+	template := `<iframe%s frameborder="%d" src="%s"></iframe>`
 
-	amp := fmt.Sprintf(template, attributes, ypost.Frameborder, ypost.VideoID)
+	amp := fmt.Sprintf(template, attributes, ypost.Frameborder, ypost.src())
 
 	return []byte(amp)
 }
 
-// VkToTurbo validates given vkontakte widget post for Yandex Turbo
-// What is that? Look https://vk.com/dev/widget_post
-func VkToTurbo(htmlText []byte) ([]byte, error) {
-	if !bytes.Contains(htmlText, []byte(`VK.Widgets.Post`)) {
-		return nil, fmt.Errorf("given string is not a VK widget post")
+// printTurbo returns ready to handle Turbo with given parameters
+func (post *tiktokPost) printTurbo() []byte {
+	if post.Width == 0 {
+		post.Width = 325
+	}
+	if post.Height == 0 {
+		post.Height = 575
 	}
+	template := `<iframe width="%d" height="%d" frameborder="0" src="https://www.tiktok.com/embed/v2/%s"></iframe>`
+
+	turbo := fmt.Sprintf(template, post.Width, post.Height, post.VideoID)
 
-	re := regexp.MustCompile(`VK.Widgets.Post\("vk_post_(-?\d+)_(-?\d+)", (-?\d+), (-?\d+), '(\S+?)'`)
-	widgetParsed := re.FindSubmatch(htmlText)
-	if widgetParsed == nil {
-		return nil, fmt.Errorf("cannot parse vk widget")
+	return []byte(turbo)
+}
+
+// printPostTurbo returns iframe of social network post with given src
+func printPostTurbo(src string, width, height int64) []byte {
+	var attributes string
+	if width > 0 {
+		attributes += fmt.Sprintf(` width="%d"`, width)
 	}
+	if height > 0 {
+		attributes += fmt.Sprintf(` height="%d"`, height)
+	}
+
+	template := `<iframe%s frameborder="0" src="%s"></iframe>`
+
+	turbo := fmt.Sprintf(template, attributes, src)
+
+	return []byte(turbo)
+}
+
+// printTurbo returns ready to handle Turbo with given parameters
+func (post *telegramPost) printTurbo() []byte {
+	return printPostTurbo(post.src(), post.Width, post.Height)
+}
 
-	// 1st and 3rd, 2nd and 4th should match
-	if string(widgetParsed[1]) != string(widgetParsed[3]) || string(widgetParsed[2]) != string(widgetParsed[4]) {
-		return nil, fmt.Errorf("parsed string does not match Vk widget post format")
+// printPlayerTurbo returns iframe of video player with given src
+func printPlayerTurbo(src string, width, height int64) []byte {
+	var attributes string
+	if width > 0 {
+		attributes += fmt.Sprintf(` width="%d"`, width)
+	}
+	if height > 0 {
+		attributes += fmt.Sprintf(` height="%d"`, height)
 	}
 
-	_, err := strconv.ParseInt(string(widgetParsed[1]), 10, 0)
-	if err != nil {
-		return nil, fmt.Errorf("cannot parse owner id")
+	template := `<iframe%s allowfullscreen="true" frameborder="0" src="%s"></iframe>`
+
+	turbo := fmt.Sprintf(template, attributes, src)
+
+	return []byte(turbo)
+}
+
+// printTurbo returns ready to handle Turbo with given parameters
+func (post *rutubePost) printTurbo() []byte {
+	return printPlayerTurbo(post.src(), post.Width, post.Height)
+}
+
+// printTurbo returns ready to handle Turbo with given parameters
+func (post *vkVideoPost) printTurbo() []byte {
+	return printPlayerTurbo(post.src(), post.Width, post.Height)
+}
+
+// printTurbo returns ready to handle Turbo with given parameters
+func (post *vimeoPost) printTurbo() []byte {
+	return printPlayerTurbo(post.src(), post.Width, post.Height)
+}
+
+// printTurbo returns ready to handle Turbo with given parameters
+func (post *dailymotionPost) printTurbo() []byte {
+	return printPlayerTurbo(post.src(), post.Width, post.Height)
+}
+
+// printTurbo returns ready to handle Turbo with given parameters.
+// Yandex Turbo cannot show pinterest widgets, so pins are shown by pinterest iframe
+// and boards and profiles are just links.
+func (post *pinterestPost) printTurbo() []byte {
+	if post.Kind != "embedPin" {
+		return []byte(fmt.Sprintf(`<a href="%s">%s</a>`, post.url(), post.url()))
 	}
 
-	_, err = strconv.ParseInt(string(widgetParsed[2]), 10, 0)
-	if err != nil {
-		return nil, fmt.Errorf("cannot parse post id")
+	if post.Width == 0 {
+		post.Width = 236
 	}
+	template := `<iframe width="%d" height="%d" frameborder="0" src="https://assets.pinterest.com/ext/embed.html?id=%s"></iframe>`
 
-	return htmlText, nil
+	turbo := fmt.Sprintf(template, post.Width, post.Width*326/236, post.PinID)
+
+	return []byte(turbo)
 }
 
-// TwitToTurbo convertes given twitter embeddable html for Yandex Turbo
-func TwitToTurbo(htmlText []byte) ([]byte, error) {
-	pointerNode, err := html.Parse(bytes.NewReader(htmlText))
-	if err != nil {
-		return nil, fmt.Errorf("cannot parse twitter html")
-	}
-	var post tweetPost
-
-	var f func(*html.Node)
-	f = func(n *html.Node) {
-		if n.DataAtom == atom.A {
-			for _, a := range n.Attr {
-				if a.Key == "href" {
-					re := regexp.MustCompile(`https://twitter.com/[a-zA-Z_]{1,15}/status/(\d+)`)
-					submatch := re.FindStringSubmatch(a.Val)
-					if submatch == nil {
-						continue
-					}
-					post.ID = submatch[1]
-					post.Src = a.Val
-					return
-				}
-			}
-		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			f(c)
+// printAudioTurbo returns iframe of audio player with given src
+func printAudioTurbo(src string, height int64) []byte {
+	template := `<iframe height="%d" frameborder="0" src="%s"></iframe>`
+
+	turbo := fmt.Sprintf(template, height, src)
+
+	return []byte(turbo)
+}
+
+// printTurbo returns ready to handle Turbo with given parameters
+func (post *soundcloudPost) printTurbo() []byte {
+	if post.Height == 0 {
+		post.Height = 166
+		if post.Visual {
+			post.Height = 300
 		}
 	}
-	f(pointerNode)
 
-	if len(post.Src) < 1 {
-		return nil, fmt.Errorf("no twitter ID in the url")
+	return printAudioTurbo(post.src(), post.Height)
+}
+
+// printTurbo returns ready to handle Turbo with given parameters
+func (post *yandexMusicPost) printTurbo() []byte {
+	return printAudioTurbo(post.src(), post.height())
+}
+
+// printTurbo returns ready to handle Turbo with given parameters
+func (post *applePodcastsPost) printTurbo() []byte {
+	return printAudioTurbo(post.src(), post.height())
+}
+
+// printTurbo returns ready to handle Turbo with given parameters
+func (post *threadsPost) printTurbo() []byte {
+	return printPostTurbo(post.url()+"/embed", 0, 0)
+}
+
+// printTurbo returns ready to handle Turbo with given parameters
+func (post *mastodonPost) printTurbo() []byte {
+	return printPostTurbo(post.url()+"/embed", post.Width, post.Height)
+}
+
+// printTurbo returns ready to handle Turbo with given parameters
+func (post *blueskyPost) printTurbo() []byte {
+	return printPostTurbo(post.src(), 0, 0)
+}
+
+// printTurbo returns ready to handle Turbo with given parameters.
+// Yandex Turbo cannot run odnoklassniki script, so topics are just links.
+func (post *okPost) printTurbo() []byte {
+	if post.Kind != "video" {
+		return []byte(fmt.Sprintf(`<a href="%s">%s</a>`, post.url(), post.url()))
+	}
+
+	return printPlayerTurbo(post.src(), post.Width, post.Height)
+}
+
+// printChartTurbo returns iframe of chart with given src
+func printChartTurbo(src string, height int64) []byte {
+	template := `<iframe height="%d" frameborder="0" scrolling="no" src="%s"></iframe>`
+
+	turbo := fmt.Sprintf(template, height, src)
+
+	return []byte(turbo)
+}
+
+// printTurbo returns ready to handle Turbo with given parameters
+func (post *datawrapperPost) printTurbo() []byte {
+	return printChartTurbo(post.src(), post.height())
+}
+
+// printTurbo returns ready to handle Turbo with given parameters
+func (post *flourishPost) printTurbo() []byte {
+	return printChartTurbo(post.src(), post.height())
+}
+
+// printTurbo returns ready to handle Turbo with given parameters
+func (post *infogramPost) printTurbo() []byte {
+	return printChartTurbo(post.src(), post.height())
+}
+
+// VkToTurbo validates given vkontakte widget post, group or playlist for Yandex Turbo
+// and convertes video player
+// What is that? Look https://vk.com/dev/widget_post
+func VkToTurbo(htmlText []byte) ([]byte, error) {
+	if !vkCallRe.Match(htmlText) && bytes.Contains(htmlText, []byte(`video_ext.php`)) {
+		return VkVideoToTurbo(htmlText)
+	}
+
+	if _, err := parseVk(htmlText); err != nil {
+		return nil, err
+	}
+
+	return htmlText, nil
+}
+
+// TwitToTurbo convertes given twitter embeddable html for Yandex Turbo
+func TwitToTurbo(htmlText []byte) ([]byte, error) {
+	if _, err := parseTwit(htmlText); err != nil {
+		return nil, err
 	}
 
 	return htmlText, nil
@@ -153,233 +255,220 @@ func TwitToTurbo(htmlText []byte) ([]byte, error) {
 
 // InstaToTurbo validates given instagram embeddable html for Yandex Turbo
 func InstaToTurbo(htmlText []byte) ([]byte, error) {
-	pointerNode, err := html.Parse(bytes.NewReader(htmlText))
+	if _, err := parseInsta(htmlText); err != nil {
+		return nil, err
+	}
+
+	return htmlText, nil
+}
+
+// FbToTurbo validates Facebook html for Yandex Turbo
+func FbToTurbo(htmlText []byte) ([]byte, error) {
+	if _, err := parseFb(htmlText); err != nil {
+		return nil, err
+	}
+
+	return htmlText, nil
+}
+
+// YoutubeToTurbo convertes Youtube embeddable html to Yandex Turbo
+func YoutubeToTurbo(htmlText []byte) ([]byte, error) {
+	post, err := parseYoutube(htmlText)
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse insta html")
-	}
-	var post instaPost
-
-	var f func(*html.Node)
-	f = func(n *html.Node) {
-		if n.DataAtom == atom.Blockquote {
-			for _, bq := range n.Attr {
-				switch bq.Key {
-				case "data-instgrm-permalink":
-					post.Src = bq.Val
-				case "width":
-					w, err := strconv.ParseInt(bq.Val, 10, 0)
-					if err == nil {
-						post.Width = w
-					}
-				case "height":
-					h, err := strconv.ParseInt(bq.Val, 10, 0)
-					if err == nil {
-						post.Height = h
-					}
-				}
-			}
-			if len(post.Src) > 0 {
-				return
-			}
-		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			f(c)
-		}
+		return nil, err
 	}
-	f(pointerNode)
 
-	if len(post.Src) < 1 {
-		return nil, fmt.Errorf("no src in the url")
+	return post.printTurbo(), nil
+}
+
+// IframeToTurbo convertes some custom iframe embeddable html to Yandex Turbo
+func IframeToTurbo(htmlText []byte) ([]byte, error) {
+	post, err := parseIframe(htmlText)
+	if err != nil {
+		return nil, err
 	}
 
-	urlPtr, err := url.Parse(post.Src)
+	if post.Scheme != `https` {
+		return nil, embedError(`iframe`, ErrInsecureScheme, post.Src)
+	}
+
+	return post.printTurbo(), nil
+}
+
+// TiktokToTurbo convertes given tiktok embeddable html to Yandex Turbo
+func TiktokToTurbo(htmlText []byte) ([]byte, error) {
+	post, err := parseTiktok(htmlText)
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse url")
+		return nil, err
 	}
 
-	if !strings.Contains(urlPtr.Hostname(), "instagram.com") {
-		return nil, fmt.Errorf("it is not instagram url")
+	return post.printTurbo(), nil
+}
+
+// TelegramToTurbo convertes given telegram widget post or iframe to Yandex Turbo
+func TelegramToTurbo(htmlText []byte) ([]byte, error) {
+	post, err := parseTelegram(htmlText)
+	if err != nil {
+		return nil, err
 	}
 
-	re := regexp.MustCompile(`p/(\S+?)/`)
-	submatch := re.FindStringSubmatch(urlPtr.Path)
-	if submatch == nil {
-		return nil, fmt.Errorf("instagram url is malformed")
+	return post.printTurbo(), nil
+}
+
+// RutubeToTurbo convertes given rutube embeddable html to Yandex Turbo
+func RutubeToTurbo(htmlText []byte) ([]byte, error) {
+	post, err := parseRutube(htmlText)
+	if err != nil {
+		return nil, err
 	}
 
-	return htmlText, nil
+	return post.printTurbo(), nil
 }
 
-// FbToTurbo validates Facebook html for Yandex Turbo
-func FbToTurbo(htmlText []byte) ([]byte, error) {
-	pointerNode, err := html.Parse(bytes.NewReader(htmlText))
+// VkVideoToTurbo convertes given vkontakte video player to Yandex Turbo
+func VkVideoToTurbo(htmlText []byte) ([]byte, error) {
+	post, err := parseVkVideo(htmlText)
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse fb iframe")
-	}
-	var post fbPost
-
-	var f func(*html.Node)
-	f = func(n *html.Node) {
-		if n.DataAtom == atom.Iframe {
-			for _, iframe := range n.Attr {
-				switch iframe.Key {
-				case "src":
-					post.Src = iframe.Val
-				case "width":
-					w, err := strconv.ParseInt(iframe.Val, 10, 0)
-					if err == nil {
-						post.Width = w
-					}
-				case "height":
-					h, err := strconv.ParseInt(iframe.Val, 10, 0)
-					if err == nil {
-						post.Height = h
-					}
-				}
-			}
-			if len(post.Src) > 0 {
-				return
-			}
-		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			f(c)
-		}
+		return nil, err
 	}
-	f(pointerNode)
 
-	if len(post.Src) < 1 {
-		return nil, fmt.Errorf("no src in the url")
+	return post.printTurbo(), nil
+}
+
+// VimeoToTurbo convertes given vimeo embeddable html to Yandex Turbo
+func VimeoToTurbo(htmlText []byte) ([]byte, error) {
+	post, err := parseVimeo(htmlText)
+	if err != nil {
+		return nil, err
 	}
 
-	urlPtr, err := url.Parse(post.Src)
+	return post.printTurbo(), nil
+}
+
+// DailymotionToTurbo convertes given dailymotion embeddable html to Yandex Turbo
+func DailymotionToTurbo(htmlText []byte) ([]byte, error) {
+	post, err := parseDailymotion(htmlText)
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse fb url")
+		return nil, err
 	}
 
-	if !strings.Contains(urlPtr.Hostname(), "facebook.com") {
-		return nil, fmt.Errorf("it is not facebook url")
+	return post.printTurbo(), nil
+}
+
+// PinterestToTurbo convertes given pinterest pin, board or profile widget to Yandex Turbo
+func PinterestToTurbo(htmlText []byte) ([]byte, error) {
+	post, err := parsePinterest(htmlText)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.printTurbo(), nil
+}
+
+// RedditToTurbo validates given reddit post or comment embeddable html for Yandex Turbo
+func RedditToTurbo(htmlText []byte) ([]byte, error) {
+	if _, err := parseReddit(htmlText); err != nil {
+		return nil, err
 	}
 
 	return htmlText, nil
 }
 
-// YoutubeToTurbo convertes Youtube embeddable html to Yandex Turbo
-func YoutubeToTurbo(htmlText []byte) ([]byte, error) {
-	pointerNode, err := html.Parse(bytes.NewReader(htmlText))
+// SoundcloudToTurbo convertes given soundcloud player to Yandex Turbo
+func SoundcloudToTurbo(htmlText []byte) ([]byte, error) {
+	post, err := parseSoundcloud(htmlText)
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse youtube iframe")
-	}
-	var post youtubePost
-
-	var f func(*html.Node)
-	f = func(n *html.Node) {
-		if n.DataAtom == atom.Iframe {
-			for _, iframe := range n.Attr {
-				switch iframe.Key {
-				case "src":
-					post.Src = iframe.Val
-				case "width":
-					w, err := strconv.ParseInt(iframe.Val, 10, 0)
-					if err == nil {
-						post.Width = w
-					}
-				case "height":
-					h, err := strconv.ParseInt(iframe.Val, 10, 0)
-					if err == nil {
-						post.Height = h
-					}
-				case "allowfullscreen":
-					post.AllowFS = true
-				}
-			}
-			if len(post.Src) > 0 {
-				return
-			}
-		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			f(c)
-		}
+		return nil, err
+	}
+
+	return post.printTurbo(), nil
+}
+
+// YandexMusicToTurbo convertes given yandex music player to Yandex Turbo
+func YandexMusicToTurbo(htmlText []byte) ([]byte, error) {
+	post, err := parseYandexMusic(htmlText)
+	if err != nil {
+		return nil, err
 	}
-	f(pointerNode)
 
-	if !(len(post.Src) > 0) {
-		return nil, fmt.Errorf("no src in the url")
+	return post.printTurbo(), nil
+}
+
+// ApplePodcastsToTurbo convertes given apple podcasts player to Yandex Turbo
+func ApplePodcastsToTurbo(htmlText []byte) ([]byte, error) {
+	post, err := parseApplePodcasts(htmlText)
+	if err != nil {
+		return nil, err
 	}
 
-	urlPtr, err := url.Parse(post.Src)
+	return post.printTurbo(), nil
+}
+
+// ThreadsToTurbo convertes given threads embeddable html to Yandex Turbo
+func ThreadsToTurbo(htmlText []byte) ([]byte, error) {
+	post, err := parseThreads(htmlText)
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse youtube url")
+		return nil, err
 	}
 
-	if !strings.Contains(urlPtr.Hostname(), "youtube.com") {
-		return nil, fmt.Errorf("it is not youtube url")
+	return post.printTurbo(), nil
+}
+
+// MastodonToTurbo convertes given mastodon status embed of any instance to Yandex Turbo
+func MastodonToTurbo(htmlText []byte) ([]byte, error) {
+	post, err := parseMastodon(htmlText)
+	if err != nil {
+		return nil, err
 	}
 
-	re := regexp.MustCompile(`embed/([A-Za-z0-9_-]{11})`)
-	submatch := re.FindStringSubmatch(urlPtr.Path)
-	if submatch == nil {
-		return nil, fmt.Errorf("youtube url is malformed")
+	return post.printTurbo(), nil
+}
+
+// BlueskyToTurbo convertes given bluesky embeddable html to Yandex Turbo
+func BlueskyToTurbo(htmlText []byte) ([]byte, error) {
+	post, err := parseBluesky(htmlText)
+	if err != nil {
+		return nil, err
 	}
-	post.VideoID = submatch[1]
 
 	return post.printTurbo(), nil
 }
 
-// IframeToTurbo convertes some custom iframe embeddable html to Yandex Turbo
-func IframeToTurbo(htmlText []byte) ([]byte, error) {
-	pointerNode, err := html.Parse(bytes.NewReader(htmlText))
+// OkToTurbo convertes given odnoklassniki video iframe or content widget to Yandex Turbo
+func OkToTurbo(htmlText []byte) ([]byte, error) {
+	post, err := parseOk(htmlText)
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse iframe")
-	}
-	var post iframePost
-
-	var f func(*html.Node)
-	f = func(n *html.Node) {
-		if n.DataAtom == atom.Iframe {
-			for _, iframe := range n.Attr {
-				switch iframe.Key {
-				case "src":
-					post.Src = iframe.Val
-				case "width":
-					w, err := strconv.ParseInt(iframe.Val, 10, 0)
-					if err == nil {
-						post.Width = w
-					}
-				case "height":
-					h, err := strconv.ParseInt(iframe.Val, 10, 0)
-					if err == nil {
-						post.Height = h
-					}
-				case "allowfullscreen":
-					post.AllowFS = true
-				case "frameborder":
-					fb, err := strconv.ParseInt(iframe.Val, 10, 0)
-					if err == nil {
-						post.Frameborder = fb
-					}
-				}
-			}
-			if len(post.Src) > 0 {
-				return
-			}
-		}
-		for c := n.FirstChild; c != nil; c = c.NextSibling {
-			f(c)
-		}
+		return nil, err
 	}
-	f(pointerNode)
 
-	if len(post.Src) < 1 {
-		return nil, fmt.Errorf("no src in the url")
+	return post.printTurbo(), nil
+}
+
+// DatawrapperToTurbo convertes given datawrapper chart to Yandex Turbo
+func DatawrapperToTurbo(htmlText []byte) ([]byte, error) {
+	post, err := parseDatawrapper(htmlText)
+	if err != nil {
+		return nil, err
 	}
 
-	urlPtr, err := url.Parse(post.Src)
+	return post.printTurbo(), nil
+}
+
+// FlourishToTurbo convertes given flourish embed to Yandex Turbo
+func FlourishToTurbo(htmlText []byte) ([]byte, error) {
+	post, err := parseFlourish(htmlText)
 	if err != nil {
-		return nil, fmt.Errorf("cannot parse iframe url")
+		return nil, err
 	}
 
-	if urlPtr.Scheme != `https` {
-		return nil, fmt.Errorf("yandex Turbo supports only https iframe scheme")
+	return post.printTurbo(), nil
+}
+
+// InfogramToTurbo convertes given infogram embed to Yandex Turbo
+func InfogramToTurbo(htmlText []byte) ([]byte, error) {
+	post, err := parseInfogram(htmlText)
+	if err != nil {
+		return nil, err
 	}
 
 	return post.printTurbo(), nil
@@ -1,8 +1,13 @@
 package turboamper
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestTurbo(t *testing.T) {
@@ -53,6 +58,62 @@ func TestTurbo(t *testing.T) {
 	</script>`,
 			`vkontakte`,
 		},
+		{
+			`<blockquote class="tiktok-embed" cite="https://www.tiktok.com/@rgru_official/video/7021366529212419330" data-video-id="7021366529212419330" style="max-width: 605px;min-width: 325px;" > <section> <a target="_blank" title="@rgru_official" href="https://www.tiktok.com/@rgru_official">@rgru_official</a> <a target="_blank" title="♬ original sound - rgru_official" href="https://www.tiktok.com/music/original-sound-7021366535231294210">♬ original sound - rgru_official</a> </section> </blockquote> <script async src="https://www.tiktok.com/embed.js"></script>`,
+			`<iframe width="325" height="575" frameborder="0" src="https://www.tiktok.com/embed/v2/7021366529212419330"></iframe>`,
+			`tiktok`,
+		},
+		{
+			`<script async src="https://telegram.org/js/telegram-widget.js?22" data-telegram-post="rgrunews/12345" data-width="100%"></script>`,
+			`<iframe frameborder="0" src="https://t.me/rgrunews/12345?embed=1"></iframe>`,
+			`telegram`,
+		},
+		{
+			`<iframe width="720" height="405" src="https://rutube.ru/play/embed/a10e53b86e8f349080f718d3f00d4f4a" frameBorder="0" allow="clipboard-write; autoplay" webkitAllowFullScreen mozallowfullscreen allowFullScreen></iframe>`,
+			`<iframe width="720" height="405" allowfullscreen="true" frameborder="0" src="https://rutube.ru/play/embed/a10e53b86e8f349080f718d3f00d4f4a"></iframe>`,
+			`rutube`,
+		},
+		{
+			`<iframe src="https://vk.com/video_ext.php?oid=-22822305&id=456242110&hash=e037414127166efe&hd=2" width="853" height="480" allow="autoplay; encrypted-media; fullscreen; picture-in-picture;" frameborder="0" allowfullscreen></iframe>`,
+			`<iframe width="853" height="480" allowfullscreen="true" frameborder="0" src="https://vk.com/video_ext.php?oid=-22822305&id=456242110&hash=e037414127166efe"></iframe>`,
+			`vkvideo`,
+		},
+		{
+			`<iframe src="https://player.vimeo.com/video/76979871?autoplay=1&muted=1" width="640" height="360" frameborder="0" allow="autoplay; fullscreen; picture-in-picture" allowfullscreen></iframe>`,
+			`<iframe width="640" height="360" allowfullscreen="true" frameborder="0" src="https://player.vimeo.com/video/76979871?autoplay=1&muted=1"></iframe>`,
+			`vimeo`,
+		},
+		{
+			`<iframe frameborder="0" width="480" height="270" src="https://www.dailymotion.com/embed/video/x7tgad0?autoplay=1&mute=1&queue-enable=false" allowfullscreen allow="autoplay"></iframe>`,
+			`<iframe width="480" height="270" allowfullscreen="true" frameborder="0" src="https://www.dailymotion.com/embed/video/x7tgad0?autoplay=1&mute=1&queue-enable=false"></iframe>`,
+			`dailymotion`,
+		},
+		{
+			`<a data-pin-do="embedPin" data-pin-width="medium" href="https://www.pinterest.com/pin/99360735500167749/"></a>
+<script async defer src="//assets.pinterest.com/js/pinit.js"></script>`,
+			`<iframe width="345" height="476" frameborder="0" src="https://assets.pinterest.com/ext/embed.html?id=99360735500167749"></iframe>`,
+			`pinterest`,
+		},
+		{
+			`<blockquote class="reddit-embed-bq" data-embed-height="316"><a href="https://www.reddit.com/r/aww/comments/15l2uzq/my_cat_when_he_hears_the_treat_bag/">My cat when he hears the treat bag</a><br> by<a href="https://www.reddit.com/user/catlover/">u/catlover</a> in<a href="https://www.reddit.com/r/aww/">aww</a></blockquote><script async="" src="https://embed.reddit.com/widgets.js" charset="UTF-8"></script>`,
+			`<blockquote class="reddit-embed-bq" data-embed-height="316"><a href="https://www.reddit.com/r/aww/comments/15l2uzq/my_cat_when_he_hears_the_treat_bag/">My cat when he hears the treat bag</a><br> by<a href="https://www.reddit.com/user/catlover/">u/catlover</a> in<a href="https://www.reddit.com/r/aww/">aww</a></blockquote><script async="" src="https://embed.reddit.com/widgets.js" charset="UTF-8"></script>`,
+			`reddit`,
+		},
+		{
+			`<iframe width="100%" height="166" scrolling="no" frameborder="no" allow="autoplay" src="https://w.soundcloud.com/player/?url=https%3A//api.soundcloud.com/tracks/293&color=%23ff5500&auto_play=false&hide_related=false&show_comments=true&show_user=true&show_reposts=false&show_teaser=true"></iframe>`,
+			`<iframe height="166" frameborder="0" src="https://w.soundcloud.com/player/?color=%23ff5500&url=https%3A%2F%2Fapi.soundcloud.com%2Ftracks%2F293"></iframe>`,
+			`soundcloud`,
+		},
+		{
+			`<iframe frameborder="0" style="border:none;width:100%;height:180px;" width="100%" height="180" src="https://music.yandex.ru/iframe/#track/17325218/2007004">Слушайте <a href='https://music.yandex.ru/album/2007004/track/17325218'>Группа крови</a> — <a href='https://music.yandex.ru/artist/161373'>Кино</a> на Яндекс Музыке</iframe>`,
+			`<iframe height="180" frameborder="0" src="https://music.yandex.ru/iframe/#track/17325218/2007004"></iframe>`,
+			`yandexmusic`,
+		},
+		{
+			`<iframe allow="autoplay *; encrypted-media *; fullscreen *; clipboard-write" frameborder="0" height="175" style="width:100%;max-width:660px;overflow:hidden;border-radius:10px;" sandbox="allow-forms allow-popups allow-same-origin allow-scripts allow-storage-access-by-user-activation allow-top-navigation-by-user-activation" src="https://embed.podcasts.apple.com/us/podcast/the-daily/id1200361736?i=1000650123456"></iframe>`,
+			`<iframe height="175" frameborder="0" src="https://embed.podcasts.apple.com/us/podcast/the-daily/id1200361736?i=1000650123456"></iframe>`,
+			`applepodcasts`,
+		},
 		{
 			// error
 			`<div id="vk_post_-175249128_1156"></div>
@@ -62,13 +123,13 @@ func TestTurbo(t *testing.T) {
 		VK.Widgets.Post("vk_post_175249128_1156", -175249128, 1156, 'HmCFKRSM81NEzJ8mY9gzgXOlEFM');
 	}());
 	</script>`,
-			`unknown embed`,
+			`vkontakte: malformed embed: VK.Widgets.Post("vk_post_175249128_1156", -175249128, 1156, 'HmCFKRSM81NEzJ8mY9gzgXOlEFM'`,
 			``,
 		},
 		{
 			// error
 			`<blockquote class="twitter-tweet"><p lang="ru" dir="ltr">Андрей Сошенко. Когда рванет второй Чернобыль? <br>Рано или поздно, но на Украине обязательно сотворят глобальную катастрофу <a href="https://t.co/EQGPtpvxVF">https://t.co/EQGPtpvxVF</a> <a href="https://t.co/WBIrRCAvZq">pic.twitter.com/WBIrRCAvZq</a></p>&mdash; газета Завтра (@ZavtraRu) <a href="https://twitter.com/ZavtraRu/status/?ref_src=twsrc%5Etfw">January 9, 2020</a></blockquote> <script async src="https://platform.twitter.com/widgets.js" charset="utf-8"></script>`,
-			`unknown embed`,
+			`twitter: no source of embed`,
 			``,
 		},
 	}
@@ -103,29 +164,80 @@ func TestAMP(t *testing.T) {
 	}{
 		{
 			`<div style="position: relative;padding-bottom: 56.25%; padding-top: 25px; height: 0;"><iframe style="position: absolute;top: 0;left: 0;width: 100%;height: 100%;" src="https://russian.rt.com/nopolitics/video/706313-popokatepetl-stolb-pepel-3-km/video/5e1852ef02e8bd3b731db837" frameborder="0" allowfullscreen/></iframe></div>`,
-			`<amp-iframe width="480" height="315" sandbox="allow-scripts allow-same-origin" layout="responsive" frameborder="0" allowfullscreen src="https://russian.rt.com/nopolitics/video/706313-popokatepetl-stolb-pepel-3-km/video/5e1852ef02e8bd3b731db837"`,
+			`<amp-iframe width="480" height="315" sandbox="allow-scripts allow-same-origin" layout="responsive" frameborder="0" allowfullscreen src="https://russian.rt.com/nopolitics/video/706313-popokatepetl-stolb-pepel-3-km/video/5e1852ef02e8bd3b731db837"></amp-iframe>`,
 			`iframe`,
 		},
 		{
 			`<iframe width="560" height="315" src="https://www.youtube.com/embed/05klG-PTKqo" frameborder="0" allow="accelerometer; autoplay; encrypted-media; gyroscope; picture-in-picture" allowfullscreen></iframe>`,
-			`<amp-youtube layout="responsive" width="560" height="315" data-videoid="05klG-PTKqo"></amp-youtube>`,
+			`<amp-youtube layout="responsive" height="315" width="560" data-videoid="05klG-PTKqo"></amp-youtube>`,
 			`youtube`,
 		},
 		{
 			`<blockquote class="instagram-media" data-instgrm-permalink="https://www.instagram.com/p/B6nHZAHl7JZ/?utm_source=ig_embed&amp;utm_campaign=loading" data-instgrm-version="12" style=" background:#FFF; border:0; border-radius:3px; box-shadow:0 0 1px 0 rgba(0,0,0,0.5),0 1px 10px 0 rgba(0,0,0,0.15); margin: 1px; max-width:540px; min-width:326px; padding:0; width:99.375%; width:-webkit-calc(100% - 2px); width:calc(100% - 2px);"><div style="padding:16px;"> <a href="https://www.instagram.com/p/B6nHZAHl7JZ/?utm_source=ig_embed&amp;utm_campaign=loading" style=" background:#FFFFFF; line-height:0; padding:0 0; text-align:center; text-decoration:none; width:100%;" target="_blank"> <div style=" display: flex; flex-direction: row; align-items: center;"> <div style="background-color: #F4F4F4; border-radius: 50%; flex-grow: 0; height: 40px; margin-right: 14px; width: 40px;"></div> <div style="display: flex; flex-direction: column; flex-grow: 1; justify-content: center;"> <div style=" background-color: #F4F4F4; border-radius: 4px; flex-grow: 0; height: 14px; margin-bottom: 6px; width: 100px;"></div> <div style=" background-color: #F4F4F4; border-radius: 4px; flex-grow: 0; height: 14px; width: 60px;"></div></div></div><div style="padding: 19% 0;"></div> <div style="display:block; height:50px; margin:0 auto 12px; width:50px;"><svg width="50px" height="50px" viewBox="0 0 60 60" version="1.1" xmlns="https://www.w3.org/2000/svg" xmlns:xlink="https://www.w3.org/1999/xlink"><g stroke="none" stroke-width="1" fill="none" fill-rule="evenodd"><g transform="translate(-511.000000, -20.000000)" fill="#000000"><g><path d="M556.869,30.41 C554.814,30.41 553.148,32.076 553.148,34.131 C553.148,36.186 554.814,37.852 556.869,37.852 C558.924,37.852 560.59,36.186 560.59,34.131 C560.59,32.076 558.924,30.41 556.869,30.41 M541,60.657 C535.114,60.657 530.342,55.887 530.342,50 C530.342,44.114 535.114,39.342 541,39.342 C546.887,39.342 551.658,44.114 551.658,50 C551.658,55.887 546.887,60.657 541,60.657 M541,33.886 C532.1,33.886 524.886,41.1 524.886,50 C524.886,58.899 532.1,66.113 541,66.113 C549.9,66.113 557.115,58.899 557.115,50 C557.115,41.1 549.9,33.886 541,33.886 M565.378,62.101 C565.244,65.022 564.756,66.606 564.346,67.663 C563.803,69.06 563.154,70.057 562.106,71.106 C561.058,72.155 560.06,72.803 558.662,73.347 C557.607,73.757 556.021,74.244 553.102,74.378 C549.944,74.521 548.997,74.552 541,74.552 C533.003,74.552 532.056,74.521 528.898,74.378 C525.979,74.244 524.393,73.757 523.338,73.347 C521.94,72.803 520.942,72.155 519.894,71.106 C518.846,70.057 518.197,69.06 517.654,67.663 C517.244,66.606 516.755,65.022 516.623,62.101 C516.479,58.943 516.448,57.996 516.448,50 C516.448,42.003 516.479,41.056 516.623,37.899 C516.755,34.978 517.244,33.391 517.654,32.338 C518.197,30.938 518.846,29.942 519.894,28.894 C520.942,27.846 521.94,27.196 523.338,26.654 C524.393,26.244 525.979,25.756 528.898,25.623 C532.057,25.479 533.004,25.448 541,25.448 C548.997,25.448 549.943,25.479 553.102,25.623 C556.021,25.756 557.607,26.244 558.662,26.654 C560.06,27.196 561.058,27.846 562.106,28.894 C563.154,29.942 563.803,30.938 564.346,32.338 C564.756,33.391 565.244,34.978 565.378,37.899 C565.522,41.056 565.552,42.003 565.552,50 C565.552,57.996 565.522,58.943 565.378,62.101 M570.82,37.631 C570.674,34.438 570.167,32.258 569.425,30.349 C568.659,28.377 567.633,26.702 565.965,25.035 C564.297,23.368 562.623,22.342 560.652,21.575 C558.743,20.834 556.562,20.326 553.369,20.18 C550.169,20.033 549.148,20 541,20 C532.853,20 531.831,20.033 528.631,20.18 C525.438,20.326 523.257,20.834 521.349,21.575 C519.376,22.342 517.703,23.368 516.035,25.035 C514.368,26.702 513.342,28.377 512.574,30.349 C511.834,32.258 511.326,34.438 511.181,37.631 C511.035,40.831 511,41.851 511,50 C511,58.147 511.035,59.17 511.181,62.369 C511.326,65.562 511.834,67.743 512.574,69.651 C513.342,71.625 514.368,73.296 516.035,74.965 C517.703,76.634 519.376,77.658 521.349,78.425 C523.257,79.167 525.438,79.673 528.631,79.82 C531.831,79.965 532.853,80.001 541,80.001 C549.148,80.001 550.169,79.965 553.369,79.82 C556.562,79.673 558.743,79.167 560.652,78.425 C562.623,77.658 564.297,76.634 565.965,74.965 C567.633,73.296 568.659,71.625 569.425,69.651 C570.167,67.743 570.674,65.562 570.82,62.369 C570.966,59.17 571,58.147 571,50 C571,41.851 570.966,40.831 570.82,37.631"></path></g></g></g></svg></div><div style="padding-top: 8px;"> <div style=" color:#3897f0; font-family:Arial,sans-serif; font-size:14px; font-style:normal; font-weight:550; line-height:18px;"> View this post on Instagram</div></div><div style="padding: 12.5% 0;"></div> <div style="display: flex; flex-direction: row; margin-bottom: 14px; align-items: center;"><div> <div style="background-color: #F4F4F4; border-radius: 50%; height: 12.5px; width: 12.5px; transform: translateX(0px) translateY(7px);"></div> <div style="background-color: #F4F4F4; height: 12.5px; transform: rotate(-45deg) translateX(3px) translateY(1px); width: 12.5px; flex-grow: 0; margin-right: 14px; margin-left: 2px;"></div> <div style="background-color: #F4F4F4; border-radius: 50%; height: 12.5px; width: 12.5px; transform: translateX(9px) translateY(-18px);"></div></div><div style="margin-left: 8px;"> <div style=" background-color: #F4F4F4; border-radius: 50%; flex-grow: 0; height: 20px; width: 20px;"></div> <div style=" width: 0; height: 0; border-top: 2px solid transparent; border-left: 6px solid #f4f4f4; border-bottom: 2px solid transparent; transform: translateX(16px) translateY(-4px) rotate(30deg)"></div></div><div style="margin-left: auto;"> <div style=" width: 0px; border-top: 8px solid #F4F4F4; border-right: 8px solid transparent; transform: translateY(16px);"></div> <div style=" background-color: #F4F4F4; flex-grow: 0; height: 12px; width: 16px; transform: translateY(-4px);"></div> <div style=" width: 0; height: 0; border-top: 8px solid #F4F4F4; border-left: 8px solid transparent; transform: translateY(-4px) translateX(8px);"></div></div></div></a> <p style=" margin:8px 0 0 0; padding:0 4px;"> <a href="https://www.instagram.com/p/B6nHZAHl7JZ/?utm_source=ig_embed&amp;utm_campaign=loading" style=" color:#000; font-family:Arial,sans-serif; font-size:14px; font-style:normal; font-weight:normal; line-height:17px; text-decoration:none; word-wrap:break-word;" target="_blank">Председатель общероссийской общественной организации защиты семьи «Родительское Всероссийское Сопротивление» (РВС) Мария Мамиконян написала открытое письмо депутату Государственной думы @opushkina Оксане Пушкиной. ⠀ На пресс-конференции, посвященной законопроекту о профилактике семейно-бытового насилия (СБН), Пушкина заявила, что ей придется «оправдываться» в Страсбурге за непринятие закона. В письме, опубликованном в ИА «Регнум», председатель РВС напомнила, что Пушкина занимает пост спецпредставителя Госдумы во Всемирном банке по женскому предпринимательству. ⠀ Мария Мамиконян порекомендовала Пушкиной рассказать о мерах, которые уже применяются в России для профилактики насилия, в том числе семейного. Председатель РВС напомнила о положительной практике перевода «побоев», которые причинены впервые (за совершенные повторно в РФ предусмотрено уголовное наказание - прим. РВС), из разряда уголовных преступлений в административные нарушения. ⠀ Эта мера обеспечила неотвратимость наказания и снизила латентность этого нарушения. «Так что этой мерой Вам можно отчитываться как достижением, а не требовать её отмены и возврата всех побоев в УК!» — считает председатель РВС. ⠀ Она также посоветовала Пушкиной не оправдываться, а обратить внимание на то, что уровень насилия в России явно и сильно снижается. «То есть для самой постановки вопроса о чрезвычайных мерах в России нет почвы», — говорится в письме. ⠀ Мамиконян порекомендовала Пушкиной, как члену ПАСЕ, предложить коллегам за рубежом изучить передовой и эффективный российский опыт. Она отметила, что нормы, предлагаемые в скандальном законе о СБН, дискриминационны, коррупциогенны, несовместимы с презумпцией невиновности и попросту не имеют доказанную эффективность. ⠀ «И коль скоро вас так тяготит необходимость „оправдываться“ в Страсбурге за суверенные решения Российской Федерации, то, быть может, вам стоит освободиться от этих обременительных обязательств? Быть может, Россию в ПАСЕ лучше представлять людям, которые не будут оправдываться, но станут защищать интересы нашей страны на международной арене, а не наоборот?»— заключает Мария Мамиконян. ⠀ #СемейноБытовоеНасилие #ДомашнееНасилие #ОксанаПушкина #ЯНеХотелаУмирать</a></p> <p style=" color:#c9c8cd; font-family:Arial,sans-serif; font-size:14px; line-height:17px; margin-bottom:0; margin-top:8px; overflow:hidden; padding:8px 0 7px; text-align:center; text-overflow:ellipsis; white-space:nowrap;">A post shared by <a href="https://www.instagram.com/rvs.news/?utm_source=ig_embed&amp;utm_campaign=loading" style=" color:#c9c8cd; font-family:Arial,sans-serif; font-size:14px; font-style:normal; font-weight:normal; line-height:17px;" target="_blank"> РВС - защита семьи 👨‍👩‍👧‍👦</a> (@rvs.news) on <time style=" font-family:Arial,sans-serif; font-size:14px; line-height:17px;" datetime="2019-12-28T09:32:04+00:00">Dec 28, 2019 at 1:32am PST</time></p></div></blockquote> <script async src="//www.instagram.com/embed.js"></script>`,
-			`<amp-instagram layout="responsive" data-shortcode="B6nHZAHl7JZ"></amp-instagram>`,
+			`<amp-instagram layout="responsive" height="400" width="400" data-shortcode="B6nHZAHl7JZ"></amp-instagram>`,
 			`instagram`,
 		},
 		{
 			`<iframe src="https://www.facebook.com/plugins/post.php?href=https%3A%2F%2Fwww.facebook.com%2Fstcnk%2Fposts%2F3384458724928901&width=500" width="500" height="498" style="border:none;overflow:hidden" scrolling="no" frameborder="0" allowTransparency="true" allow="encrypted-media"></iframe>`,
-			`<amp-facebook layout="responsive" width="500" height="498" data-href="https://www.facebook.com/stcnk/posts/3384458724928901"></amp-facebook>`,
+			`<amp-facebook height="498" width="500" layout="responsive" data-href="https://www.facebook.com/stcnk/posts/3384458724928901"></amp-facebook>`,
 			`facebook`,
 		},
 		{
 			`<blockquote class="twitter-tweet"><p lang="en" dir="ltr"><a href="https://twitter.com/hashtag/TodayInHistory?src=hash&amp;ref_src=twsrc%5Etfw">#TodayInHistory</a> | 1999 – Prime minister Vladimir Putin becomes acting president <a href="https://t.co/pHbyk12C0s">pic.twitter.com/pHbyk12C0s</a></p>&mdash; WION (@WIONews) <a href="https://twitter.com/WIONews/status/1211912897590202368?ref_src=twsrc%5Etfw">December 31, 2019</a></blockquote> <script async src="https://platform.twitter.com/widgets.js" charset="utf-8"></script>`,
-			`<amp-twitter layout="responsive" data-tweetid="1211912897590202368"></amp-twitter>`,
+			`<amp-twitter layout="responsive" height="480" width="380" data-tweetid="1211912897590202368"></amp-twitter>`,
 			`twitter`,
 		},
+		{
+			`<script async src="https://telegram.org/js/telegram-widget.js?22" data-telegram-post="rgrunews/12345" data-width="100%"></script>`,
+			`<amp-iframe width="480" height="400" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" src="https://t.me/rgrunews/12345?embed=1"></amp-iframe>`,
+			`telegram`,
+		},
+		{
+			`<iframe width="720" height="405" src="https://rutube.ru/play/embed/a10e53b86e8f349080f718d3f00d4f4a" frameBorder="0" allow="clipboard-write; autoplay" webkitAllowFullScreen mozallowfullscreen allowFullScreen></iframe>`,
+			`<amp-iframe width="720" height="405" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" allow="autoplay; encrypted-media; fullscreen; picture-in-picture" allowfullscreen src="https://rutube.ru/play/embed/a10e53b86e8f349080f718d3f00d4f4a"></amp-iframe>`,
+			`rutube`,
+		},
+		{
+			`<iframe src="https://vk.com/video_ext.php?oid=-22822305&id=456242110&hash=e037414127166efe&hd=2" width="853" height="480" allow="autoplay; encrypted-media; fullscreen; picture-in-picture;" frameborder="0" allowfullscreen></iframe>`,
+			`<amp-iframe width="853" height="480" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" allow="autoplay; encrypted-media; fullscreen; picture-in-picture" allowfullscreen src="https://vk.com/video_ext.php?oid=-22822305&id=456242110&hash=e037414127166efe"></amp-iframe>`,
+			`vkvideo`,
+		},
+		{
+			`<iframe src="https://player.vimeo.com/video/76979871?autoplay=1&muted=1" width="640" height="360" frameborder="0" allow="autoplay; fullscreen; picture-in-picture" allowfullscreen></iframe>`,
+			`<amp-vimeo layout="responsive" height="360" width="640" autoplay data-videoid="76979871"></amp-vimeo>`,
+			`vimeo`,
+		},
+		{
+			`<iframe frameborder="0" width="480" height="270" src="https://www.dailymotion.com/embed/video/x7tgad0?autoplay=1&mute=1&queue-enable=false" allowfullscreen allow="autoplay"></iframe>`,
+			`<amp-dailymotion layout="responsive" height="270" width="480" autoplay data-mute="1" data-param-queue-enable="false" data-videoid="x7tgad0"></amp-dailymotion>`,
+			`dailymotion`,
+		},
+		{
+			`<a data-pin-do="embedPin" data-pin-width="medium" href="https://www.pinterest.com/pin/99360735500167749/"></a>
+<script async defer src="//assets.pinterest.com/js/pinit.js"></script>`,
+			`<amp-pinterest height="476" width="345" data-width="medium" data-do="embedPin" data-url="https://www.pinterest.com/pin/99360735500167749/"></amp-pinterest>`,
+			`pinterest`,
+		},
+		{
+			`<blockquote class="reddit-embed-bq" data-embed-height="316"><a href="https://www.reddit.com/r/aww/comments/15l2uzq/my_cat_when_he_hears_the_treat_bag/">My cat when he hears the treat bag</a><br> by<a href="https://www.reddit.com/user/catlover/">u/catlover</a> in<a href="https://www.reddit.com/r/aww/">aww</a></blockquote><script async="" src="https://embed.reddit.com/widgets.js" charset="UTF-8"></script>`,
+			`<amp-reddit layout="responsive" height="316" width="300" data-embedtype="post" data-src="https://www.reddit.com/r/aww/comments/15l2uzq/my_cat_when_he_hears_the_treat_bag/"></amp-reddit>`,
+			`reddit`,
+		},
+		{
+			`<iframe width="100%" height="166" scrolling="no" frameborder="no" allow="autoplay" src="https://w.soundcloud.com/player/?url=https%3A//api.soundcloud.com/tracks/293&color=%23ff5500&auto_play=false&hide_related=false&show_comments=true&show_user=true&show_reposts=false&show_teaser=true"></iframe>`,
+			`<amp-soundcloud height="166" layout="fixed-height" data-trackid="293" data-color="ff5500"></amp-soundcloud>`,
+			`soundcloud`,
+		},
+		{
+			`<iframe frameborder="0" style="border:none;width:100%;height:180px;" width="100%" height="180" src="https://music.yandex.ru/iframe/#track/17325218/2007004">Слушайте <a href='https://music.yandex.ru/album/2007004/track/17325218'>Группа крови</a> — <a href='https://music.yandex.ru/artist/161373'>Кино</a> на Яндекс Музыке</iframe>`,
+			`<amp-iframe height="180" layout="fixed-height" sandbox="allow-scripts allow-same-origin allow-popups" frameborder="0" allow="autoplay" src="https://music.yandex.ru/iframe/#track/17325218/2007004"></amp-iframe>`,
+			`yandexmusic`,
+		},
+		{
+			`<iframe allow="autoplay *; encrypted-media *; fullscreen *; clipboard-write" frameborder="0" height="175" style="width:100%;max-width:660px;overflow:hidden;border-radius:10px;" sandbox="allow-forms allow-popups allow-same-origin allow-scripts allow-storage-access-by-user-activation allow-top-navigation-by-user-activation" src="https://embed.podcasts.apple.com/us/podcast/the-daily/id1200361736?i=1000650123456"></iframe>`,
+			`<amp-iframe height="175" layout="fixed-height" sandbox="allow-scripts allow-same-origin allow-popups" frameborder="0" allow="autoplay" src="https://embed.podcasts.apple.com/us/podcast/the-daily/id1200361736?i=1000650123456"></amp-iframe>`,
+			`applepodcasts`,
+		},
 		{
 			// error
 			`<div id="vk_post_-175249128_1156"></div>
@@ -135,7 +247,7 @@ func TestAMP(t *testing.T) {
 		VK.Widgets.Post("vk_post_175249128_1156", -175249128, 1156, 'HmCFKRSM81NEzJ8mY9gzgXOlEFM');
 	}());
 	</script>`,
-			`unknown embed`,
+			`vkontakte: malformed embed: VK.Widgets.Post("vk_post_175249128_1156", -175249128, 1156, 'HmCFKRSM81NEzJ8mY9gzgXOlEFM'`,
 			``,
 		},
 		{
@@ -146,13 +258,18 @@ func TestAMP(t *testing.T) {
 		VK.Widgets.Post("vk_post_-175249128_1156", -175249128, 1156, 'HmCFKRSM81NEzJ8mY9gzgXOlEFM');
 	}());
 	</script>`,
-			`<amp-vk data-embedtype="post" layout="responsive" data-owner-id="-175249128" data-post-id="1156" data-hash="HmCFKRSM81NEzJ8mY9gzgXOlEFM"></amp-vk>`,
+			`<amp-vk height="300" width="500" data-embedtype="post" layout="responsive" data-owner-id="-175249128" data-post-id="1156" data-hash="HmCFKRSM81NEzJ8mY9gzgXOlEFM"></amp-vk>`,
 			`vkontakte`,
 		},
+		{
+			`<blockquote class="tiktok-embed" cite="https://www.tiktok.com/@rgru_official/video/7021366529212419330" data-video-id="7021366529212419330" style="max-width: 605px;min-width: 325px;" > <section> <a target="_blank" title="@rgru_official" href="https://www.tiktok.com/@rgru_official">@rgru_official</a> <a target="_blank" title="♬ original sound - rgru_official" href="https://www.tiktok.com/music/original-sound-7021366535231294210">♬ original sound - rgru_official</a> </section> </blockquote> <script async src="https://www.tiktok.com/embed.js"></script>`,
+			`<amp-tiktok layout="responsive" height="575" width="325" data-src="7021366529212419330"></amp-tiktok>`,
+			`tiktok`,
+		},
 		{
 			// error
 			`<blockquote class="twitter-tweet"><p lang="ru" dir="ltr">Андрей Сошенко. Когда рванет второй Чернобыль? <br>Рано или поздно, но на Украине обязательно сотворят глобальную катастрофу <a href="https://t.co/EQGPtpvxVF">https://t.co/EQGPtpvxVF</a> <a href="https://t.co/WBIrRCAvZq">pic.twitter.com/WBIrRCAvZq</a></p>&mdash; газета Завтра (@ZavtraRu) <a href="https://twitter.com/ZavtraRu/status/?ref_src=twsrc%5Etfw">January 9, 2020</a></blockquote> <script async src="https://platform.twitter.com/widgets.js" charset="utf-8"></script>`,
-			`unknown embed`,
+			`twitter: no source of embed`,
 			``,
 		},
 	}
@@ -241,7 +358,7 @@ func TestVkToTurbo(t *testing.T) {
 		VK.Widgets.Post("vk_post_-175249128_1156", 1156, 'HmCFKRSM81NEzJ8mY9gzgXOlEFM', {width: 500, height: 300});
 	}());
 	</script>`,
-			`cannot parse vk widget`,
+			`vkontakte: malformed embed`,
 		},
 		{
 			//error
@@ -252,7 +369,7 @@ func TestVkToTurbo(t *testing.T) {
 		VK.Widgets.Post("vk_post_-175249128_1156", 175249128, 1156, 'HmCFKRSM81NEzJ8mY9gzgXOlEFM', {width: 500});
 	}());
 	</script>`,
-			`parsed string does not match Vk widget post format`,
+			`vkontakte: malformed embed: VK.Widgets.Post("vk_post_-175249128_1156", 175249128, 1156, 'HmCFKRSM81NEzJ8mY9gzgXOlEFM'`,
 		},
 	}
 
@@ -285,10 +402,14 @@ func TestTwitToTurbo(t *testing.T) {
 			`<blockquote class="twitter-tweet"><p lang="ru" dir="ltr">Андрей Сошенко. Когда рванет второй Чернобыль? <br>Рано или поздно, но на Украине обязательно сотворят глобальную катастрофу <a href="https://t.co/EQGPtpvxVF">https://t.co/EQGPtpvxVF</a> <a href="https://t.co/WBIrRCAvZq">pic.twitter.com/WBIrRCAvZq</a></p>&mdash; газета Завтра (@ZavtraRu) <a href="https://twitter.com/ZavtraRu/status/1215336058755436547?ref_src=twsrc%5Etfw">January 9, 2020</a></blockquote> <script async src="https://platform.twitter.com/widgets.js" charset="utf-8"></script>`,
 			`<blockquote class="twitter-tweet"><p lang="ru" dir="ltr">Андрей Сошенко. Когда рванет второй Чернобыль? <br>Рано или поздно, но на Украине обязательно сотворят глобальную катастрофу <a href="https://t.co/EQGPtpvxVF">https://t.co/EQGPtpvxVF</a> <a href="https://t.co/WBIrRCAvZq">pic.twitter.com/WBIrRCAvZq</a></p>&mdash; газета Завтра (@ZavtraRu) <a href="https://twitter.com/ZavtraRu/status/1215336058755436547?ref_src=twsrc%5Etfw">January 9, 2020</a></blockquote> <script async src="https://platform.twitter.com/widgets.js" charset="utf-8"></script>`,
 		},
+		{
+			`<a class="twitter-timeline" data-height="600" href="https://x.com/rg_ru?ref_src=twsrc%5Etfw">Tweets by rg_ru</a> <script async src="https://platform.twitter.com/widgets.js" charset="utf-8"></script>`,
+			`<a class="twitter-timeline" data-height="600" href="https://x.com/rg_ru?ref_src=twsrc%5Etfw">Tweets by rg_ru</a> <script async src="https://platform.twitter.com/widgets.js" charset="utf-8"></script>`,
+		},
 		{
 			// error
 			`<blockquote class="twitter-tweet"><p lang="ru" dir="ltr">Андрей Сошенко. Когда рванет второй Чернобыль? <br>Рано или поздно, но на Украине обязательно сотворят глобальную катастрофу <a href="https://t.co/EQGPtpvxVF">https://t.co/EQGPtpvxVF</a> <a href="https://t.co/WBIrRCAvZq">pic.twitter.com/WBIrRCAvZq</a></p>&mdash; газета Завтра (@ZavtraRu) <a href="https://twitter.com/ZavtraRu/status/?ref_src=twsrc%5Etfw">January 9, 2020</a></blockquote> <script async src="https://platform.twitter.com/widgets.js" charset="utf-8"></script>`,
-			`no twitter ID in the url`,
+			`twitter: no source of embed`,
 		},
 	}
 
@@ -329,15 +450,23 @@ func TestInstaToTurbo(t *testing.T) {
 			`<blockquote class="instagram-media" data-instgrm-permalink="https://www.instagram.com/p/B6n1kfKoLmr/?utm_source=ig_embed&amp;utm_campaign=loading" data-instgrm-version="12" style=" background:#FFF; border:0; border-radius:3px; box-shadow:0 0 1px 0 rgba(0,0,0,0.5),0 1px 10px 0 rgba(0,0,0,0.15); margin: 1px; max-width:540px; min-width:326px; padding:0; width:99.375%; width:-webkit-calc(100% - 2px); width:calc(100% - 2px);"><div style="padding:16px;"> <a href="https://www.instagram.com/p/B6n1kfKoLmr/?utm_source=ig_embed&amp;utm_campaign=loading" style=" background:#FFFFFF; line-height:0; padding:0 0; text-align:center; text-decoration:none; width:100%;" target="_blank"> <div style=" display: flex; flex-direction: row; align-items: center;"> <div style="background-color: #F4F4F4; border-radius: 50%; flex-grow: 0; height: 40px; margin-right: 14px; width: 40px;"></div> <div style="display: flex; flex-direction: column; flex-grow: 1; justify-content: center;"> <div style=" background-color: #F4F4F4; border-radius: 4px; flex-grow: 0; height: 14px; margin-bottom: 6px; width: 100px;"></div> <div style=" background-color: #F4F4F4; border-radius: 4px; flex-grow: 0; height: 14px; width: 60px;"></div></div></div><div style="padding: 19% 0;"></div> <div style="display:block; height:50px; margin:0 auto 12px; width:50px;"><svg width="50px" height="50px" viewBox="0 0 60 60" version="1.1" xmlns="https://www.w3.org/2000/svg" xmlns:xlink="https://www.w3.org/1999/xlink"><g stroke="none" stroke-width="1" fill="none" fill-rule="evenodd"><g transform="translate(-511.000000, -20.000000)" fill="#000000"><g><path d="M556.869,30.41 C554.814,30.41 553.148,32.076 553.148,34.131 C553.148,36.186 554.814,37.852 556.869,37.852 C558.924,37.852 560.59,36.186 560.59,34.131 C560.59,32.076 558.924,30.41 556.869,30.41 M541,60.657 C535.114,60.657 530.342,55.887 530.342,50 C530.342,44.114 535.114,39.342 541,39.342 C546.887,39.342 551.658,44.114 551.658,50 C551.658,55.887 546.887,60.657 541,60.657 M541,33.886 C532.1,33.886 524.886,41.1 524.886,50 C524.886,58.899 532.1,66.113 541,66.113 C549.9,66.113 557.115,58.899 557.115,50 C557.115,41.1 549.9,33.886 541,33.886 M565.378,62.101 C565.244,65.022 564.756,66.606 564.346,67.663 C563.803,69.06 563.154,70.057 562.106,71.106 C561.058,72.155 560.06,72.803 558.662,73.347 C557.607,73.757 556.021,74.244 553.102,74.378 C549.944,74.521 548.997,74.552 541,74.552 C533.003,74.552 532.056,74.521 528.898,74.378 C525.979,74.244 524.393,73.757 523.338,73.347 C521.94,72.803 520.942,72.155 519.894,71.106 C518.846,70.057 518.197,69.06 517.654,67.663 C517.244,66.606 516.755,65.022 516.623,62.101 C516.479,58.943 516.448,57.996 516.448,50 C516.448,42.003 516.479,41.056 516.623,37.899 C516.755,34.978 517.244,33.391 517.654,32.338 C518.197,30.938 518.846,29.942 519.894,28.894 C520.942,27.846 521.94,27.196 523.338,26.654 C524.393,26.244 525.979,25.756 528.898,25.623 C532.057,25.479 533.004,25.448 541,25.448 C548.997,25.448 549.943,25.479 553.102,25.623 C556.021,25.756 557.607,26.244 558.662,26.654 C560.06,27.196 561.058,27.846 562.106,28.894 C563.154,29.942 563.803,30.938 564.346,32.338 C564.756,33.391 565.244,34.978 565.378,37.899 C565.522,41.056 565.552,42.003 565.552,50 C565.552,57.996 565.522,58.943 565.378,62.101 M570.82,37.631 C570.674,34.438 570.167,32.258 569.425,30.349 C568.659,28.377 567.633,26.702 565.965,25.035 C564.297,23.368 562.623,22.342 560.652,21.575 C558.743,20.834 556.562,20.326 553.369,20.18 C550.169,20.033 549.148,20 541,20 C532.853,20 531.831,20.033 528.631,20.18 C525.438,20.326 523.257,20.834 521.349,21.575 C519.376,22.342 517.703,23.368 516.035,25.035 C514.368,26.702 513.342,28.377 512.574,30.349 C511.834,32.258 511.326,34.438 511.181,37.631 C511.035,40.831 511,41.851 511,50 C511,58.147 511.035,59.17 511.181,62.369 C511.326,65.562 511.834,67.743 512.574,69.651 C513.342,71.625 514.368,73.296 516.035,74.965 C517.703,76.634 519.376,77.658 521.349,78.425 C523.257,79.167 525.438,79.673 528.631,79.82 C531.831,79.965 532.853,80.001 541,80.001 C549.148,80.001 550.169,79.965 553.369,79.82 C556.562,79.673 558.743,79.167 560.652,78.425 C562.623,77.658 564.297,76.634 565.965,74.965 C567.633,73.296 568.659,71.625 569.425,69.651 C570.167,67.743 570.674,65.562 570.82,62.369 C570.966,59.17 571,58.147 571,50 C571,41.851 570.966,40.831 570.82,37.631"></path></g></g></g></svg></div><div style="padding-top: 8px;"> <div style=" color:#3897f0; font-family:Arial,sans-serif; font-size:14px; font-style:normal; font-weight:550; line-height:18px;"> View this post on Instagram</div></div><div style="padding: 12.5% 0;"></div> <div style="display: flex; flex-direction: row; margin-bottom: 14px; align-items: center;"><div> <div style="background-color: #F4F4F4; border-radius: 50%; height: 12.5px; width: 12.5px; transform: translateX(0px) translateY(7px);"></div> <div style="background-color: #F4F4F4; height: 12.5px; transform: rotate(-45deg) translateX(3px) translateY(1px); width: 12.5px; flex-grow: 0; margin-right: 14px; margin-left: 2px;"></div> <div style="background-color: #F4F4F4; border-radius: 50%; height: 12.5px; width: 12.5px; transform: translateX(9px) translateY(-18px);"></div></div><div style="margin-left: 8px;"> <div style=" background-color: #F4F4F4; border-radius: 50%; flex-grow: 0; height: 20px; width: 20px;"></div> <div style=" width: 0; height: 0; border-top: 2px solid transparent; border-left: 6px solid #f4f4f4; border-bottom: 2px solid transparent; transform: translateX(16px) translateY(-4px) rotate(30deg)"></div></div><div style="margin-left: auto;"> <div style=" width: 0px; border-top: 8px solid #F4F4F4; border-right: 8px solid transparent; transform: translateY(16px);"></div> <div style=" background-color: #F4F4F4; flex-grow: 0; height: 12px; width: 16px; transform: translateY(-4px);"></div> <div style=" width: 0; height: 0; border-top: 8px solid #F4F4F4; border-left: 8px solid transparent; transform: translateY(-4px) translateX(8px);"></div></div></div> <div style="display: flex; flex-direction: column; flex-grow: 1; justify-content: center; margin-bottom: 24px;"> <div style=" background-color: #F4F4F4; border-radius: 4px; flex-grow: 0; height: 14px; margin-bottom: 6px; width: 224px;"></div> <div style=" background-color: #F4F4F4; border-radius: 4px; flex-grow: 0; height: 14px; width: 144px;"></div></div></a><p style=" color:#c9c8cd; font-family:Arial,sans-serif; font-size:14px; line-height:17px; margin-bottom:0; margin-top:8px; overflow:hidden; padding:8px 0 7px; text-align:center; text-overflow:ellipsis; white-space:nowrap;"><a href="https://www.instagram.com/p/B6n1kfKoLmr/?utm_source=ig_embed&amp;utm_campaign=loading" style=" color:#c9c8cd; font-family:Arial,sans-serif; font-size:14px; font-style:normal; font-weight:normal; line-height:17px; text-decoration:none;" target="_blank">A post shared by РВС - защита семьи 👨‍👩‍👧‍👦 (@rvs.news)</a> on <time style=" font-family:Arial,sans-serif; font-size:14px; line-height:17px;" datetime="2019-12-28T16:15:35+00:00">Dec 28, 2019 at 8:15am PST</time></p></div></blockquote> <script async src="//www.instagram.com/embed.js"></script>`,
 			`<blockquote class="instagram-media" data-instgrm-permalink="https://www.instagram.com/p/B6n1kfKoLmr/?utm_source=ig_embed&amp;utm_campaign=loading" data-instgrm-version="12" style=" background:#FFF; border:0; border-radius:3px; box-shadow:0 0 1px 0 rgba(0,0,0,0.5),0 1px 10px 0 rgba(0,0,0,0.15); margin: 1px; max-width:540px; min-width:326px; padding:0; width:99.375%; width:-webkit-calc(100% - 2px); width:calc(100% - 2px);"><div style="padding:16px;"> <a href="https://www.instagram.com/p/B6n1kfKoLmr/?utm_source=ig_embed&amp;utm_campaign=loading" style=" background:#FFFFFF; line-height:0; padding:0 0; text-align:center; text-decoration:none; width:100%;" target="_blank"> <div style=" display: flex; flex-direction: row; align-items: center;"> <div style="background-color: #F4F4F4; border-radius: 50%; flex-grow: 0; height: 40px; margin-right: 14px; width: 40px;"></div> <div style="display: flex; flex-direction: column; flex-grow: 1; justify-content: center;"> <div style=" background-color: #F4F4F4; border-radius: 4px; flex-grow: 0; height: 14px; margin-bottom: 6px; width: 100px;"></div> <div style=" background-color: #F4F4F4; border-radius: 4px; flex-grow: 0; height: 14px; width: 60px;"></div></div></div><div style="padding: 19% 0;"></div> <div style="display:block; height:50px; margin:0 auto 12px; width:50px;"><svg width="50px" height="50px" viewBox="0 0 60 60" version="1.1" xmlns="https://www.w3.org/2000/svg" xmlns:xlink="https://www.w3.org/1999/xlink"><g stroke="none" stroke-width="1" fill="none" fill-rule="evenodd"><g transform="translate(-511.000000, -20.000000)" fill="#000000"><g><path d="M556.869,30.41 C554.814,30.41 553.148,32.076 553.148,34.131 C553.148,36.186 554.814,37.852 556.869,37.852 C558.924,37.852 560.59,36.186 560.59,34.131 C560.59,32.076 558.924,30.41 556.869,30.41 M541,60.657 C535.114,60.657 530.342,55.887 530.342,50 C530.342,44.114 535.114,39.342 541,39.342 C546.887,39.342 551.658,44.114 551.658,50 C551.658,55.887 546.887,60.657 541,60.657 M541,33.886 C532.1,33.886 524.886,41.1 524.886,50 C524.886,58.899 532.1,66.113 541,66.113 C549.9,66.113 557.115,58.899 557.115,50 C557.115,41.1 549.9,33.886 541,33.886 M565.378,62.101 C565.244,65.022 564.756,66.606 564.346,67.663 C563.803,69.06 563.154,70.057 562.106,71.106 C561.058,72.155 560.06,72.803 558.662,73.347 C557.607,73.757 556.021,74.244 553.102,74.378 C549.944,74.521 548.997,74.552 541,74.552 C533.003,74.552 532.056,74.521 528.898,74.378 C525.979,74.244 524.393,73.757 523.338,73.347 C521.94,72.803 520.942,72.155 519.894,71.106 C518.846,70.057 518.197,69.06 517.654,67.663 C517.244,66.606 516.755,65.022 516.623,62.101 C516.479,58.943 516.448,57.996 516.448,50 C516.448,42.003 516.479,41.056 516.623,37.899 C516.755,34.978 517.244,33.391 517.654,32.338 C518.197,30.938 518.846,29.942 519.894,28.894 C520.942,27.846 521.94,27.196 523.338,26.654 C524.393,26.244 525.979,25.756 528.898,25.623 C532.057,25.479 533.004,25.448 541,25.448 C548.997,25.448 549.943,25.479 553.102,25.623 C556.021,25.756 557.607,26.244 558.662,26.654 C560.06,27.196 561.058,27.846 562.106,28.894 C563.154,29.942 563.803,30.938 564.346,32.338 C564.756,33.391 565.244,34.978 565.378,37.899 C565.522,41.056 565.552,42.003 565.552,50 C565.552,57.996 565.522,58.943 565.378,62.101 M570.82,37.631 C570.674,34.438 570.167,32.258 569.425,30.349 C568.659,28.377 567.633,26.702 565.965,25.035 C564.297,23.368 562.623,22.342 560.652,21.575 C558.743,20.834 556.562,20.326 553.369,20.18 C550.169,20.033 549.148,20 541,20 C532.853,20 531.831,20.033 528.631,20.18 C525.438,20.326 523.257,20.834 521.349,21.575 C519.376,22.342 517.703,23.368 516.035,25.035 C514.368,26.702 513.342,28.377 512.574,30.349 C511.834,32.258 511.326,34.438 511.181,37.631 C511.035,40.831 511,41.851 511,50 C511,58.147 511.035,59.17 511.181,62.369 C511.326,65.562 511.834,67.743 512.574,69.651 C513.342,71.625 514.368,73.296 516.035,74.965 C517.703,76.634 519.376,77.658 521.349,78.425 C523.257,79.167 525.438,79.673 528.631,79.82 C531.831,79.965 532.853,80.001 541,80.001 C549.148,80.001 550.169,79.965 553.369,79.82 C556.562,79.673 558.743,79.167 560.652,78.425 C562.623,77.658 564.297,76.634 565.965,74.965 C567.633,73.296 568.659,71.625 569.425,69.651 C570.167,67.743 570.674,65.562 570.82,62.369 C570.966,59.17 571,58.147 571,50 C571,41.851 570.966,40.831 570.82,37.631"></path></g></g></g></svg></div><div style="padding-top: 8px;"> <div style=" color:#3897f0; font-family:Arial,sans-serif; font-size:14px; font-style:normal; font-weight:550; line-height:18px;"> View this post on Instagram</div></div><div style="padding: 12.5% 0;"></div> <div style="display: flex; flex-direction: row; margin-bottom: 14px; align-items: center;"><div> <div style="background-color: #F4F4F4; border-radius: 50%; height: 12.5px; width: 12.5px; transform: translateX(0px) translateY(7px);"></div> <div style="background-color: #F4F4F4; height: 12.5px; transform: rotate(-45deg) translateX(3px) translateY(1px); width: 12.5px; flex-grow: 0; margin-right: 14px; margin-left: 2px;"></div> <div style="background-color: #F4F4F4; border-radius: 50%; height: 12.5px; width: 12.5px; transform: translateX(9px) translateY(-18px);"></div></div><div style="margin-left: 8px;"> <div style=" background-color: #F4F4F4; border-radius: 50%; flex-grow: 0; height: 20px; width: 20px;"></div> <div style=" width: 0; height: 0; border-top: 2px solid transparent; border-left: 6px solid #f4f4f4; border-bottom: 2px solid transparent; transform: translateX(16px) translateY(-4px) rotate(30deg)"></div></div><div style="margin-left: auto;"> <div style=" width: 0px; border-top: 8px solid #F4F4F4; border-right: 8px solid transparent; transform: translateY(16px);"></div> <div style=" background-color: #F4F4F4; flex-grow: 0; height: 12px; width: 16px; transform: translateY(-4px);"></div> <div style=" width: 0; height: 0; border-top: 8px solid #F4F4F4; border-left: 8px solid transparent; transform: translateY(-4px) translateX(8px);"></div></div></div> <div style="display: flex; flex-direction: column; flex-grow: 1; justify-content: center; margin-bottom: 24px;"> <div style=" background-color: #F4F4F4; border-radius: 4px; flex-grow: 0; height: 14px; margin-bottom: 6px; width: 224px;"></div> <div style=" background-color: #F4F4F4; border-radius: 4px; flex-grow: 0; height: 14px; width: 144px;"></div></div></a><p style=" color:#c9c8cd; font-family:Arial,sans-serif; font-size:14px; line-height:17px; margin-bottom:0; margin-top:8px; overflow:hidden; padding:8px 0 7px; text-align:center; text-overflow:ellipsis; white-space:nowrap;"><a href="https://www.instagram.com/p/B6n1kfKoLmr/?utm_source=ig_embed&amp;utm_campaign=loading" style=" color:#c9c8cd; font-family:Arial,sans-serif; font-size:14px; font-style:normal; font-weight:normal; line-height:17px; text-decoration:none;" target="_blank">A post shared by РВС - защита семьи 👨‍👩‍👧‍👦 (@rvs.news)</a> on <time style=" font-family:Arial,sans-serif; font-size:14px; line-height:17px;" datetime="2019-12-28T16:15:35+00:00">Dec 28, 2019 at 8:15am PST</time></p></div></blockquote> <script async src="//www.instagram.com/embed.js"></script>`,
 		},
+		{
+			`<blockquote class="instagram-media" data-instgrm-permalink="https://www.instagram.com/reels/C7kQ2xNPxYz" data-instgrm-version="14"></blockquote>`,
+			`<blockquote class="instagram-media" data-instgrm-permalink="https://www.instagram.com/reels/C7kQ2xNPxYz" data-instgrm-version="14"></blockquote>`,
+		},
+		{
+			`<blockquote class="instagram-media" data-instgrm-permalink="https://www.instagram.com/stories/rgru/3370000000000000000/" data-instgrm-version="14"></blockquote>`,
+			`instagram: malformed url: https://www.instagram.com/stories/rgru/3370000000000000000/`,
+		},
 		{
 			// error
 			`<blockquote class="instagram-media" data-instgrm-permalink="https://gram.com/p/B6n1kfKoLmr/?utm_source=ig_embed&amp;utm_campaign=loading" data-instgrm-version="12" style=" background:#FFF; border:0; border-radius:3px; box-shadow:0 0 1px 0 rgba(0,0,0,0.5),0 1px 10px 0 rgba(0,0,0,0.15); margin: 1px; max-width:540px; min-width:326px; padding:0; width:99.375%; width:-webkit-calc(100% - 2px); width:calc(100% - 2px);"><div style="padding:16px;"> <a href="https://www.instagram.com/p/B6n1kfKoLmr/?utm_source=ig_embed&amp;utm_campaign=loading" style=" background:#FFFFFF; line-height:0; padding:0 0; text-align:center; text-decoration:none; width:100%;" target="_blank"> <div style=" display: flex; flex-direction: row; align-items: center;"> <div style="background-color: #F4F4F4; border-radius: 50%; flex-grow: 0; height: 40px; margin-right: 14px; width: 40px;"></div> <div style="display: flex; flex-direction: column; flex-grow: 1; justify-content: center;"> <div style=" background-color: #F4F4F4; border-radius: 4px; flex-grow: 0; height: 14px; margin-bottom: 6px; width: 100px;"></div> <div style=" background-color: #F4F4F4; border-radius: 4px; flex-grow: 0; height: 14px; width: 60px;"></div></div></div><div style="padding: 19% 0;"></div> <div style="display:block; height:50px; margin:0 auto 12px; width:50px;"><svg width="50px" height="50px" viewBox="0 0 60 60" version="1.1" xmlns="https://www.w3.org/2000/svg" xmlns:xlink="https://www.w3.org/1999/xlink"><g stroke="none" stroke-width="1" fill="none" fill-rule="evenodd"><g transform="translate(-511.000000, -20.000000)" fill="#000000"><g><path d="M556.869,30.41 C554.814,30.41 553.148,32.076 553.148,34.131 C553.148,36.186 554.814,37.852 556.869,37.852 C558.924,37.852 560.59,36.186 560.59,34.131 C560.59,32.076 558.924,30.41 556.869,30.41 M541,60.657 C535.114,60.657 530.342,55.887 530.342,50 C530.342,44.114 535.114,39.342 541,39.342 C546.887,39.342 551.658,44.114 551.658,50 C551.658,55.887 546.887,60.657 541,60.657 M541,33.886 C532.1,33.886 524.886,41.1 524.886,50 C524.886,58.899 532.1,66.113 541,66.113 C549.9,66.113 557.115,58.899 557.115,50 C557.115,41.1 549.9,33.886 541,33.886 M565.378,62.101 C565.244,65.022 564.756,66.606 564.346,67.663 C563.803,69.06 563.154,70.057 562.106,71.106 C561.058,72.155 560.06,72.803 558.662,73.347 C557.607,73.757 556.021,74.244 553.102,74.378 C549.944,74.521 548.997,74.552 541,74.552 C533.003,74.552 532.056,74.521 528.898,74.378 C525.979,74.244 524.393,73.757 523.338,73.347 C521.94,72.803 520.942,72.155 519.894,71.106 C518.846,70.057 518.197,69.06 517.654,67.663 C517.244,66.606 516.755,65.022 516.623,62.101 C516.479,58.943 516.448,57.996 516.448,50 C516.448,42.003 516.479,41.056 516.623,37.899 C516.755,34.978 517.244,33.391 517.654,32.338 C518.197,30.938 518.846,29.942 519.894,28.894 C520.942,27.846 521.94,27.196 523.338,26.654 C524.393,26.244 525.979,25.756 528.898,25.623 C532.057,25.479 533.004,25.448 541,25.448 C548.997,25.448 549.943,25.479 553.102,25.623 C556.021,25.756 557.607,26.244 558.662,26.654 C560.06,27.196 561.058,27.846 562.106,28.894 C563.154,29.942 563.803,30.938 564.346,32.338 C564.756,33.391 565.244,34.978 565.378,37.899 C565.522,41.056 565.552,42.003 565.552,50 C565.552,57.996 565.522,58.943 565.378,62.101 M570.82,37.631 C570.674,34.438 570.167,32.258 569.425,30.349 C568.659,28.377 567.633,26.702 565.965,25.035 C564.297,23.368 562.623,22.342 560.652,21.575 C558.743,20.834 556.562,20.326 553.369,20.18 C550.169,20.033 549.148,20 541,20 C532.853,20 531.831,20.033 528.631,20.18 C525.438,20.326 523.257,20.834 521.349,21.575 C519.376,22.342 517.703,23.368 516.035,25.035 C514.368,26.702 513.342,28.377 512.574,30.349 C511.834,32.258 511.326,34.438 511.181,37.631 C511.035,40.831 511,41.851 511,50 C511,58.147 511.035,59.17 511.181,62.369 C511.326,65.562 511.834,67.743 512.574,69.651 C513.342,71.625 514.368,73.296 516.035,74.965 C517.703,76.634 519.376,77.658 521.349,78.425 C523.257,79.167 525.438,79.673 528.631,79.82 C531.831,79.965 532.853,80.001 541,80.001 C549.148,80.001 550.169,79.965 553.369,79.82 C556.562,79.673 558.743,79.167 560.652,78.425 C562.623,77.658 564.297,76.634 565.965,74.965 C567.633,73.296 568.659,71.625 569.425,69.651 C570.167,67.743 570.674,65.562 570.82,62.369 C570.966,59.17 571,58.147 571,50 C571,41.851 570.966,40.831 570.82,37.631"></path></g></g></g></svg></div><div style="padding-top: 8px;"> <div style=" color:#3897f0; font-family:Arial,sans-serif; font-size:14px; font-style:normal; font-weight:550; line-height:18px;"> View this post on Instagram</div></div><div style="padding: 12.5% 0;"></div> <div style="display: flex; flex-direction: row; margin-bottom: 14px; align-items: center;"><div> <div style="background-color: #F4F4F4; border-radius: 50%; height: 12.5px; width: 12.5px; transform: translateX(0px) translateY(7px);"></div> <div style="background-color: #F4F4F4; height: 12.5px; transform: rotate(-45deg) translateX(3px) translateY(1px); width: 12.5px; flex-grow: 0; margin-right: 14px; margin-left: 2px;"></div> <div style="background-color: #F4F4F4; border-radius: 50%; height: 12.5px; width: 12.5px; transform: translateX(9px) translateY(-18px);"></div></div><div style="margin-left: 8px;"> <div style=" background-color: #F4F4F4; border-radius: 50%; flex-grow: 0; height: 20px; width: 20px;"></div> <div style=" width: 0; height: 0; border-top: 2px solid transparent; border-left: 6px solid #f4f4f4; border-bottom: 2px solid transparent; transform: translateX(16px) translateY(-4px) rotate(30deg)"></div></div><div style="margin-left: auto;"> <div style=" width: 0px; border-top: 8px solid #F4F4F4; border-right: 8px solid transparent; transform: translateY(16px);"></div> <div style=" background-color: #F4F4F4; flex-grow: 0; height: 12px; width: 16px; transform: translateY(-4px);"></div> <div style=" width: 0; height: 0; border-top: 8px solid #F4F4F4; border-left: 8px solid transparent; transform: translateY(-4px) translateX(8px);"></div></div></div> <div style="display: flex; flex-direction: column; flex-grow: 1; justify-content: center; margin-bottom: 24px;"> <div style=" background-color: #F4F4F4; border-radius: 4px; flex-grow: 0; height: 14px; margin-bottom: 6px; width: 224px;"></div> <div style=" background-color: #F4F4F4; border-radius: 4px; flex-grow: 0; height: 14px; width: 144px;"></div></div></a><p style=" color:#c9c8cd; font-family:Arial,sans-serif; font-size:14px; line-height:17px; margin-bottom:0; margin-top:8px; overflow:hidden; padding:8px 0 7px; text-align:center; text-overflow:ellipsis; white-space:nowrap;"><a href="https://www.instagram.com/p/B6n1kfKoLmr/?utm_source=ig_embed&amp;utm_campaign=loading" style=" color:#c9c8cd; font-family:Arial,sans-serif; font-size:14px; font-style:normal; font-weight:normal; line-height:17px; text-decoration:none;" target="_blank">A post shared by РВС - защита семьи 👨‍👩‍👧‍👦 (@rvs.news)</a> on <time style=" font-family:Arial,sans-serif; font-size:14px; line-height:17px;" datetime="2019-12-28T16:15:35+00:00">Dec 28, 2019 at 8:15am PST</time></p></div></blockquote> <script async src="//www.instagram.com/embed.js"></script>`,
-			`it is not instagram url`,
+			`instagram: wrong host: gram.com`,
 		},
 		{
 			// error
 			`<blockquote class="instagram-media" data-instgrm-permalink="" data-instgrm-version="12" style=" background:#FFF; border:0; border-radius:3px; box-shadow:0 0 1px 0 rgba(0,0,0,0.5),0 1px 10px 0 rgba(0,0,0,0.15); margin: 1px; max-width:540px; min-width:326px; padding:0; width:99.375%; width:-webkit-calc(100% - 2px); width:calc(100% - 2px);"><div style="padding:16px;"> <a href="https://www.instagram.com/p/B6n1kfKoLmr/?utm_source=ig_embed&amp;utm_campaign=loading" style=" background:#FFFFFF; line-height:0; padding:0 0; text-align:center; text-decoration:none; width:100%;" target="_blank"> <div style=" display: flex; flex-direction: row; align-items: center;"> <div style="background-color: #F4F4F4; border-radius: 50%; flex-grow: 0; height: 40px; margin-right: 14px; width: 40px;"></div> <div style="display: flex; flex-direction: column; flex-grow: 1; justify-content: center;"> <div style=" background-color: #F4F4F4; border-radius: 4px; flex-grow: 0; height: 14px; margin-bottom: 6px; width: 100px;"></div> <div style=" background-color: #F4F4F4; border-radius: 4px; flex-grow: 0; height: 14px; width: 60px;"></div></div></div><div style="padding: 19% 0;"></div> <div style="display:block; height:50px; margin:0 auto 12px; width:50px;"><svg width="50px" height="50px" viewBox="0 0 60 60" version="1.1" xmlns="https://www.w3.org/2000/svg" xmlns:xlink="https://www.w3.org/1999/xlink"><g stroke="none" stroke-width="1" fill="none" fill-rule="evenodd"><g transform="translate(-511.000000, -20.000000)" fill="#000000"><g><path d="M556.869,30.41 C554.814,30.41 553.148,32.076 553.148,34.131 C553.148,36.186 554.814,37.852 556.869,37.852 C558.924,37.852 560.59,36.186 560.59,34.131 C560.59,32.076 558.924,30.41 556.869,30.41 M541,60.657 C535.114,60.657 530.342,55.887 530.342,50 C530.342,44.114 535.114,39.342 541,39.342 C546.887,39.342 551.658,44.114 551.658,50 C551.658,55.887 546.887,60.657 541,60.657 M541,33.886 C532.1,33.886 524.886,41.1 524.886,50 C524.886,58.899 532.1,66.113 541,66.113 C549.9,66.113 557.115,58.899 557.115,50 C557.115,41.1 549.9,33.886 541,33.886 M565.378,62.101 C565.244,65.022 564.756,66.606 564.346,67.663 C563.803,69.06 563.154,70.057 562.106,71.106 C561.058,72.155 560.06,72.803 558.662,73.347 C557.607,73.757 556.021,74.244 553.102,74.378 C549.944,74.521 548.997,74.552 541,74.552 C533.003,74.552 532.056,74.521 528.898,74.378 C525.979,74.244 524.393,73.757 523.338,73.347 C521.94,72.803 520.942,72.155 519.894,71.106 C518.846,70.057 518.197,69.06 517.654,67.663 C517.244,66.606 516.755,65.022 516.623,62.101 C516.479,58.943 516.448,57.996 516.448,50 C516.448,42.003 516.479,41.056 516.623,37.899 C516.755,34.978 517.244,33.391 517.654,32.338 C518.197,30.938 518.846,29.942 519.894,28.894 C520.942,27.846 521.94,27.196 523.338,26.654 C524.393,26.244 525.979,25.756 528.898,25.623 C532.057,25.479 533.004,25.448 541,25.448 C548.997,25.448 549.943,25.479 553.102,25.623 C556.021,25.756 557.607,26.244 558.662,26.654 C560.06,27.196 561.058,27.846 562.106,28.894 C563.154,29.942 563.803,30.938 564.346,32.338 C564.756,33.391 565.244,34.978 565.378,37.899 C565.522,41.056 565.552,42.003 565.552,50 C565.552,57.996 565.522,58.943 565.378,62.101 M570.82,37.631 C570.674,34.438 570.167,32.258 569.425,30.349 C568.659,28.377 567.633,26.702 565.965,25.035 C564.297,23.368 562.623,22.342 560.652,21.575 C558.743,20.834 556.562,20.326 553.369,20.18 C550.169,20.033 549.148,20 541,20 C532.853,20 531.831,20.033 528.631,20.18 C525.438,20.326 523.257,20.834 521.349,21.575 C519.376,22.342 517.703,23.368 516.035,25.035 C514.368,26.702 513.342,28.377 512.574,30.349 C511.834,32.258 511.326,34.438 511.181,37.631 C511.035,40.831 511,41.851 511,50 C511,58.147 511.035,59.17 511.181,62.369 C511.326,65.562 511.834,67.743 512.574,69.651 C513.342,71.625 514.368,73.296 516.035,74.965 C517.703,76.634 519.376,77.658 521.349,78.425 C523.257,79.167 525.438,79.673 528.631,79.82 C531.831,79.965 532.853,80.001 541,80.001 C549.148,80.001 550.169,79.965 553.369,79.82 C556.562,79.673 558.743,79.167 560.652,78.425 C562.623,77.658 564.297,76.634 565.965,74.965 C567.633,73.296 568.659,71.625 569.425,69.651 C570.167,67.743 570.674,65.562 570.82,62.369 C570.966,59.17 571,58.147 571,50 C571,41.851 570.966,40.831 570.82,37.631"></path></g></g></g></svg></div><div style="padding-top: 8px;"> <div style=" color:#3897f0; font-family:Arial,sans-serif; font-size:14px; font-style:normal; font-weight:550; line-height:18px;"> View this post on Instagram</div></div><div style="padding: 12.5% 0;"></div> <div style="display: flex; flex-direction: row; margin-bottom: 14px; align-items: center;"><div> <div style="background-color: #F4F4F4; border-radius: 50%; height: 12.5px; width: 12.5px; transform: translateX(0px) translateY(7px);"></div> <div style="background-color: #F4F4F4; height: 12.5px; transform: rotate(-45deg) translateX(3px) translateY(1px); width: 12.5px; flex-grow: 0; margin-right: 14px; margin-left: 2px;"></div> <div style="background-color: #F4F4F4; border-radius: 50%; height: 12.5px; width: 12.5px; transform: translateX(9px) translateY(-18px);"></div></div><div style="margin-left: 8px;"> <div style=" background-color: #F4F4F4; border-radius: 50%; flex-grow: 0; height: 20px; width: 20px;"></div> <div style=" width: 0; height: 0; border-top: 2px solid transparent; border-left: 6px solid #f4f4f4; border-bottom: 2px solid transparent; transform: translateX(16px) translateY(-4px) rotate(30deg)"></div></div><div style="margin-left: auto;"> <div style=" width: 0px; border-top: 8px solid #F4F4F4; border-right: 8px solid transparent; transform: translateY(16px);"></div> <div style=" background-color: #F4F4F4; flex-grow: 0; height: 12px; width: 16px; transform: translateY(-4px);"></div> <div style=" width: 0; height: 0; border-top: 8px solid #F4F4F4; border-left: 8px solid transparent; transform: translateY(-4px) translateX(8px);"></div></div></div> <div style="display: flex; flex-direction: column; flex-grow: 1; justify-content: center; margin-bottom: 24px;"> <div style=" background-color: #F4F4F4; border-radius: 4px; flex-grow: 0; height: 14px; margin-bottom: 6px; width: 224px;"></div> <div style=" background-color: #F4F4F4; border-radius: 4px; flex-grow: 0; height: 14px; width: 144px;"></div></div></a><p style=" color:#c9c8cd; font-family:Arial,sans-serif; font-size:14px; line-height:17px; margin-bottom:0; margin-top:8px; overflow:hidden; padding:8px 0 7px; text-align:center; text-overflow:ellipsis; white-space:nowrap;"><a href="https://www.instagram.com/p/B6n1kfKoLmr/?utm_source=ig_embed&amp;utm_campaign=loading" style=" color:#c9c8cd; font-family:Arial,sans-serif; font-size:14px; font-style:normal; font-weight:normal; line-height:17px; text-decoration:none;" target="_blank">A post shared by РВС - защита семьи 👨‍👩‍👧‍👦 (@rvs.news)</a> on <time style=" font-family:Arial,sans-serif; font-size:14px; line-height:17px;" datetime="2019-12-28T16:15:35+00:00">Dec 28, 2019 at 8:15am PST</time></p></div></blockquote> <script async src="//www.instagram.com/embed.js"></script>`,
-			`no src in the url`,
+			`instagram: no source of embed`,
 		},
 	}
 
@@ -373,10 +502,18 @@ func TestFbToTurbo(t *testing.T) {
 			`<iframe src="https://www.facebook.com/plugins/video.php?href=https%3A%2F%2Fwww.facebook.com%2Fbarsuksergey%2Fvideos%2F2720743767989363%2F&show_text=0&width=560" width="560" height="308" style="border:none;overflow:hidden" scrolling="no" frameborder="0" allowTransparency="true" allowFullScreen="true"></iframe>`,
 			`<iframe src="https://www.facebook.com/plugins/video.php?href=https%3A%2F%2Fwww.facebook.com%2Fbarsuksergey%2Fvideos%2F2720743767989363%2F&show_text=0&width=560" width="560" height="308" style="border:none;overflow:hidden" scrolling="no" frameborder="0" allowTransparency="true" allowFullScreen="true"></iframe>`,
 		},
+		{
+			`<div id="fb-root"></div><script async defer crossorigin="anonymous" src="https://connect.facebook.net/ru_RU/sdk.js#xfbml=1&version=v19.0"></script><div class="fb-post" data-href="https://www.facebook.com/stcnk/posts/3384458724928901" data-width="500" data-show-text="true"><blockquote cite="https://www.facebook.com/stcnk/posts/3384458724928901" class="fb-xfbml-parse-ignore">Post</blockquote></div>`,
+			`<div id="fb-root"></div><script async defer crossorigin="anonymous" src="https://connect.facebook.net/ru_RU/sdk.js#xfbml=1&version=v19.0"></script><div class="fb-post" data-href="https://www.facebook.com/stcnk/posts/3384458724928901" data-width="500" data-show-text="true"><blockquote cite="https://www.facebook.com/stcnk/posts/3384458724928901" class="fb-xfbml-parse-ignore">Post</blockquote></div>`,
+		},
+		{
+			`<div class="fb-comments" data-href="https://rg.ru/2024/05/27/news.html" data-width="" data-numposts="5"></div>`,
+			`<div class="fb-comments" data-href="https://rg.ru/2024/05/27/news.html" data-width="" data-numposts="5"></div>`,
+		},
 		{
 			//error
 			`<iframe src="" width="560" height="308" style="border:none;overflow:hidden" scrolling="no" frameborder="0" allowTransparency="true" allowFullScreen="true"></iframe>`,
-			`no src in the url`,
+			`facebook: no source of embed`,
 		},
 	}
 
@@ -419,7 +556,7 @@ func TestIframeToTurbo(t *testing.T) {
 		{
 			//error
 			`<div style="position: relative;padding-bottom: 56.25%; padding-top: 25px; height: 0;"><iframe style="position: absolute;top: 0;left: 0;width: 100%;height: 100%;" src="" frameborder="0" allowfullscreen/></iframe></div>`,
-			`no src in the url`,
+			`iframe: no source of embed`,
 		},
 	}
 
@@ -455,9 +592,17 @@ func TestYoutubeToTurbo(t *testing.T) {
 			`<iframe height="315" src="https://www.youtube.com/embed/TVakXOkE2G4" frameborder="0" allow="accelerometer; autoplay; encrypted-media; gyroscope; picture-in-picture" allowfullscreen></iframe>`,
 			`<iframe height="315" allowfullscreen="true" frameborder="0" src="https://www.youtube.com/embed/TVakXOkE2G4"></iframe>`,
 		},
+		{
+			`<iframe width="560" height="315" src="https://www.youtube-nocookie.com/embed/05klG-PTKqo?si=Xy1&amp;start=42" frameborder="0" allowfullscreen></iframe>`,
+			`<iframe width="560" height="315" allowfullscreen="true" frameborder="0" src="https://www.youtube-nocookie.com/embed/05klG-PTKqo?start=42"></iframe>`,
+		},
+		{
+			`<iframe src="https://www.youtube.com/embed/videoseries?list=PLx0sYbCqOb8TBPRdmBHs5Iftvv9TPboYG" frameborder="0"></iframe>`,
+			`<iframe frameborder="0" src="https://www.youtube.com/embed/videoseries?list=PLx0sYbCqOb8TBPRdmBHs5Iftvv9TPboYG"></iframe>`,
+		},
 		{ //error
 			`<iframe width="560" height="315" src="https://www.youtube.com/embed/" frameborder="0" allow="accelerometer; autoplay; encrypted-media; gyroscope; picture-in-picture" allowfullscreen></iframe>`,
-			`youtube url is malformed`,
+			`youtube: malformed url: https://www.youtube.com/embed/`,
 		},
 	}
 
@@ -483,24 +628,24 @@ func TestIframeToAMP(t *testing.T) {
 	}{
 		{
 			`<div style="position: relative;padding-bottom: 56.25%; padding-top: 25px; height: 0;"><iframe style="position: absolute;top: 0;left: 0;width: 100%;height: 100%;" src="https://russian.rt.com/nopolitics/video/706313-popokatepetl-stolb-pepel-3-km/video/5e1852ef02e8bd3b731db837" frameborder="0" allowfullscreen/></iframe></div>`,
-			`<amp-iframe width="480" height="315" sandbox="allow-scripts allow-same-origin" layout="responsive" frameborder="0" allowfullscreen src="https://russian.rt.com/nopolitics/video/706313-popokatepetl-stolb-pepel-3-km/video/5e1852ef02e8bd3b731db837"`,
+			`<amp-iframe width="480" height="315" sandbox="allow-scripts allow-same-origin" layout="responsive" frameborder="0" allowfullscreen src="https://russian.rt.com/nopolitics/video/706313-popokatepetl-stolb-pepel-3-km/video/5e1852ef02e8bd3b731db837"></amp-iframe>`,
 		},
 		{
 			`<div style="position: relative;padding-bottom: 56.25%; padding-top: 25px; height: 0;"><iframe style="position: absolute;top: 0;left: 0;width: 100%;height: 100%;" src="https://russian.rt.com/world/video/706283-posol-iran-oon-ssha-suleimani/video/5e184bbf02e8bd3f073eebeb" frameborder="0"/></iframe></div>`,
-			`<amp-iframe width="480" height="315" sandbox="allow-scripts allow-same-origin" layout="responsive" frameborder="0" src="https://russian.rt.com/world/video/706283-posol-iran-oon-ssha-suleimani/video/5e184bbf02e8bd3f073eebeb"`,
+			`<amp-iframe width="480" height="315" sandbox="allow-scripts allow-same-origin" layout="responsive" frameborder="0" src="https://russian.rt.com/world/video/706283-posol-iran-oon-ssha-suleimani/video/5e184bbf02e8bd3f073eebeb"></amp-iframe>`,
 		},
 		{
 			`<div style="position: relative;padding-bottom: 56.25%; padding-top: 25px; height: 0;"><iframe style="position: absolute;top: 0;left: 0;width: 100%;height: 100%;" src="https://russian.rt.com/world/video/706283-posol-iran-oon-ssha-suleimani/video/5e184bbf02e8bd3f073eebeb" frameborder="2"/></iframe></div>`,
-			`<amp-iframe width="480" height="315" sandbox="allow-scripts allow-same-origin" layout="responsive" frameborder="2" src="https://russian.rt.com/world/video/706283-posol-iran-oon-ssha-suleimani/video/5e184bbf02e8bd3f073eebeb"`,
+			`<amp-iframe width="480" height="315" sandbox="allow-scripts allow-same-origin" layout="responsive" frameborder="2" src="https://russian.rt.com/world/video/706283-posol-iran-oon-ssha-suleimani/video/5e184bbf02e8bd3f073eebeb"></amp-iframe>`,
 		},
 		{
 			`<div style="position: relative;padding-bottom: 56.25%; padding-top: 25px; height: 0;"><iframe style="position: absolute;top: 0;left: 0;width: 100%;height: 100%;" src="https://russian.rt.com/world/video/706283-posol-iran-oon-ssha-suleimani/video/5e184bbf02e8bd3f073eebeb" frameborder="0" allowfullscreen/></iframe></div>`,
-			`<amp-iframe width="480" height="315" sandbox="allow-scripts allow-same-origin" layout="responsive" frameborder="0" allowfullscreen src="https://russian.rt.com/world/video/706283-posol-iran-oon-ssha-suleimani/video/5e184bbf02e8bd3f073eebeb"`,
+			`<amp-iframe width="480" height="315" sandbox="allow-scripts allow-same-origin" layout="responsive" frameborder="0" allowfullscreen src="https://russian.rt.com/world/video/706283-posol-iran-oon-ssha-suleimani/video/5e184bbf02e8bd3f073eebeb"></amp-iframe>`,
 		},
 		{
 			//error
 			`<div style="position: relative;padding-bottom: 56.25%; padding-top: 25px; height: 0;"><iframe style="position: absolute;top: 0;left: 0;width: 100%;height: 100%;" src="" frameborder="0" allowfullscreen/></iframe></div>`,
-			`no src in the url`,
+			`iframe: no source of embed`,
 		},
 	}
 
@@ -542,7 +687,35 @@ func TestYoutubeToAMP(t *testing.T) {
 		},
 		{ //error
 			`<iframe width="560" height="315" src="https://www.youtube.com/embed/" frameborder="0" allow="accelerometer; autoplay; encrypted-media; gyroscope; picture-in-picture" allowfullscreen></iframe>`,
-			`youtube url is malformed`,
+			`youtube: malformed url: https://www.youtube.com/embed/`,
+		},
+		{
+			`<iframe width="560" height="315" src="https://www.youtube-nocookie.com/embed/05klG-PTKqo?start=42&amp;feature=oembed" frameborder="0" allowfullscreen></iframe>`,
+			`<amp-youtube layout="responsive" height="315" width="560" credentials="omit" data-param-start="42" data-videoid="05klG-PTKqo"></amp-youtube>`,
+		},
+		{
+			`<iframe width="315" height="560" src="https://www.youtube.com/shorts/TVakXOkE2G4"></iframe>`,
+			`<amp-youtube layout="responsive" height="560" width="315" data-videoid="TVakXOkE2G4"></amp-youtube>`,
+		},
+		{
+			`<iframe src="https://youtu.be/TVakXOkE2G4?t=1m30s"></iframe>`,
+			`<amp-youtube layout="responsive" height="315" width="480" data-param-start="90" data-videoid="TVakXOkE2G4"></amp-youtube>`,
+		},
+		{
+			`<iframe src="https://www.youtube.com/embed/videoseries?list=PLx0sYbCqOb8TBPRdmBHs5Iftvv9TPboYG&amp;autoplay=1"></iframe>`,
+			`<amp-youtube layout="responsive" height="315" width="480" autoplay data-param-list="PLx0sYbCqOb8TBPRdmBHs5Iftvv9TPboYG" data-videoid="videoseries"></amp-youtube>`,
+		},
+		{
+			`<iframe src="https://www.youtube.com/embed/TVakXOkE2G4?list=PLx0sYbCqOb8TBPRdmBHs5Iftvv9TPboYG&amp;index=2"></iframe>`,
+			`<amp-youtube layout="responsive" height="315" width="480" data-param-list="PLx0sYbCqOb8TBPRdmBHs5Iftvv9TPboYG" data-videoid="TVakXOkE2G4"></amp-youtube>`,
+		},
+		{
+			`<iframe src="https://www.youtube.com/embed/videoseries"></iframe>`,
+			`youtube: malformed url: https://www.youtube.com/embed/videoseries`,
+		},
+		{
+			`<iframe src="https://youtube.com.example.com/embed/TVakXOkE2G4"></iframe>`,
+			`youtube: wrong host: youtube.com.example.com`,
 		},
 	}
 
@@ -577,7 +750,39 @@ func TestTwitToAMP(t *testing.T) {
 		},
 		{
 			`<blockquote class="twitter-tweet"><p lang="ru" dir="ltr">Андрей Сошенко. Когда рванет второй Чернобыль? <br>Рано или поздно, но на Украине обязательно сотворят глобальную катастрофу <a href="https://t.co/EQGPtpvxVF">https://t.co/EQGPtpvxVF</a> <a href="https://t.co/WBIrRCAvZq">pic.twitter.com/WBIrRCAvZq</a></p>&mdash; газета Завтра (@ZavtraRu) <a href="https://twitter.com/ZavtraRu/status/?ref_src=twsrc%5Etfw">January 9, 2020</a></blockquote> <script async src="https://platform.twitter.com/widgets.js" charset="utf-8"></script>`,
-			`no twitter ID in the url`,
+			`twitter: no source of embed`,
+		},
+		{
+			`<blockquote class="twitter-tweet"><p lang="ru" dir="ltr">Текст</p>&mdash; RG (@rgru2024) <a href="https://x.com/rgru2024/status/1795012345678901234?ref_src=twsrc%5Etfw">May 27, 2024</a></blockquote>`,
+			`<amp-twitter layout="responsive" height="480" width="380" data-tweetid="1795012345678901234"></amp-twitter>`,
+		},
+		{
+			`<blockquote class="twitter-tweet"><a href="https://mobile.twitter.com/i/web/status/1211912897590202368">December 31, 2019</a></blockquote>`,
+			`<amp-twitter layout="responsive" height="480" width="380" data-tweetid="1211912897590202368"></amp-twitter>`,
+		},
+		{
+			`<a class="twitter-timeline" data-height="600" href="https://twitter.com/rg_ru?ref_src=twsrc%5Etfw">Tweets by rg_ru</a> <script async src="https://platform.twitter.com/widgets.js" charset="utf-8"></script>`,
+			`<amp-twitter layout="responsive" height="600" width="375" data-timeline-source-type="profile" data-timeline-screen-name="rg_ru"></amp-twitter>`,
+		},
+		{
+			`<a class="twitter-timeline" href="https://x.com/rg_ru/likes">Likes by rg_ru</a>`,
+			`<amp-twitter layout="responsive" height="472" width="375" data-timeline-source-type="likes" data-timeline-screen-name="rg_ru"></amp-twitter>`,
+		},
+		{
+			`<a class="twitter-timeline" data-width="500" data-height="700" href="https://twitter.com/rg_ru/lists/news-2024">A list</a>`,
+			`<amp-twitter layout="responsive" height="700" width="500" data-timeline-source-type="list" data-timeline-owner-screen-name="rg_ru" data-timeline-slug="news-2024"></amp-twitter>`,
+		},
+		{
+			`<a class="twitter-timeline" href="https://x.com/i/lists/1585430245762441216">A list</a>`,
+			`<amp-twitter layout="responsive" height="472" width="375" data-timeline-source-type="list" data-timeline-list-id="1585430245762441216"></amp-twitter>`,
+		},
+		{
+			`<a class="twitter-timeline" href="https://x.com/rg_ru/media">Media</a>`,
+			`twitter: malformed url: https://x.com/rg_ru/media`,
+		},
+		{
+			`<a class="twitter-timeline" href="https://x.com.example.com/rg_ru">Tweets</a>`,
+			`twitter: wrong host: x.com.example.com`,
 		},
 	}
 
@@ -618,6 +823,18 @@ func TestInstaToAMP(t *testing.T) {
 			`<blockquote class="instagram-media" data-instgrm-permalink="https://www.instagram.com/p/B6n1kfKoLmr/?utm_source=ig_embed&amp;utm_campaign=loading" data-instgrm-version="12" style=" background:#FFF; border:0; border-radius:3px; box-shadow:0 0 1px 0 rgba(0,0,0,0.5),0 1px 10px 0 rgba(0,0,0,0.15); margin: 1px; max-width:540px; min-width:326px; padding:0; width:99.375%; width:-webkit-calc(100% - 2px); width:calc(100% - 2px);"><div style="padding:16px;"> <a href="https://www.instagram.com/p/B6n1kfKoLmr/?utm_source=ig_embed&amp;utm_campaign=loading" style=" background:#FFFFFF; line-height:0; padding:0 0; text-align:center; text-decoration:none; width:100%;" target="_blank"> <div style=" display: flex; flex-direction: row; align-items: center;"> <div style="background-color: #F4F4F4; border-radius: 50%; flex-grow: 0; height: 40px; margin-right: 14px; width: 40px;"></div> <div style="display: flex; flex-direction: column; flex-grow: 1; justify-content: center;"> <div style=" background-color: #F4F4F4; border-radius: 4px; flex-grow: 0; height: 14px; margin-bottom: 6px; width: 100px;"></div> <div style=" background-color: #F4F4F4; border-radius: 4px; flex-grow: 0; height: 14px; width: 60px;"></div></div></div><div style="padding: 19% 0;"></div> <div style="display:block; height:50px; margin:0 auto 12px; width:50px;"><svg width="50px" height="50px" viewBox="0 0 60 60" version="1.1" xmlns="https://www.w3.org/2000/svg" xmlns:xlink="https://www.w3.org/1999/xlink"><g stroke="none" stroke-width="1" fill="none" fill-rule="evenodd"><g transform="translate(-511.000000, -20.000000)" fill="#000000"><g><path d="M556.869,30.41 C554.814,30.41 553.148,32.076 553.148,34.131 C553.148,36.186 554.814,37.852 556.869,37.852 C558.924,37.852 560.59,36.186 560.59,34.131 C560.59,32.076 558.924,30.41 556.869,30.41 M541,60.657 C535.114,60.657 530.342,55.887 530.342,50 C530.342,44.114 535.114,39.342 541,39.342 C546.887,39.342 551.658,44.114 551.658,50 C551.658,55.887 546.887,60.657 541,60.657 M541,33.886 C532.1,33.886 524.886,41.1 524.886,50 C524.886,58.899 532.1,66.113 541,66.113 C549.9,66.113 557.115,58.899 557.115,50 C557.115,41.1 549.9,33.886 541,33.886 M565.378,62.101 C565.244,65.022 564.756,66.606 564.346,67.663 C563.803,69.06 563.154,70.057 562.106,71.106 C561.058,72.155 560.06,72.803 558.662,73.347 C557.607,73.757 556.021,74.244 553.102,74.378 C549.944,74.521 548.997,74.552 541,74.552 C533.003,74.552 532.056,74.521 528.898,74.378 C525.979,74.244 524.393,73.757 523.338,73.347 C521.94,72.803 520.942,72.155 519.894,71.106 C518.846,70.057 518.197,69.06 517.654,67.663 C517.244,66.606 516.755,65.022 516.623,62.101 C516.479,58.943 516.448,57.996 516.448,50 C516.448,42.003 516.479,41.056 516.623,37.899 C516.755,34.978 517.244,33.391 517.654,32.338 C518.197,30.938 518.846,29.942 519.894,28.894 C520.942,27.846 521.94,27.196 523.338,26.654 C524.393,26.244 525.979,25.756 528.898,25.623 C532.057,25.479 533.004,25.448 541,25.448 C548.997,25.448 549.943,25.479 553.102,25.623 C556.021,25.756 557.607,26.244 558.662,26.654 C560.06,27.196 561.058,27.846 562.106,28.894 C563.154,29.942 563.803,30.938 564.346,32.338 C564.756,33.391 565.244,34.978 565.378,37.899 C565.522,41.056 565.552,42.003 565.552,50 C565.552,57.996 565.522,58.943 565.378,62.101 M570.82,37.631 C570.674,34.438 570.167,32.258 569.425,30.349 C568.659,28.377 567.633,26.702 565.965,25.035 C564.297,23.368 562.623,22.342 560.652,21.575 C558.743,20.834 556.562,20.326 553.369,20.18 C550.169,20.033 549.148,20 541,20 C532.853,20 531.831,20.033 528.631,20.18 C525.438,20.326 523.257,20.834 521.349,21.575 C519.376,22.342 517.703,23.368 516.035,25.035 C514.368,26.702 513.342,28.377 512.574,30.349 C511.834,32.258 511.326,34.438 511.181,37.631 C511.035,40.831 511,41.851 511,50 C511,58.147 511.035,59.17 511.181,62.369 C511.326,65.562 511.834,67.743 512.574,69.651 C513.342,71.625 514.368,73.296 516.035,74.965 C517.703,76.634 519.376,77.658 521.349,78.425 C523.257,79.167 525.438,79.673 528.631,79.82 C531.831,79.965 532.853,80.001 541,80.001 C549.148,80.001 550.169,79.965 553.369,79.82 C556.562,79.673 558.743,79.167 560.652,78.425 C562.623,77.658 564.297,76.634 565.965,74.965 C567.633,73.296 568.659,71.625 569.425,69.651 C570.167,67.743 570.674,65.562 570.82,62.369 C570.966,59.17 571,58.147 571,50 C571,41.851 570.966,40.831 570.82,37.631"></path></g></g></g></svg></div><div style="padding-top: 8px;"> <div style=" color:#3897f0; font-family:Arial,sans-serif; font-size:14px; font-style:normal; font-weight:550; line-height:18px;"> View this post on Instagram</div></div><div style="padding: 12.5% 0;"></div> <div style="display: flex; flex-direction: row; margin-bottom: 14px; align-items: center;"><div> <div style="background-color: #F4F4F4; border-radius: 50%; height: 12.5px; width: 12.5px; transform: translateX(0px) translateY(7px);"></div> <div style="background-color: #F4F4F4; height: 12.5px; transform: rotate(-45deg) translateX(3px) translateY(1px); width: 12.5px; flex-grow: 0; margin-right: 14px; margin-left: 2px;"></div> <div style="background-color: #F4F4F4; border-radius: 50%; height: 12.5px; width: 12.5px; transform: translateX(9px) translateY(-18px);"></div></div><div style="margin-left: 8px;"> <div style=" background-color: #F4F4F4; border-radius: 50%; flex-grow: 0; height: 20px; width: 20px;"></div> <div style=" width: 0; height: 0; border-top: 2px solid transparent; border-left: 6px solid #f4f4f4; border-bottom: 2px solid transparent; transform: translateX(16px) translateY(-4px) rotate(30deg)"></div></div><div style="margin-left: auto;"> <div style=" width: 0px; border-top: 8px solid #F4F4F4; border-right: 8px solid transparent; transform: translateY(16px);"></div> <div style=" background-color: #F4F4F4; flex-grow: 0; height: 12px; width: 16px; transform: translateY(-4px);"></div> <div style=" width: 0; height: 0; border-top: 8px solid #F4F4F4; border-left: 8px solid transparent; transform: translateY(-4px) translateX(8px);"></div></div></div> <div style="display: flex; flex-direction: column; flex-grow: 1; justify-content: center; margin-bottom: 24px;"> <div style=" background-color: #F4F4F4; border-radius: 4px; flex-grow: 0; height: 14px; margin-bottom: 6px; width: 224px;"></div> <div style=" background-color: #F4F4F4; border-radius: 4px; flex-grow: 0; height: 14px; width: 144px;"></div></div></a><p style=" color:#c9c8cd; font-family:Arial,sans-serif; font-size:14px; line-height:17px; margin-bottom:0; margin-top:8px; overflow:hidden; padding:8px 0 7px; text-align:center; text-overflow:ellipsis; white-space:nowrap;"><a href="https://www.instagram.com/p/B6n1kfKoLmr/?utm_source=ig_embed&amp;utm_campaign=loading" style=" color:#c9c8cd; font-family:Arial,sans-serif; font-size:14px; font-style:normal; font-weight:normal; line-height:17px; text-decoration:none;" target="_blank">A post shared by РВС - защита семьи 👨‍👩‍👧‍👦 (@rvs.news)</a> on <time style=" font-family:Arial,sans-serif; font-size:14px; line-height:17px;" datetime="2019-12-28T16:15:35+00:00">Dec 28, 2019 at 8:15am PST</time></p></div></blockquote> <script async src="//www.instagram.com/embed.js"></script>`,
 			`<amp-instagram layout="responsive" height="400" width="400" data-shortcode="B6n1kfKoLmr"></amp-instagram>`,
 		},
+		{
+			`<blockquote class="instagram-media" data-instgrm-permalink="https://www.instagram.com/reel/C7kQ2xNPxYz/?utm_source=ig_embed&amp;utm_campaign=loading" data-instgrm-version="14"></blockquote>`,
+			`<amp-instagram layout="responsive" height="400" width="400" data-shortcode="C7kQ2xNPxYz"></amp-instagram>`,
+		},
+		{
+			`<blockquote class="instagram-media" data-instgrm-permalink="https://www.instagram.com/tv/CAxR8f-HqV1" data-instgrm-version="14"></blockquote>`,
+			`<amp-instagram layout="responsive" height="400" width="400" data-shortcode="CAxR8f-HqV1"></amp-instagram>`,
+		},
+		{
+			`<blockquote class="instagram-media" data-instgrm-permalink="https://www.instagram.com/rgru/p/B6nHZAHl7JZ?igsh=MWQ1ZGUxMzBkMA==" data-instgrm-version="14"></blockquote>`,
+			`<amp-instagram layout="responsive" height="400" width="400" data-shortcode="B6nHZAHl7JZ"></amp-instagram>`,
+		},
 	}
 
 	for _, test := range tests {
@@ -734,6 +951,56 @@ func TestVkToAMPWrong(t *testing.T) {
 	}
 }
 
+func TestVkWidgetsToAMP(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{
+			`<div id="vk_post_-175249128_1156"></div><script type="text/javascript">VK.Widgets.Post('vk_post_-175249128_1156', -175249128, 1156, 'HmCFKRSM81NEzJ8mY9gzgXOlEFM');</script>`,
+			`<amp-vk height="300" width="500" data-embedtype="post" layout="responsive" data-owner-id="-175249128" data-post-id="1156" data-hash="HmCFKRSM81NEzJ8mY9gzgXOlEFM"></amp-vk>`,
+		},
+		{
+			`<script>VK.Widgets.Post( "vk_post_1_45616" ,  1 , 45616 , "ZMk4b98xpQZMJJRXVsL1ig" , { width : 500 } );</script>`,
+			`<amp-vk height="300" width="500" data-embedtype="post" layout="responsive" data-owner-id="1" data-post-id="45616" data-hash="ZMk4b98xpQZMJJRXVsL1ig"></amp-vk>`,
+		},
+		{
+			`<div id="vk_groups"></div><script type="text/javascript">VK.Widgets.Group("vk_groups", {mode: 3, no_cover: 1, height: "600"}, 24288133);</script>`,
+			`<amp-iframe width="480" height="600" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" src="https://vk.com/widget_community.php?gid=24288133&mode=3&no_cover=1"></amp-iframe>`,
+		},
+		{
+			`<div id="vk_playlist_-2000391107_11391107"></div><script type="text/javascript">VK.Widgets.Playlist("vk_playlist_-2000391107_11391107", -2000391107, 11391107, 'b1c1da6cdd5d65e2a7');</script>`,
+			`<amp-iframe width="480" height="400" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" src="https://vk.com/widget_playlist.php?hash=b1c1da6cdd5d65e2a7&oid=-2000391107&pid=11391107"></amp-iframe>`,
+		},
+		{
+			`<iframe src="https://vk.com/video_ext.php?oid=-22822305&id=456241864&hash=c0b9a5a7a1b9c4a3" width="640" height="360" frameborder="0" allowfullscreen></iframe>`,
+			`<amp-iframe width="640" height="360" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" allow="autoplay; encrypted-media; fullscreen; picture-in-picture" allowfullscreen src="https://vk.com/video_ext.php?oid=-22822305&id=456241864&hash=c0b9a5a7a1b9c4a3"></amp-iframe>`,
+		},
+		{
+			`<script>VK.Widgets.Post("vk_post_1_45616", 1, 45616, "bad hash!");</script>`,
+			`vkontakte: malformed embed: bad hash!`,
+		},
+		{
+			`<script>VK.Widgets.Group("vk_groups", {mode: 3}, 0);</script>`,
+			`vkontakte: malformed embed: 0`,
+		},
+		{
+			`<script>VK.Widgets.Group("vk_groups", {mode: 3}</script>`,
+			`vkontakte: malformed embed`,
+		},
+	}
+
+	for i, test := range tests {
+		got, err := VkToAMP([]byte(test.input))
+		if err != nil {
+			got = []byte(fmt.Sprint(err))
+		}
+		if string(got) != test.want {
+			t.Errorf("%d: VkToAMP() = %q, want %q", i, got, test.want)
+		}
+	}
+}
+
 func ExampleFbToAMP() {
 	html := `<iframe src="https://www.facebook.com/plugins/post.php?href=https%3A%2F%2Fwww.facebook.com%2Fstcnk%2Fposts%2F3384458724928901&width=500" width="500" height="498" style="border:none;overflow:hidden" scrolling="no" frameborder="0" allowTransparency="true" allow="encrypted-media"></iframe>`
 	amp, err := FbToAMP([]byte(html))
@@ -757,3 +1024,2106 @@ func ExampleVkToAMP() {
 	}
 	fmt.Printf("AMPfied: %s", amp)
 }
+
+type widgetProvider struct{}
+
+func (widgetProvider) Name() string { return `widget` }
+
+func (widgetProvider) Detect(htmlText []byte) bool {
+	return strings.Contains(string(htmlText), `class="rg-widget"`)
+}
+
+func (p widgetProvider) Extract(htmlText []byte) (*Embed, error) {
+	return &Embed{Provider: p.Name(), Raw: htmlText}, nil
+}
+
+func (widgetProvider) AMP(embed *Embed) ([]byte, error) {
+	return []byte(`<amp-img src="https://rg.ru/widget.png" width="1" height="1"></amp-img>`), nil
+}
+
+func (widgetProvider) Turbo(embed *Embed) ([]byte, error) { return embed.Raw, nil }
+
+// AMP() and Turbo() go through the registry, but should give the same bytes
+// as converters of the providers which they replaced
+func TestRegistryOutput(t *testing.T) {
+	var tests = []struct {
+		input string
+		amp   func([]byte) ([]byte, error)
+		turbo func([]byte) ([]byte, error)
+	}{
+		{
+			`<script>VK.Widgets.Post("vk_post_-175249128_1156", -175249128, 1156, 'HmCFKRSM81NEzJ8mY9gzgXOlEFM');</script>`,
+			VkToAMP, VkToTurbo,
+		},
+		{
+			`<iframe src="https://www.facebook.com/plugins/post.php?href=https%3A%2F%2Fwww.facebook.com%2Fstcnk%2Fposts%2F3384458724928901&width=500" width="500" height="498"></iframe>`,
+			FbToAMP, FbToTurbo,
+		},
+		{
+			`<blockquote class="instagram-media" data-instgrm-permalink="https://www.instagram.com/p/B6nHZAHl7JZ/"></blockquote>`,
+			InstaToAMP, InstaToTurbo,
+		},
+		{
+			`<blockquote class="twitter-tweet"><a href="https://twitter.com/WIONews/status/1211912897590202368">December 31, 2019</a></blockquote>`,
+			TwitToAMP, TwitToTurbo,
+		},
+		{
+			`<iframe width="560" height="315" src="https://www.youtube.com/embed/05klG-PTKqo" frameborder="0" allowfullscreen></iframe>`,
+			YoutubeToAMP, YoutubeToTurbo,
+		},
+		{
+			`<iframe src="https://russian.rt.com/world/video/706283" frameborder="0" allowfullscreen></iframe>`,
+			IframeToAMP, IframeToTurbo,
+		},
+	}
+
+	for i, test := range tests {
+		want, err := test.amp([]byte(test.input))
+		if err != nil {
+			t.Fatalf("\n[%d] ERROR: %q", i+1, err)
+		}
+		if got, _, err := AMP([]byte(test.input)); err != nil || string(got) != string(want) {
+			t.Errorf("\n[%d]AMP() = %q, %v,\nwant        %q\n", i+1, got, err, want)
+		}
+
+		want, err = test.turbo([]byte(test.input))
+		if err != nil {
+			t.Fatalf("\n[%d] ERROR: %q", i+1, err)
+		}
+		if got, _, err := Turbo([]byte(test.input)); err != nil || string(got) != string(want) {
+			t.Errorf("\n[%d]Turbo() = %q, %v,\nwant        %q\n", i+1, got, err, want)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register(widgetProvider{})
+
+	providers := Providers()
+	if got := providers[len(providers)-1].Name(); got != `iframe` {
+		t.Errorf("Providers() last = %q, want %q", got, `iframe`)
+	}
+
+	input := `<div class="rg-widget"><iframe src="https://rg.ru/widget"></iframe></div>`
+	got, social, err := AMP([]byte(input))
+	if err != nil {
+		t.Fatalf("AMP() ERROR: %q", err)
+	}
+	if social != `widget` {
+		t.Errorf("AMP() = %q, want %q", social, `widget`)
+	}
+	if want := `<amp-img src="https://rg.ru/widget.png" width="1" height="1"></amp-img>`; string(got) != want {
+		t.Errorf("AMP() = %q, want %q", got, want)
+	}
+
+	got, social, err = Turbo([]byte(input))
+	if err != nil {
+		t.Fatalf("Turbo() ERROR: %q", err)
+	}
+	if social != `widget` || string(got) != input {
+		t.Errorf("Turbo() = %q, %q, want %q, %q", got, social, input, `widget`)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Register() twice did not panic")
+		}
+	}()
+	Register(widgetProvider{})
+}
+
+func TestParse(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  Embed
+		amp   string
+	}{
+		{
+			`<div id="vk_post_-175249128_1156"></div>
+	<script type="text/javascript" src="https://vk.com/js/api/openapi.js?162"></script>
+	<script type="text/javascript">
+		(function() {
+		VK.Widgets.Post("vk_post_-175249128_1156", -175249128, 1156, 'HmCFKRSM81NEzJ8mY9gzgXOlEFM', {width: 500});
+	}());
+	</script>`,
+			Embed{Provider: `vkontakte`, URL: `https://vk.com/wall-175249128_1156`, ID: `1156`, OwnerID: `-175249128`, Hash: `HmCFKRSM81NEzJ8mY9gzgXOlEFM`, Width: 500, Elements: []string{`amp-vk`}},
+			`<amp-vk height="300" width="500" data-embedtype="post" layout="responsive" data-owner-id="-175249128" data-post-id="1156" data-hash="HmCFKRSM81NEzJ8mY9gzgXOlEFM"></amp-vk>`,
+		},
+		{
+			`<div id="vk_groups"></div><script type="text/javascript">VK.Widgets.Group("vk_groups", {mode: 3, height: 600}, 24288133);</script>`,
+			Embed{Provider: `vkontakte`, URL: `https://vk.com/club24288133`, ID: `24288133`, Kind: `group`, Height: 600, Params: map[string]string{`mode`: `3`}, Elements: []string{`amp-iframe`}},
+			`<amp-iframe width="480" height="600" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" src="https://vk.com/widget_community.php?gid=24288133&mode=3"></amp-iframe>`,
+		},
+		{
+			`<iframe src="https://www.facebook.com/plugins/video.php?href=https%3A%2F%2Fwww.facebook.com%2Fnasaearth%2Fvideos%2F456540998570328%2F&show_text=0&width=560" width="560" height="373" style="border:none;overflow:hidden" scrolling="no" frameborder="0" allowTransparency="true" allowFullScreen="true"></iframe>`,
+			Embed{Provider: `facebook`, URL: `https://www.facebook.com/nasaearth/videos/456540998570328/`, Width: 560, Height: 373, Video: true, Media: `video`, Elements: []string{`amp-facebook`}},
+			`<amp-facebook height="373" width="560" layout="responsive" data-embed-as="video" data-href="https://www.facebook.com/nasaearth/videos/456540998570328/"></amp-facebook>`,
+		},
+		{
+			`<blockquote class="instagram-media" data-instgrm-captioned data-instgrm-permalink="https://www.instagram.com/p/B6nHZAHl7JZ/?utm_source=ig_embed&amp;utm_campaign=loading" data-instgrm-version="12"></blockquote>`,
+			Embed{Provider: `instagram`, URL: `https://www.instagram.com/p/B6nHZAHl7JZ/`, ID: `B6nHZAHl7JZ`, Kind: `post`, Captioned: true, Elements: []string{`amp-instagram`}},
+			`<amp-instagram layout="responsive" height="400" width="400" data-captioned data-shortcode="B6nHZAHl7JZ"></amp-instagram>`,
+		},
+		{
+			`<blockquote class="instagram-media" data-instgrm-permalink="https://www.instagram.com/reel/C7kQ2xNPxYz?utm_source=ig_embed" data-instgrm-version="14"></blockquote>`,
+			Embed{Provider: `instagram`, URL: `https://www.instagram.com/reel/C7kQ2xNPxYz/`, ID: `C7kQ2xNPxYz`, Kind: `reel`, Elements: []string{`amp-instagram`}},
+			`<amp-instagram layout="responsive" height="400" width="400" data-shortcode="C7kQ2xNPxYz"></amp-instagram>`,
+		},
+		{
+			`<blockquote class="twitter-tweet"><p lang="en" dir="ltr">text</p>&mdash; WION (@WIONews) <a href="https://twitter.com/WIONews/status/1211912897590202368?ref_src=twsrc%5Etfw">December 31, 2019</a></blockquote>`,
+			Embed{Provider: `twitter`, URL: `https://twitter.com/WIONews/status/1211912897590202368`, ID: `1211912897590202368`, Elements: []string{`amp-twitter`}},
+			`<amp-twitter layout="responsive" height="480" width="380" data-tweetid="1211912897590202368"></amp-twitter>`,
+		},
+		{
+			`<a class="twitter-timeline" href="https://twitter.com/rg_ru/lists/news-2024">A list</a>`,
+			Embed{Provider: `twitter`, URL: `https://twitter.com/rg_ru/lists/news-2024`, ID: `news-2024`, OwnerID: `rg_ru`, Kind: `list`, Elements: []string{`amp-twitter`}},
+			`<amp-twitter layout="responsive" height="472" width="375" data-timeline-source-type="list" data-timeline-owner-screen-name="rg_ru" data-timeline-slug="news-2024"></amp-twitter>`,
+		},
+		{
+			`<iframe width="560" height="315" src="https://www.youtube.com/embed/05klG-PTKqo" frameborder="0" allowfullscreen></iframe>`,
+			Embed{Provider: `youtube`, URL: `https://www.youtube.com/watch?v=05klG-PTKqo`, ID: `05klG-PTKqo`, Width: 560, Height: 315, AllowFullscreen: true, Video: true, Media: `video`, Elements: []string{`amp-youtube`}},
+			`<amp-youtube layout="responsive" height="315" width="560" data-videoid="05klG-PTKqo"></amp-youtube>`,
+		},
+		{
+			`<iframe src="https://www.youtube-nocookie.com/embed/videoseries?list=PLx0sYbCqOb8TBPRdmBHs5Iftvv9TPboYG&amp;start=10"></iframe>`,
+			Embed{Provider: `youtube`, URL: `https://www.youtube.com/playlist?list=PLx0sYbCqOb8TBPRdmBHs5Iftvv9TPboYG`, ID: `PLx0sYbCqOb8TBPRdmBHs5Iftvv9TPboYG`, Kind: `playlist`, Video: true, Media: `video`, NoCookie: true, Params: map[string]string{"list": "PLx0sYbCqOb8TBPRdmBHs5Iftvv9TPboYG", "start": "10"}, Elements: []string{`amp-youtube`}},
+			`<amp-youtube layout="responsive" height="315" width="480" credentials="omit" data-param-list="PLx0sYbCqOb8TBPRdmBHs5Iftvv9TPboYG" data-param-start="10" data-videoid="videoseries"></amp-youtube>`,
+		},
+		{
+			`<iframe src="https://www.dailymotion.com/embed/video/x7tgad0?autoplay=1&mute=1" width="480" height="270"></iframe>`,
+			Embed{Provider: `dailymotion`, URL: `https://www.dailymotion.com/video/x7tgad0`, ID: `x7tgad0`, Width: 480, Height: 270, Video: true, Media: `video`, Params: map[string]string{"autoplay": "1", "mute": "1"}, Elements: []string{`amp-dailymotion`}},
+			`<amp-dailymotion layout="responsive" height="270" width="480" autoplay data-mute="1" data-videoid="x7tgad0"></amp-dailymotion>`,
+		}, {
+			`<blockquote class="reddit-embed-bq" data-embed-height="240"><a href="https://www.reddit.com/r/aww/comments/15l2uzq/comment/jvaxm3k/">Comment</a></blockquote>`,
+			Embed{Provider: `reddit`, URL: `https://www.reddit.com/r/aww/comments/15l2uzq/comment/jvaxm3k/`, ID: `jvaxm3k`, OwnerID: `aww`, Kind: `comment`, Height: 240, Elements: []string{`amp-reddit`}},
+			`<amp-reddit layout="responsive" height="240" width="300" data-embedtype="comment" data-src="https://www.reddit.com/r/aww/comments/15l2uzq/comment/jvaxm3k/"></amp-reddit>`,
+		}, {
+			`<iframe height="450" src="https://w.soundcloud.com/player/?url=https%3A//api.soundcloud.com/playlists/1234%3Fsecret_token%3Ds-AbCdE&visual=true"></iframe>`,
+			Embed{Provider: `soundcloud`, URL: `https://api.soundcloud.com/playlists/1234`, ID: `1234`, Hash: `s-AbCdE`, Kind: `playlist`, Height: 450, Media: `audio`, Params: map[string]string{"color": "", "visual": "true"}, Elements: []string{`amp-soundcloud`}},
+			`<amp-soundcloud height="450" layout="fixed-height" data-playlistid="1234" data-secret-token="s-AbCdE" data-visual="true"></amp-soundcloud>`,
+		},
+		{
+			`<iframe allow="autoplay *; encrypted-media *; fullscreen *; clipboard-write" frameborder="0" height="175" style="width:100%;max-width:660px;overflow:hidden;border-radius:10px;" sandbox="allow-forms allow-popups allow-same-origin allow-scripts allow-storage-access-by-user-activation allow-top-navigation-by-user-activation" src="https://embed.podcasts.apple.com/us/podcast/the-daily/id1200361736?i=1000650123456"></iframe>`,
+			Embed{Provider: `applepodcasts`, URL: `https://podcasts.apple.com/us/podcast/the-daily/id1200361736?i=1000650123456`, ID: `1000650123456`, OwnerID: `1200361736`, Kind: `episode`, Height: 175, Media: `audio`, Elements: []string{`amp-iframe`}},
+			`<amp-iframe height="175" layout="fixed-height" sandbox="allow-scripts allow-same-origin allow-popups" frameborder="0" allow="autoplay" src="https://embed.podcasts.apple.com/us/podcast/the-daily/id1200361736?i=1000650123456"></amp-iframe>`,
+		}, {
+			`<iframe src="https://mastodon.social/@Gargron@mastodon.social/113457432853185130/embed" width="400" height="500"></iframe>`,
+			Embed{Provider: `mastodon`, URL: `https://mastodon.social/@Gargron@mastodon.social/113457432853185130`, ID: `113457432853185130`, OwnerID: `Gargron@mastodon.social`, Width: 400, Height: 500, Elements: []string{`amp-iframe`}},
+			`<amp-iframe width="400" height="500" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" src="https://mastodon.social/@Gargron@mastodon.social/113457432853185130/embed"></amp-iframe>`,
+		},
+		{
+			`<iframe width="560" height="315" src="//ok.ru/videoembed/2478134036998" frameborder="0" allow="autoplay" allowfullscreen></iframe>`,
+			Embed{Provider: `odnoklassniki`, URL: `https://ok.ru/video/2478134036998`, ID: `2478134036998`, Kind: `video`, Width: 560, Height: 315, Video: true, Media: `video`, Elements: []string{`amp-iframe`}},
+			`<amp-iframe width="560" height="315" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" allow="autoplay; encrypted-media; fullscreen; picture-in-picture" allowfullscreen src="https://ok.ru/videoembed/2478134036998"></amp-iframe>`,
+		},
+		{
+			`<iframe src="https://datawrapper.dwcdn.net/k4Qe7/3/" scrolling="no" frameborder="0" height="447"></iframe>`,
+			Embed{Provider: `datawrapper`, URL: `https://datawrapper.dwcdn.net/k4Qe7/3/`, ID: `k4Qe7`, Height: 447, Elements: []string{`amp-iframe`}},
+			`<amp-iframe height="447" layout="fixed-height" sandbox="allow-scripts allow-same-origin allow-popups" resizable frameborder="0" src="https://datawrapper.dwcdn.net/k4Qe7/3/"><div overflow tabindex="0" role="button" aria-label="Show more">Show more</div></amp-iframe>`,
+		},
+	}
+
+	for i, test := range tests {
+		got, err := Parse([]byte(test.input))
+		if err != nil {
+			t.Errorf("\n[%d]Parse() ERROR: %q", i+1, err)
+			continue
+		}
+		if string(got.Raw) != test.input {
+			t.Errorf("\n[%d]Parse().Raw = %q,\nwant        %q\n", i+1, got.Raw, test.input)
+		}
+
+		got.Raw = nil
+		if !reflect.DeepEqual(*got, test.want) {
+			t.Errorf("\n[%d]Parse() = %+v,\nwant        %+v\n", i+1, *got, test.want)
+		}
+
+		// stored embed should be rendered without original html
+		amp, err := got.AMP()
+		if err != nil {
+			t.Errorf("\n[%d]Embed.AMP() ERROR: %q", i+1, err)
+		}
+		if string(amp) != test.amp {
+			t.Errorf("\n[%d]Embed.AMP() = %q,\nwant        %q\n", i+1, amp, test.amp)
+		}
+	}
+
+	if _, err := Parse([]byte(`<p>just a text</p>`)); err == nil {
+		t.Errorf("Parse() of text: want err, got result")
+	}
+}
+
+func TestEmbedElements(t *testing.T) {
+	// stored embed gets its elements when it is rendered
+	embed := &Embed{Provider: `youtube`, ID: `05klG-PTKqo`, Width: 560, Height: 315}
+	if _, err := embed.AMP(); err != nil {
+		t.Fatalf("Embed.AMP() ERROR: %q", err)
+	}
+	if want := []string{`amp-youtube`}; !reflect.DeepEqual(embed.Elements, want) {
+		t.Errorf("Embed.AMP() elements = %q, want %q", embed.Elements, want)
+	}
+
+	article, err := ArticleToAMP([]byte(`<p>Text</p><iframe src="https://player.vimeo.com/video/76979871"></iframe><img src="/a.jpg">`))
+	if err != nil {
+		t.Fatalf("ArticleToAMP() ERROR: %q", err)
+	}
+	if len(article.Embeds) != 1 || !reflect.DeepEqual(article.Embeds[0].Elements, []string{`amp-vimeo`}) {
+		t.Errorf("ArticleToAMP() embeds = %+v, want amp-vimeo elements", article.Embeds)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	var tests = []struct {
+		input    string
+		convert  func([]byte) ([]byte, error)
+		kind     error
+		provider string
+		value    string
+	}{
+		{
+			`<iframe src="http://russian.rt.com/world/video/706283-posol-iran-oon-ssha-suleimani/video/5e184bbf02e8bd3f073eebeb"></iframe>`,
+			IframeToAMP, ErrInsecureScheme, `iframe`, `http://russian.rt.com/world/video/706283-posol-iran-oon-ssha-suleimani/video/5e184bbf02e8bd3f073eebeb`,
+		},
+		{
+			`<iframe src="http://russian.rt.com/world/video/706283-posol-iran-oon-ssha-suleimani/video/5e184bbf02e8bd3f073eebeb"></iframe>`,
+			IframeToTurbo, ErrInsecureScheme, `iframe`, `http://russian.rt.com/world/video/706283-posol-iran-oon-ssha-suleimani/video/5e184bbf02e8bd3f073eebeb`,
+		},
+		{
+			`<iframe src="https://www.youtu.be/embed/05klG-PTKqo"></iframe>`,
+			YoutubeToTurbo, ErrWrongHost, `youtube`, `www.youtu.be`,
+		},
+		{
+			`<iframe src="https://www.facebook.com/plugins/post.php?href=x" width="500"></iframe>`,
+			YoutubeToAMP, ErrWrongHost, `youtube`, `www.facebook.com`,
+		},
+		{
+			`<blockquote class="instagram-media" data-instgrm-permalink="https://www.instagram.com/stories/B6nHZAHl7JZ"></blockquote>`,
+			InstaToAMP, ErrMalformedURL, `instagram`, `https://www.instagram.com/stories/B6nHZAHl7JZ`,
+		},
+		{
+			`<div class="playbuzz">&nbsp;</div>`,
+			PlaybuzzToAMP, ErrMalformedEmbed, `playbuzz`, ``,
+		},
+	}
+
+	for i, test := range tests {
+		_, err := test.convert([]byte(test.input))
+		if !errors.Is(err, test.kind) {
+			t.Errorf("\n[%d] error = %v, want %v", i+1, err, test.kind)
+			continue
+		}
+
+		var embedErr *EmbedError
+		if !errors.As(err, &embedErr) {
+			t.Errorf("\n[%d] error = %T, want *EmbedError", i+1, err)
+			continue
+		}
+		if embedErr.Provider != test.provider || embedErr.Value != test.value {
+			t.Errorf("\n[%d] error = %q %q, want %q %q", i+1, embedErr.Provider, embedErr.Value, test.provider, test.value)
+		}
+	}
+
+	if _, _, err := AMP([]byte(`<p>just a text</p>`)); !errors.Is(err, ErrUnknownEmbed) {
+		t.Errorf("AMP() error = %v, want %v", err, ErrUnknownEmbed)
+	}
+	if _, _, err := Turbo([]byte(`<div class="playbuzz" data-id="001c4920">&nbsp;</div>`)); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("Turbo() error = %v, want %v", err, ErrUnsupported)
+	}
+
+	// errors of detected embeds are kept by AMP() and Turbo()
+	var entries = []struct {
+		convert  func([]byte) ([]byte, string, error)
+		input    string
+		kind     error
+		provider string
+		value    string
+	}{
+		{AMP, `<iframe src="http://example.com/x"></iframe>`, ErrInsecureScheme, `iframe`, `http://example.com/x`},
+		{Turbo, `<iframe src="http://example.com/x"></iframe>`, ErrInsecureScheme, `iframe`, `http://example.com/x`},
+		{AMP, `<blockquote class="tiktok-embed" cite="https://www.tok.com/@rgru_official/video/7021366529212419330"></blockquote>`, ErrWrongHost, `tiktok`, `www.tok.com`},
+		{Turbo, `<blockquote class="tiktok-embed" cite="https://www.tok.com/@rgru_official/video/7021366529212419330"></blockquote>`, ErrWrongHost, `tiktok`, `www.tok.com`},
+	}
+
+	for i, entry := range entries {
+		_, _, err := entry.convert([]byte(entry.input))
+		var embedErr *EmbedError
+		if !errors.As(err, &embedErr) || !errors.Is(err, entry.kind) {
+			t.Errorf("\n[%d] error = %v, want *EmbedError %v", i+1, err, entry.kind)
+			continue
+		}
+		if embedErr.Provider != entry.provider || embedErr.Value != entry.value {
+			t.Errorf("\n[%d] error = %q %q, want %q %q", i+1, embedErr.Provider, embedErr.Value, entry.provider, entry.value)
+		}
+	}
+}
+
+func TestNoFallback(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{
+			`<iframe src="https://rutube.ru/play/embed/zzz"></iframe>`,
+			`rutube: malformed url: https://rutube.ru/play/embed/zzz`,
+		},
+		{
+			`<iframe src="https://vk.com/video_ext.php?oid=abc&id=x"></iframe>`,
+			`vkvideo: malformed embed: abc`,
+		},
+		{
+			`<iframe src="https://ok.ru/videoembed/abc"></iframe>`,
+			`odnoklassniki: malformed url: https://ok.ru/videoembed/abc`,
+		},
+		{
+			`<iframe src="https://ok.ru/videoembed/0"></iframe>`,
+			`odnoklassniki: malformed embed: 0`,
+		},
+	}
+
+	for i, test := range tests {
+		if got, _, err := AMP([]byte(test.input)); fmt.Sprint(err) != test.want {
+			t.Errorf("\n[%d]AMP() = %q, %v,\nwant ERR    %q\n", i+1, got, err, test.want)
+		}
+		if embed, err := Parse([]byte(test.input)); fmt.Sprint(err) != test.want {
+			t.Errorf("\n[%d]Parse() = %+v, %v,\nwant ERR    %q\n", i+1, embed, err, test.want)
+		}
+
+		article, err := ArticleToAMP([]byte(`<p>Text</p>` + test.input))
+		if err != nil {
+			t.Fatalf("ArticleToAMP() ERROR: %q", err)
+		}
+		if string(article.Body) != `<p>Text</p>` || len(article.Failures) != 1 || fmt.Sprint(article.Failures[0]) != test.want {
+			t.Errorf("\n[%d]ArticleToAMP() = %q, failures %q,\nwant ERR    %q\n", i+1, article.Body, article.Failures, test.want)
+		}
+	}
+}
+
+// iframes of other hosts which just mention social networks in url are ordinary iframes
+func TestLooseDetect(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{
+			`<iframe src="https://about.me/x/embed"></iframe>`,
+			`<amp-iframe width="480" height="315" sandbox="allow-scripts allow-same-origin" layout="responsive" frameborder="0" src="https://about.me/x/embed"></amp-iframe>`,
+		},
+		{
+			`<iframe src="https://example.com/player?from=youtube.com"></iframe>`,
+			`<amp-iframe width="480" height="315" sandbox="allow-scripts allow-same-origin" layout="responsive" frameborder="0" src="https://example.com/player?from=youtube.com"></amp-iframe>`,
+		},
+		{
+			`<iframe src="https://example.com/share?u=facebook.com"></iframe>`,
+			`<amp-iframe width="480" height="315" sandbox="allow-scripts allow-same-origin" layout="responsive" frameborder="0" src="https://example.com/share?u=facebook.com"></amp-iframe>`,
+		},
+		{
+			`<iframe src="http://example.com/share?u=https://t.me/rgru"></iframe>`,
+			`iframe: insecure scheme: http://example.com/share?u=https://t.me/rgru`,
+		},
+	}
+
+	for i, test := range tests {
+		got, social, err := AMP([]byte(test.input))
+		if err != nil {
+			got = []byte(fmt.Sprint(err))
+		}
+		if string(got) != test.want {
+			t.Errorf("\n[%d]AMP() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+		if err != nil {
+			continue
+		}
+		if social != `iframe` {
+			t.Errorf("\n[%d]AMP() = %q, want %q", i+1, social, `iframe`)
+		}
+		if embed, err := Parse([]byte(test.input)); err != nil || embed.Provider != `iframe` {
+			t.Errorf("\n[%d]Parse() = %+v, %v, want iframe", i+1, embed, err)
+		}
+
+		article, err := ArticleToAMP([]byte(test.input))
+		if err != nil {
+			t.Fatalf("ArticleToAMP() ERROR: %q", err)
+		}
+		if string(article.Body) != test.want || len(article.Failures) > 0 {
+			t.Errorf("\n[%d]ArticleToAMP() = %q, failures %q,\nwant        %q\n", i+1, article.Body, article.Failures, test.want)
+		}
+	}
+}
+
+func TestArticleToAMP(t *testing.T) {
+	input := `<p>Hello <b>world</b></p>
+<div id="vk_post_-175249128_1156"></div>
+<script type="text/javascript" src="https://vk.com/js/api/openapi.js?162"></script>
+<script type="text/javascript">
+	(function() {
+	VK.Widgets.Post("vk_post_-175249128_1156", -175249128, 1156, 'HmCFKRSM81NEzJ8mY9gzgXOlEFM');
+}());
+</script>
+<p><img src="/a.jpg"></p>
+<blockquote class="instagram-media" data-instgrm-permalink="https://www.instagram.com/p/B6nHZAHl7JZ/?utm_source=ig_embed" data-instgrm-version="12"><div>Post</div></blockquote><script async src="//www.instagram.com/embed.js"></script>
+<blockquote><p>Just a quote</p></blockquote>
+<iframe src="http://example.com/x"></iframe>
+<script>alert(1)</script>`
+
+	want := `<p>Hello <b>world</b></p>
+<amp-vk height="300" width="500" data-embedtype="post" layout="responsive" data-owner-id="-175249128" data-post-id="1156" data-hash="HmCFKRSM81NEzJ8mY9gzgXOlEFM"></amp-vk>
+
+
+<p><img src="/a.jpg"/></p>
+<amp-instagram layout="responsive" height="400" width="400" data-shortcode="B6nHZAHl7JZ"></amp-instagram>
+<blockquote><p>Just a quote</p></blockquote>
+
+`
+
+	got, err := ArticleToAMP([]byte(input))
+	if err != nil {
+		t.Fatalf("ArticleToAMP() ERROR: %q", err)
+	}
+	if string(got.Body) != want {
+		t.Errorf("\nArticleToAMP() = %q,\nwant        %q\n", got.Body, want)
+	}
+
+	if len(got.Embeds) != 2 || got.Embeds[0].Provider != `vkontakte` || got.Embeds[1].Provider != `instagram` {
+		t.Errorf("ArticleToAMP() embeds = %+v", got.Embeds)
+	}
+
+	if elements := []string{`amp-instagram`, `amp-vk`}; !reflect.DeepEqual(got.Elements, elements) {
+		t.Errorf("ArticleToAMP() elements = %q, want %q", got.Elements, elements)
+	}
+
+	if len(got.Failures) != 2 {
+		t.Fatalf("ArticleToAMP() failures = %q, want 2", got.Failures)
+	}
+	if !errors.Is(got.Failures[0], ErrInsecureScheme) {
+		t.Errorf("ArticleToAMP() failure = %q, want %q", got.Failures[0], ErrInsecureScheme)
+	}
+	if !errors.Is(got.Failures[1], ErrUnsupported) {
+		t.Errorf("ArticleToAMP() failure = %q, want %q", got.Failures[1], ErrUnsupported)
+	}
+}
+
+func TestArticleToAMPQuotes(t *testing.T) {
+	input := `<blockquote><p>Said on facebook.com yesterday</p></blockquote>
+<blockquote><p>Watch it on <a href="https://www.youtube.com/watch?v=05klG-PTKqo">youtube.com</a></p><iframe src="http://example.com/x"></iframe></blockquote>`
+
+	want := `<blockquote><p>Said on facebook.com yesterday</p></blockquote>
+<blockquote><p>Watch it on <a href="https://www.youtube.com/watch?v=05klG-PTKqo">youtube.com</a></p></blockquote>`
+
+	got, err := ArticleToAMP([]byte(input))
+	if err != nil {
+		t.Fatalf("ArticleToAMP() ERROR: %q", err)
+	}
+	if string(got.Body) != want {
+		t.Errorf("\nArticleToAMP() = %q,\nwant        %q\n", got.Body, want)
+	}
+
+	if len(got.Embeds) != 0 {
+		t.Errorf("ArticleToAMP() embeds = %+v, want none", got.Embeds)
+	}
+	if len(got.Failures) != 1 || !errors.Is(got.Failures[0], ErrInsecureScheme) {
+		t.Errorf("ArticleToAMP() failures = %q, want %q", got.Failures, ErrInsecureScheme)
+	}
+}
+
+func TestArticleToAMPVkGroup(t *testing.T) {
+	input := `<div id='vk_groups'></div>
+<script type="text/javascript">VK.Widgets.Group('vk_groups', {mode: 3, height: 600}, 24288133);</script>`
+
+	want := `<amp-iframe width="480" height="600" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" src="https://vk.com/widget_community.php?gid=24288133&amp;mode=3"></amp-iframe>
+`
+
+	got, err := ArticleToAMP([]byte(input))
+	if err != nil {
+		t.Fatalf("ArticleToAMP() ERROR: %q", err)
+	}
+	if string(got.Body) != want {
+		t.Errorf("\nArticleToAMP() = %q,\nwant        %q\n", got.Body, want)
+	}
+}
+
+// odnoklassniki topics have no AMP component and Turbo cannot run their script,
+// so both formats get a link to the topic in place of the widget placeholder
+func TestArticleOkTopic(t *testing.T) {
+	input := `<p>Text</p>
+<div id="ok_content_widget"></div>
+<script>
+!function (d, id, did, st) {
+  var js = d.createElement("script");
+  js.src = "https://connect.ok.ru/connect.js";
+  js.onload = function () {
+    OK.CONNECT.insertContentWidget(id,did,st);
+  };
+  d.documentElement.appendChild(js);
+}(document,"ok_content_widget","{\"topicId\":\"153789034467\",\"groupId\":\"52175189753888\"}","{\"width\":550}");
+</script>`
+
+	want := `<p>Text</p>
+<a href="https://ok.ru/group/52175189753888/topic/153789034467">https://ok.ru/group/52175189753888/topic/153789034467</a>
+`
+
+	for name, convert := range map[string]func([]byte) (*Article, error){`ArticleToAMP`: ArticleToAMP, `ArticleToTurbo`: ArticleToTurbo} {
+		got, err := convert([]byte(input))
+		if err != nil {
+			t.Fatalf("%s() ERROR: %q", name, err)
+		}
+		if string(got.Body) != want {
+			t.Errorf("\n%s() = %q,\nwant        %q\n", name, got.Body, want)
+		}
+		if len(got.Failures) != 0 {
+			t.Errorf("%s() failures = %q, want none", name, got.Failures)
+		}
+	}
+}
+
+func TestArticleToTurbo(t *testing.T) {
+	input := `<p>Hello</p>
+<div id="vk_post_-175249128_1156"></div>
+<script type="text/javascript" src="https://vk.com/js/api/openapi.js?162"></script>
+<script type="text/javascript">VK.Widgets.Post("vk_post_-175249128_1156", -175249128, 1156, 'HmCFKRSM81NEzJ8mY9gzgXOlEFM');</script>
+<iframe width="560" height="315" src="https://www.youtube.com/embed/05klG-PTKqo" frameborder="0" allowfullscreen></iframe>
+<blockquote class="instagram-media" data-instgrm-permalink="https://www.instagram.com/p/B6nHZAHl7JZ/"><div>Post</div></blockquote><script async src="//www.instagram.com/embed.js"></script>
+<div class="playbuzz" data-id="001c4920-5312-4d9a-9ecc-5b5dcf753381"></div><script src="https://embed.ex.co/sdk.js"></script>
+<iframe src="http://example.com/x"></iframe>`
+
+	want := `<p>Hello</p>
+<div id="vk_post_-175249128_1156"></div>
+<script type="text/javascript" src="https://vk.com/js/api/openapi.js?162"></script>
+<script type="text/javascript">VK.Widgets.Post("vk_post_-175249128_1156", -175249128, 1156, 'HmCFKRSM81NEzJ8mY9gzgXOlEFM');</script>
+<iframe width="560" height="315" allowfullscreen="true" frameborder="0" src="https://www.youtube.com/embed/05klG-PTKqo"></iframe>
+<blockquote class="instagram-media" data-instgrm-permalink="https://www.instagram.com/p/B6nHZAHl7JZ/"><div>Post</div></blockquote><script async="" src="//www.instagram.com/embed.js"></script>
+
+`
+
+	got, err := ArticleToTurbo([]byte(input))
+	if err != nil {
+		t.Fatalf("ArticleToTurbo() ERROR: %q", err)
+	}
+	if string(got.Body) != want {
+		t.Errorf("\nArticleToTurbo() = %q,\nwant        %q\n", got.Body, want)
+	}
+
+	if len(got.Embeds) != 3 {
+		t.Errorf("ArticleToTurbo() embeds = %+v, want 3", got.Embeds)
+	}
+
+	var kinds = []error{ErrUnsupported, ErrUnsupported, ErrInsecureScheme}
+	if len(got.Failures) != len(kinds) {
+		t.Fatalf("ArticleToTurbo() failures = %q, want %d", got.Failures, len(kinds))
+	}
+	for i, kind := range kinds {
+		if !errors.Is(got.Failures[i], kind) {
+			t.Errorf("ArticleToTurbo() failure = %q, want %q", got.Failures[i], kind)
+		}
+	}
+}
+
+func TestArticleToTurboQuotes(t *testing.T) {
+	input := `<blockquote><p>Seen on instagram.com and facebook.com</p></blockquote>
+<blockquote><p>Watch it on <a href="https://www.youtube.com/watch?v=05klG-PTKqo">youtube.com</a></p><iframe src="http://example.com/x"></iframe><script>alert(1)</script></blockquote>`
+
+	want := `<blockquote><p>Seen on instagram.com and facebook.com</p></blockquote>
+<blockquote><p>Watch it on <a href="https://www.youtube.com/watch?v=05klG-PTKqo">youtube.com</a></p></blockquote>`
+
+	got, err := ArticleToTurbo([]byte(input))
+	if err != nil {
+		t.Fatalf("ArticleToTurbo() ERROR: %q", err)
+	}
+	if string(got.Body) != want {
+		t.Errorf("\nArticleToTurbo() = %q,\nwant        %q\n", got.Body, want)
+	}
+
+	var kinds = []error{ErrInsecureScheme, ErrUnsupported}
+	if len(got.Failures) != len(kinds) {
+		t.Fatalf("ArticleToTurbo() failures = %q, want %d", got.Failures, len(kinds))
+	}
+	for i, kind := range kinds {
+		if !errors.Is(got.Failures[i], kind) {
+			t.Errorf("ArticleToTurbo() failure = %q, want %q", got.Failures[i], kind)
+		}
+	}
+
+	if diags := ValidateTurbo(got.Body); len(diags) > 0 {
+		t.Errorf("ValidateTurbo() = %v, want none", diags)
+	}
+}
+
+func TestScripts(t *testing.T) {
+	amp := `<amp-img src="/a.jpg" width="1" height="1"></amp-img><amp-youtube layout="responsive" height="315" width="560" data-videoid="05klG-PTKqo"></amp-youtube><amp-vk height="300" width="500" data-embedtype="post"></amp-vk><amp-youtube data-videoid="TVakXOkE2G4"></amp-youtube>`
+
+	elements := CustomElements([]byte(amp))
+	if want := []string{`amp-vk`, `amp-youtube`}; !reflect.DeepEqual(elements, want) {
+		t.Errorf("CustomElements() = %q, want %q", elements, want)
+	}
+
+	want := `<script async custom-element="amp-vk" src="https://cdn.ampproject.org/v0/amp-vk-0.1.js"></script>
+<script async custom-element="amp-youtube" src="https://cdn.ampproject.org/v0/amp-youtube-0.1.js"></script>`
+	if got := Scripts(elements, ""); string(got) != want {
+		t.Errorf("\nScripts() = %q,\nwant        %q\n", got, want)
+	}
+
+	want = `<script async custom-element="amp-vk" src="https://cdn.ampproject.org/v0/amp-vk-latest.js"></script>`
+	if got := Scripts([]string{`amp-vk`}, "latest"); string(got) != want {
+		t.Errorf("\nScripts() = %q,\nwant        %q\n", got, want)
+	}
+}
+
+func TestAMPPage(t *testing.T) {
+	body := `<p>Text</p><iframe width="560" height="315" src="https://www.youtube.com/embed/05klG-PTKqo" frameborder="0" allowfullscreen></iframe>`
+	meta := PageMeta{
+		Lang:          "ru",
+		Description:   `Putin & "others"`,
+		Published:     time.Date(2020, 1, 10, 12, 0, 0, 0, time.UTC),
+		Author:        "Ivan Ivanov",
+		Publisher:     "Rossiyskaya Gazeta",
+		PublisherLogo: "https://rg.ru/logo.png",
+	}
+
+	got, failures, err := AMPPage(`Title <1>`, `https://rg.ru/2020/01/10/news.html`, []byte(body), meta)
+	if err != nil {
+		t.Fatalf("AMPPage() ERROR: %q", err)
+	}
+	if len(failures) > 0 {
+		t.Errorf("AMPPage() failures = %q", failures)
+	}
+
+	for _, want := range []string{
+		"<!doctype html>\n<html ⚡ lang=\"ru\">",
+		`<meta charset="utf-8">`,
+		`<script async src="https://cdn.ampproject.org/v0.js"></script>`,
+		`<script async custom-element="amp-youtube" src="https://cdn.ampproject.org/v0/amp-youtube-0.1.js"></script>`,
+		`<title>Title &lt;1&gt;</title>`,
+		`<link rel="canonical" href="https://rg.ru/2020/01/10/news.html">`,
+		`<meta name="viewport" content="width=device-width">`,
+		`<meta name="description" content="Putin &amp; &#34;others&#34;">`,
+		`<style amp-boilerplate>`,
+		`"@type":"NewsArticle","mainEntityOfPage":"https://rg.ru/2020/01/10/news.html","headline":"Title \u003c1\u003e"`,
+		`"datePublished":"2020-01-10T12:00:00Z"`,
+		`"author":{"@type":"Person","name":"Ivan Ivanov"}`,
+		`"publisher":{"@type":"Organization","name":"Rossiyskaya Gazeta","logo":{"@type":"ImageObject","url":"https://rg.ru/logo.png"}}`,
+		`<body>
+<p>Text</p><amp-youtube layout="responsive" height="315" width="560" data-videoid="05klG-PTKqo"></amp-youtube>
+</body>`,
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("AMPPage() does not contain %q", want)
+		}
+	}
+}
+
+func TestAMPPageMedia(t *testing.T) {
+	body := `<p onclick="alert(1)">Text</p><p><img src="https://e.com/a.jpg" width="800" height="600" alt="A" loading="lazy"></p><img src="/b.jpg"><style>p{color:red}</style>` +
+		`<video src="https://e.com/v.mp4" controls><source src="https://e.com/v.webm" type="video/webm"></video><audio controls><source src="https://e.com/a.mp3"></audio><object data="x.swf"></object>`
+
+	want := `<p>Text</p><p><amp-img width="800" height="600" layout="responsive" src="https://e.com/a.jpg" alt="A"></amp-img></p><amp-img width="640" height="360" layout="responsive" src="/b.jpg"></amp-img>` +
+		`<amp-video width="640" height="360" layout="responsive" src="https://e.com/v.mp4" controls=""><source src="https://e.com/v.webm" type="video/webm"/></amp-video><amp-audio height="50" layout="fixed-height" controls=""><source src="https://e.com/a.mp3"/></amp-audio>`
+
+	page, failures, err := AMPPage(`Title`, `https://rg.ru/news.html`, []byte(body), PageMeta{})
+	if err != nil {
+		t.Fatalf("AMPPage() ERROR: %q", err)
+	}
+	if !strings.Contains(string(page), want) {
+		t.Errorf("\nAMPPage() = %s,\nwant body   %s\n", page, want)
+	}
+	if diagnostics := Validate(page); len(diagnostics) > 0 {
+		t.Errorf("Validate(AMPPage()) = %q", diagnostics)
+	}
+	if len(failures) != 2 || !errors.Is(failures[0], ErrUnsupported) || !errors.Is(failures[1], ErrUnsupported) {
+		t.Errorf("AMPPage() failures = %q, want style and object", failures)
+	}
+
+	// body converted by ArticleToAMP keeps ordinary images, the page should not
+	article, err := ArticleToAMP([]byte(`<p>x</p><img src="https://e.com/a.jpg">`))
+	if err != nil {
+		t.Fatalf("ArticleToAMP() ERROR: %q", err)
+	}
+	page, _, err = AMPPage(`Title`, `https://rg.ru/news.html`, article.Body, PageMeta{})
+	if err != nil {
+		t.Fatalf("AMPPage() ERROR: %q", err)
+	}
+	if diagnostics := Validate(page); len(diagnostics) > 0 {
+		t.Errorf("Validate(AMPPage()) = %q", diagnostics)
+	}
+}
+
+func TestAMPPageCSS(t *testing.T) {
+	var tests = []struct {
+		css  string
+		want string
+	}{
+		{`p{color:red}</style><script>alert(1)</script>`, `invalid custom css: closing style tag`},
+		{`p{color:red}</STYLE >`, `invalid custom css: closing style tag`},
+		{`p{color:red !important}`, `invalid custom css: !important is not allowed`},
+		{`p{color:red! IMPORTANT}`, `invalid custom css: !important is not allowed`},
+		{strings.Repeat(`p{color:red}`, 7000), `invalid custom css: 84000 bytes is larger than 75000`},
+		{`p{color:red}`, `<nil>`},
+	}
+
+	for i, test := range tests {
+		_, _, err := AMPPage(`Title`, `https://rg.ru/news.html`, []byte(`<p>Text</p>`), PageMeta{CSS: test.css})
+		if fmt.Sprint(err) != test.want {
+			t.Errorf("\n[%d]AMPPage() = %v,\nwant ERR    %q\n", i+1, err, test.want)
+		}
+		if err != nil && !errors.Is(err, ErrInvalidCSS) {
+			t.Errorf("\n[%d]AMPPage() = %v, want %v", i+1, err, ErrInvalidCSS)
+		}
+	}
+}
+
+func TestTurboFeed(t *testing.T) {
+	feed := TurboFeed{
+		Channel: TurboChannel{
+			Title:       "Российская газета",
+			Link:        "https://rg.ru",
+			Description: "News & views",
+			Language:    "ru",
+			Analytics:   []TurboAnalytics{{Type: "Yandex", ID: "123456"}},
+			AdNetworks:  []TurboAdNetwork{{Type: "Yandex", ID: "RA-123456-1", AdID: "first_ad_place"}},
+		},
+	}
+	feed.Add(TurboItem{
+		Link:    "https://rg.ru/2020/01/10/news.html",
+		Title:   "Title <1>",
+		PubDate: time.Date(2020, 1, 10, 12, 0, 0, 0, time.UTC),
+		Image:   "https://rg.ru/cover.jpg",
+		Body:    []byte(`<p>a]]>b</p><iframe src="http://example.com/x"></iframe>`),
+	})
+
+	var buf bytes.Buffer
+	failures, err := feed.Write(&buf)
+	if err != nil {
+		t.Fatalf("TurboFeed.Write() ERROR: %q", err)
+	}
+	if len(failures["https://rg.ru/2020/01/10/news.html"]) != 1 {
+		t.Errorf("TurboFeed.Write() failures = %q, want 1", failures)
+	}
+
+	want := `<?xml version="1.0" encoding="UTF-8"?>
+<rss xmlns:yandex="http://news.yandex.ru" xmlns:media="http://search.yahoo.com/mrss/" xmlns:turbo="http://turbo.yandex.ru" version="2.0">
+<channel>
+<title>Российская газета</title>
+<link>https://rg.ru</link>
+<description>News &amp; views</description>
+<language>ru</language>
+<turbo:analytics type="Yandex" id="123456"></turbo:analytics>
+<turbo:adNetwork type="Yandex" id="RA-123456-1" turbo-ad-id="first_ad_place"></turbo:adNetwork>
+<item turbo="true">
+<link>https://rg.ru/2020/01/10/news.html</link>
+<pubDate>Fri, 10 Jan 2020 12:00:00 +0000</pubDate>
+<turbo:content><![CDATA[
+<header><figure><img src="https://rg.ru/cover.jpg"></figure><h1>Title &lt;1&gt;</h1></header>
+<p>a]]&gt;b</p>
+]]></turbo:content>
+</item>
+</channel>
+</rss>
+`
+	if buf.String() != want {
+		t.Errorf("\nTurboFeed.Write() = %s,\nwant        %s\n", buf.String(), want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  []string
+	}{
+		{
+			`<amp-iframe width="480" height="315" sandbox="allow-scripts allow-same-origin" layout="responsive" frameborder="0" allowfullscreen src="https://russian.rt.com/nopolitics/video/706313-popokatepetl-stolb-pepel-3-km/video/5e1852ef02e8bd3b731db837"></amp-iframe>`,
+			nil,
+		},
+		{
+			`<p>text</p>
+<amp-iframe width="480" height="315" layout="responsive" src="https://russian.rt.com/video/5e1852ef02e8bd3b731db837"`,
+			[]string{`2:1: unexpected end of input inside tag`},
+		},
+		{
+			`<p>text</p>
+<amp-youtube layout="responsive" data-videoid="05klG-PTKqo"></amp-youtube>
+<amp-iframe height="315" layout="fixed-height" src="http://rt.com/video"><p>x</p>
+<amp-vk layout="fill" data-embedtype="post"></amp-vk>
+<iframe src="https://rt.com"></iframe><img src="/a.jpg" onclick="alert(1)">
+<noscript><img src="/a.jpg"></noscript>`,
+			[]string{
+				`2:1: amp-youtube: missing attribute width for layout responsive`,
+				`2:1: amp-youtube: missing attribute height for layout responsive`,
+				`3:1: amp-iframe: attribute src should be https url`,
+				`4:1: amp-vk: layout fill is not supported`,
+				`5:1: iframe: use amp-iframe`,
+				`5:39: img: event handler attribute onclick is not allowed`,
+				`5:39: img: use amp-img`,
+				`3:1: amp-iframe: tag is not closed`,
+			},
+		},
+		{
+			`<amp-instagram width="400" height="400"></amp-instagram><script src="https://www.instagram.com/embed.js"></script>`,
+			[]string{
+				`1:1: amp-instagram: missing attribute data-shortcode or src`,
+				`1:57: script: only AMP runtime, component and JSON-LD scripts are allowed`,
+			},
+		},
+		{
+			`<!doctype html>
+<html lang="ru">
+<head><meta charset="utf-8"><title>x</title></head>
+<body><amp-twitter width="380" height="480" layout="responsive" data-tweetid="1211912897590202368"></amp-twitter></body>
+</html>`,
+			[]string{
+				`2:1: html: missing attribute ⚡ or amp`,
+				`2:1: missing <meta name="viewport">`,
+				`2:1: missing <link rel="canonical">`,
+				`2:1: missing <script async src="https://cdn.ampproject.org/v0.js">`,
+				`2:1: missing <style amp-boilerplate>`,
+				`2:1: amp-twitter: missing script of the component`,
+			},
+		},
+	}
+
+	for i, test := range tests {
+		var got []string
+		for _, d := range Validate([]byte(test.input)) {
+			got = append(got, d.String())
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("\n[%d]Validate() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+	}
+}
+
+// Responsive layout needs width and height to get aspect ratio, that is why
+// amp-instagram, amp-twitter and amp-vk are rendered with their default sizes.
+// Markup without them, as it was expected by the first tests of the package, is invalid.
+func TestValidateSizes(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{
+			`<blockquote class="instagram-media" data-instgrm-permalink="https://www.instagram.com/p/B6nHZAHl7JZ/"></blockquote>`,
+			`<amp-instagram layout="responsive" data-shortcode="B6nHZAHl7JZ"></amp-instagram>`,
+		},
+		{
+			`<blockquote class="twitter-tweet"><a href="https://twitter.com/WIONews/status/1211912897590202368">December 31, 2019</a></blockquote>`,
+			`<amp-twitter layout="responsive" data-tweetid="1211912897590202368"></amp-twitter>`,
+		},
+		{
+			`<script>VK.Widgets.Post("vk_post_-175249128_1156", -175249128, 1156, 'HmCFKRSM81NEzJ8mY9gzgXOlEFM');</script>`,
+			`<amp-vk data-embedtype="post" layout="responsive" data-owner-id="-175249128" data-post-id="1156" data-hash="HmCFKRSM81NEzJ8mY9gzgXOlEFM"></amp-vk>`,
+		},
+	}
+
+	for i, test := range tests {
+		name := strings.Fields(test.want)[0][1:]
+		want := []string{
+			`1:1: ` + name + `: missing attribute width for layout responsive`,
+			`1:1: ` + name + `: missing attribute height for layout responsive`,
+		}
+		var got []string
+		for _, d := range Validate([]byte(test.want)) {
+			got = append(got, d.String())
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("\n[%d]Validate() = %q,\nwant        %q\n", i+1, got, want)
+		}
+
+		amp, _, err := AMP([]byte(test.input))
+		if err != nil {
+			t.Fatalf("\n[%d]AMP() ERROR: %q", i+1, err)
+		}
+		if diagnostics := Validate(amp); len(diagnostics) > 0 {
+			t.Errorf("\n[%d]Validate(%q) = %q", i+1, amp, diagnostics)
+		}
+	}
+}
+
+func TestValidateOwnOutput(t *testing.T) {
+	inputs := []string{
+		`<iframe src="https://russian.rt.com/world/video/706283" frameborder="0" allowfullscreen></iframe>`,
+		`<iframe width="560" height="315" src="https://www.youtube.com/embed/05klG-PTKqo" frameborder="0" allowfullscreen></iframe>`,
+		`<blockquote class="instagram-media" data-instgrm-permalink="https://www.instagram.com/p/B6nHZAHl7JZ/"></blockquote>`,
+		`<iframe src="https://www.facebook.com/plugins/post.php?href=https%3A%2F%2Fwww.facebook.com%2Fstcnk%2Fposts%2F3384458724928901&width=500" width="500" height="498"></iframe>`,
+		`<blockquote class="twitter-tweet"><a href="https://twitter.com/WIONews/status/1211912897590202368">December 31, 2019</a></blockquote>`,
+		`<script>VK.Widgets.Post("vk_post_-175249128_1156", -175249128, 1156, 'HmCFKRSM81NEzJ8mY9gzgXOlEFM');</script>`,
+		`<div class="playbuzz" data-id="001c4920-5312-4d9a-9ecc-5b5dcf753381">&nbsp;</div>`,
+		`<blockquote class="tiktok-embed" cite="https://www.tiktok.com/@rgru_official/video/7021366529212419330" data-video-id="7021366529212419330"><section></section></blockquote>`,
+		`<script async src="https://telegram.org/js/telegram-widget.js?22" data-telegram-post="rgrunews/12345" data-width="100%"></script>`,
+		`<iframe width="720" height="405" src="https://rutube.ru/play/embed/a10e53b86e8f349080f718d3f00d4f4a" frameBorder="0" allow="clipboard-write; autoplay" webkitAllowFullScreen mozallowfullscreen allowFullScreen></iframe>`,
+		`<iframe src="https://vk.com/video_ext.php?oid=-22822305&id=456242110&hash=e037414127166efe&hd=2" width="853" height="480" allow="autoplay; encrypted-media; fullscreen; picture-in-picture;" frameborder="0" allowfullscreen></iframe>`,
+		`<iframe src="https://player.vimeo.com/video/76979871?autoplay=1&muted=1" width="640" height="360" frameborder="0" allow="autoplay; fullscreen; picture-in-picture" allowfullscreen></iframe>`,
+		`<iframe frameborder="0" width="480" height="270" src="https://www.dailymotion.com/embed/video/x7tgad0?autoplay=1&mute=1&queue-enable=false" allowfullscreen allow="autoplay"></iframe>`,
+		`<a data-pin-do="embedPin" href="https://www.pinterest.com/pin/99360735500167749/"></a>`,
+		`<a data-pin-do="embedBoard" href="https://www.pinterest.com/pinterest/official-news/"></a>`,
+		`<blockquote class="reddit-embed-bq" data-embed-height="316"><a href="https://www.reddit.com/r/aww/comments/15l2uzq/my_cat_when_he_hears_the_treat_bag/">My cat when he hears the treat bag</a><br> by<a href="https://www.reddit.com/user/catlover/">u/catlover</a> in<a href="https://www.reddit.com/r/aww/">aww</a></blockquote><script async="" src="https://embed.reddit.com/widgets.js" charset="UTF-8"></script>`,
+		`<iframe width="100%" height="166" scrolling="no" frameborder="no" allow="autoplay" src="https://w.soundcloud.com/player/?url=https%3A//api.soundcloud.com/tracks/293&color=%23ff5500&auto_play=false&hide_related=false&show_comments=true&show_user=true&show_reposts=false&show_teaser=true"></iframe>`,
+		`<iframe frameborder="0" style="border:none;width:100%;height:180px;" width="100%" height="180" src="https://music.yandex.ru/iframe/#track/17325218/2007004">Слушайте <a href='https://music.yandex.ru/album/2007004/track/17325218'>Группа крови</a> — <a href='https://music.yandex.ru/artist/161373'>Кино</a> на Яндекс Музыке</iframe>`,
+		`<iframe allow="autoplay *; encrypted-media *; fullscreen *; clipboard-write" frameborder="0" height="175" style="width:100%;max-width:660px;overflow:hidden;border-radius:10px;" sandbox="allow-forms allow-popups allow-same-origin allow-scripts allow-storage-access-by-user-activation allow-top-navigation-by-user-activation" src="https://embed.podcasts.apple.com/us/podcast/the-daily/id1200361736?i=1000650123456"></iframe>`,
+		`<blockquote class="text-post-media" data-text-post-permalink="https://www.threads.net/@rgru_official/post/C8Hk2pLNx3Q" data-text-post-version="0" id="ig-tp-C8Hk2pLNx3Q"><a href="https://www.threads.net/@rgru_official/post/C8Hk2pLNx3Q">Post by @rgru_official</a></blockquote><script async src="https://www.threads.net/embed.js"></script>`,
+		`<iframe src="https://mastodon.social/@Gargron/113457432853185130/embed" class="mastodon-embed" style="max-width: 100%; border: 0" width="400" allowfullscreen="allowfullscreen"></iframe><script src="https://mastodon.social/embed.js" async="async"></script>`,
+		`<blockquote class="bluesky-embed" data-bluesky-uri="at://did:plc:z72i7hdynmk6r22z27h6tvur/app.bsky.feed.post/3lbwvqbbkzc2y" data-bluesky-cid="bafyreihc"><p lang="en">Post</p>&mdash; Bluesky (<a href="https://bsky.app/profile/did:plc:z72i7hdynmk6r22z27h6tvur?ref_src=embed">@bsky.app</a>)</blockquote><script async src="https://embed.bsky.app/static/embed.js" charset="utf-8"></script>`,
+		`<iframe width="560" height="315" src="//ok.ru/videoembed/2478134036998" frameborder="0" allow="autoplay" allowfullscreen></iframe>`,
+		`<iframe src="https://www.youtube-nocookie.com/embed/videoseries?list=PLx0sYbCqOb8TBPRdmBHs5Iftvv9TPboYG&amp;start=10"></iframe>`,
+		`<div class="fb-page" data-href="https://www.facebook.com/rgru" data-tabs="timeline"></div>`,
+	}
+
+	for _, input := range inputs {
+		got, _, err := AMP([]byte(input))
+		if err != nil {
+			t.Errorf("AMP(%q) ERROR: %q", input, err)
+			continue
+		}
+		if diagnostics := Validate(got); len(diagnostics) > 0 {
+			t.Errorf("Validate(%q) = %q", got, diagnostics)
+		}
+	}
+
+	page, _, err := AMPPage(`Title`, `https://rg.ru/news.html`, []byte(strings.Join(inputs, "\n")), PageMeta{Lang: "ru"})
+	if err != nil {
+		t.Fatalf("AMPPage() ERROR: %q", err)
+	}
+	if diagnostics := Validate(page); len(diagnostics) > 0 {
+		t.Errorf("Validate(AMPPage()) = %q", diagnostics)
+	}
+}
+
+func TestValidateTurbo(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  []string
+	}{
+		{
+			`<header><figure><img src="https://rg.ru/a.jpg"></figure><h1>Title</h1></header>
+<p>text</p>
+<blockquote class="twitter-tweet"><a href="https://twitter.com/WIONews/status/1211912897590202368">December 31, 2019</a></blockquote>
+<div id="vk_post_-175249128_1156"></div><script src="https://vk.com/js/api/openapi.js?162"></script>
+<script>VK.Widgets.Post("vk_post_-175249128_1156", -175249128, 1156, 'HmCFKRSM81NEzJ8mY9gzgXOlEFM');</script>`,
+			nil,
+		},
+		{
+			`<header><h2>Subtitle</h2><p>x</p></header>
+<style>p{}</style><figcaption>x</figcaption>
+<figure><figcaption>x</figcaption></figure>
+<iframe src="http://rt.com/video"></iframe><p onclick="alert(1)">x</p>`,
+			[]string{
+				`1:26: p: tag is not allowed in header`,
+				`1:1: header: header should contain h1`,
+				`2:1: style: tag is not allowed in Yandex Turbo`,
+				`2:19: figcaption: figcaption should be inside figure`,
+				`3:1: figure: figure should contain img, video or iframe`,
+				`4:1: iframe: attribute src should be https url`,
+				`4:44: p: event handler attribute onclick is not allowed`,
+			},
+		},
+		{
+			`<blockquote class="instagram-media"><a href="https://www.instagram.com/rgru/">rgru</a></blockquote>
+<script src="https://example.com/widget.js"></script>
+<script>alert(1)</script>`,
+			[]string{
+				`1:1: blockquote: instagram: no source of embed`,
+				`2:1: script: script of https://example.com/widget.js is not supported`,
+				`3:1: script: unknown embed is not supported`,
+			},
+		},
+	}
+
+	for i, test := range tests {
+		var got []string
+		for _, d := range ValidateTurbo([]byte(test.input)) {
+			got = append(got, d.String())
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("\n[%d]ValidateTurbo() = %q,\nwant             %q\n", i+1, got, test.want)
+		}
+	}
+
+	article, err := ArticleToTurbo([]byte(strings.Join([]string{
+		`<p>text</p>`,
+		`<iframe width="560" height="315" src="https://www.youtube.com/embed/05klG-PTKqo" frameborder="0" allowfullscreen></iframe>`,
+		`<blockquote class="instagram-media" data-instgrm-permalink="https://www.instagram.com/p/B6nHZAHl7JZ/"></blockquote>`,
+	}, "\n")))
+	if err != nil {
+		t.Fatalf("ArticleToTurbo() ERROR: %q", err)
+	}
+	if diagnostics := ValidateTurbo(article.Body); len(diagnostics) > 0 {
+		t.Errorf("ValidateTurbo(ArticleToTurbo()) = %q", diagnostics)
+	}
+}
+
+func TestTiktokToAMP(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{
+			`<blockquote class="tiktok-embed" cite="https://www.tiktok.com/@rgru_official/video/7021366529212419330" data-video-id="7021366529212419330" style="max-width: 605px;min-width: 325px;" > <section> <a target="_blank" title="@rgru_official" href="https://www.tiktok.com/@rgru_official">@rgru_official</a> <a target="_blank" title="♬ original sound - rgru_official" href="https://www.tiktok.com/music/original-sound-7021366535231294210">♬ original sound - rgru_official</a> </section> </blockquote> <script async src="https://www.tiktok.com/embed.js"></script>`,
+			`<amp-tiktok layout="responsive" height="575" width="325" data-src="7021366529212419330"></amp-tiktok>`,
+		},
+		{
+			`<blockquote class="tiktok-embed" data-video-id="7021366529212419330"><section></section></blockquote>`,
+			`<amp-tiktok layout="responsive" height="575" width="325" data-src="7021366529212419330"></amp-tiktok>`,
+		},
+		{
+			`<blockquote class="tiktok-embed" cite="https://www.tiktok.com/@rgru_official/video/7021366529212419330"><section></section></blockquote>`,
+			`<amp-tiktok layout="responsive" height="575" width="325" data-src="7021366529212419330"></amp-tiktok>`,
+		},
+		{
+			`<blockquote class="tiktok-embed" cite="https://www.tiktok.com/@rgru_official/video/7021366529212419330" data-video-id="7021366529212419331"><section></section></blockquote>`,
+			`tiktok: malformed embed: 7021366529212419331`,
+		},
+		{
+			`<blockquote class="tiktok-embed" cite="https://www.tok.com/@rgru_official/video/7021366529212419330"><section></section></blockquote>`,
+			`tiktok: wrong host: www.tok.com`,
+		},
+		{
+			`<blockquote class="tiktok-embed" cite="https://www.tiktok.com/@rgru_official"><section></section></blockquote>`,
+			`tiktok: malformed url: https://www.tiktok.com/@rgru_official`,
+		},
+		{
+			`<blockquote class="tiktok-embed" data-video-id="video"><section></section></blockquote>`,
+			`tiktok: malformed embed: video`,
+		},
+		{
+			`<blockquote class="tiktok-embed"><section></section></blockquote>`,
+			`tiktok: no source of embed`,
+		},
+	}
+
+	for i, test := range tests {
+		got, err := TiktokToAMP([]byte(test.input))
+		if err != nil {
+			if fmt.Sprint(err) != test.want {
+				t.Errorf("\n[%d]TiktokToAMP() = %q,\nwant ERR    %q\n", i+1, err, test.want)
+			}
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("\n[%d]TiktokToAMP() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+	}
+}
+
+func TestTiktokToTurbo(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{
+			`<blockquote class="tiktok-embed" cite="https://www.tiktok.com/@rgru_official/video/7021366529212419330" data-video-id="7021366529212419330" style="max-width: 605px;min-width: 325px;" > <section> <a target="_blank" title="@rgru_official" href="https://www.tiktok.com/@rgru_official">@rgru_official</a> <a target="_blank" title="♬ original sound - rgru_official" href="https://www.tiktok.com/music/original-sound-7021366535231294210">♬ original sound - rgru_official</a> </section> </blockquote> <script async src="https://www.tiktok.com/embed.js"></script>`,
+			`<iframe width="325" height="575" frameborder="0" src="https://www.tiktok.com/embed/v2/7021366529212419330"></iframe>`,
+		},
+		{
+			`<blockquote class="tiktok-embed" cite="https://www.tiktok.com/@rgru_official/video/7021366529212419330" data-video-id="7021366529212419331"><section></section></blockquote>`,
+			`tiktok: malformed embed: 7021366529212419331`,
+		},
+	}
+
+	for i, test := range tests {
+		got, err := TiktokToTurbo([]byte(test.input))
+		if err != nil {
+			if fmt.Sprint(err) != test.want {
+				t.Errorf("\n[%d]TiktokToTurbo() = %q,\nwant ERR    %q\n", i+1, err, test.want)
+			}
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("\n[%d]TiktokToTurbo() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+	}
+
+	article, err := ArticleToTurbo([]byte(`<p>text</p><blockquote class="tiktok-embed" cite="https://www.tiktok.com/@rgru_official/video/7021366529212419330" data-video-id="7021366529212419330" style="max-width: 605px;min-width: 325px;" > <section> <a target="_blank" title="@rgru_official" href="https://www.tiktok.com/@rgru_official">@rgru_official</a> <a target="_blank" title="♬ original sound - rgru_official" href="https://www.tiktok.com/music/original-sound-7021366535231294210">♬ original sound - rgru_official</a> </section> </blockquote> <script async src="https://www.tiktok.com/embed.js"></script>`))
+	if err != nil {
+		t.Fatalf("ArticleToTurbo() ERROR: %q", err)
+	}
+	if len(article.Failures) > 0 {
+		t.Errorf("ArticleToTurbo().Failures = %q", article.Failures)
+	}
+	if diagnostics := ValidateTurbo(article.Body); len(diagnostics) > 0 {
+		t.Errorf("ValidateTurbo(ArticleToTurbo()) = %q", diagnostics)
+	}
+}
+
+func TestTelegramToAMP(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{
+			`<script async src="https://telegram.org/js/telegram-widget.js?22" data-telegram-post="rgrunews/12345" data-width="100%"></script>`,
+			`<amp-iframe width="480" height="400" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" src="https://t.me/rgrunews/12345?embed=1"></amp-iframe>`,
+		},
+		{
+			`<iframe src="https://t.me/rgrunews/12345?embed=1&userpic=true" width="500" height="600" frameborder="0"></iframe>`,
+			`<amp-iframe width="500" height="600" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" src="https://t.me/rgrunews/12345?embed=1"></amp-iframe>`,
+		},
+		{
+			`<iframe src="https://telegram.me/rgrunews/12345"></iframe>`,
+			`<amp-iframe width="480" height="400" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" src="https://t.me/rgrunews/12345?embed=1"></amp-iframe>`,
+		},
+		{
+			`<iframe src="https://rgru.t.me/rgrunews/12345"></iframe>`,
+			`telegram: wrong host: rgru.t.me`,
+		},
+		{
+			`<script async src="https://telegram.org/js/telegram-widget.js?22" data-telegram-post="rgrunews"></script>`,
+			`telegram: malformed embed: rgrunews`,
+		},
+		{
+			`<script async src="https://telegram.org/js/telegram-widget.js?22" data-telegram-post="rgr/12345"></script>`,
+			`telegram: malformed embed: rgr/12345`,
+		},
+		{
+			`<script async src="https://telegram.org/js/telegram-widget.js?22"></script>`,
+			`telegram: no source of embed`,
+		},
+	}
+
+	for i, test := range tests {
+		got, err := TelegramToAMP([]byte(test.input))
+		if err != nil {
+			if fmt.Sprint(err) != test.want {
+				t.Errorf("\n[%d]TelegramToAMP() = %q,\nwant ERR    %q\n", i+1, err, test.want)
+			}
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("\n[%d]TelegramToAMP() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+	}
+
+	article, err := ArticleToAMP([]byte(`<p>text</p><script async src="https://telegram.org/js/telegram-widget.js?22" data-telegram-post="rgrunews/12345" data-width="100%"></script>`))
+	if err != nil {
+		t.Fatalf("ArticleToAMP() ERROR: %q", err)
+	}
+	if len(article.Embeds) != 1 || article.Embeds[0].URL != "https://t.me/rgrunews/12345" {
+		t.Errorf("ArticleToAMP().Embeds = %v", article.Embeds)
+	}
+}
+
+func TestTelegramToTurbo(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{
+			`<script async src="https://telegram.org/js/telegram-widget.js?22" data-telegram-post="rgrunews/12345" data-width="100%"></script>`,
+			`<iframe frameborder="0" src="https://t.me/rgrunews/12345?embed=1"></iframe>`,
+		},
+		{
+			`<iframe src="https://t.me/rgrunews/12345?embed=1" width="500" height="600"></iframe>`,
+			`<iframe width="500" height="600" frameborder="0" src="https://t.me/rgrunews/12345?embed=1"></iframe>`,
+		},
+		{
+			`<script async src="https://telegram.org/js/telegram-widget.js?22" data-telegram-post="rgrunews/post"></script>`,
+			`telegram: malformed embed: rgrunews/post`,
+		},
+	}
+
+	for i, test := range tests {
+		got, err := TelegramToTurbo([]byte(test.input))
+		if err != nil {
+			if fmt.Sprint(err) != test.want {
+				t.Errorf("\n[%d]TelegramToTurbo() = %q,\nwant ERR    %q\n", i+1, err, test.want)
+			}
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("\n[%d]TelegramToTurbo() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+	}
+
+	article, err := ArticleToTurbo([]byte(`<p>text</p><script async src="https://telegram.org/js/telegram-widget.js?22" data-telegram-post="rgrunews/12345" data-width="100%"></script>`))
+	if err != nil {
+		t.Fatalf("ArticleToTurbo() ERROR: %q", err)
+	}
+	if len(article.Failures) > 0 {
+		t.Errorf("ArticleToTurbo().Failures = %q", article.Failures)
+	}
+	if diagnostics := ValidateTurbo(article.Body); len(diagnostics) > 0 {
+		t.Errorf("ValidateTurbo(ArticleToTurbo()) = %q", diagnostics)
+	}
+}
+
+func TestRutubeToAMP(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{
+			`<iframe width="720" height="405" src="https://rutube.ru/play/embed/a10e53b86e8f349080f718d3f00d4f4a" frameBorder="0" allow="clipboard-write; autoplay" webkitAllowFullScreen mozallowfullscreen allowFullScreen></iframe>`,
+			`<amp-iframe width="720" height="405" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" allow="autoplay; encrypted-media; fullscreen; picture-in-picture" allowfullscreen src="https://rutube.ru/play/embed/a10e53b86e8f349080f718d3f00d4f4a"></amp-iframe>`,
+		},
+		{
+			`<iframe src="https://rutube.ru/play/embed/10588620/?p=Dk3hP_u0sTr2XvXMqSyoEw"></iframe>`,
+			`<amp-iframe width="640" height="360" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" allow="autoplay; encrypted-media; fullscreen; picture-in-picture" allowfullscreen src="https://rutube.ru/play/embed/10588620?p=Dk3hP_u0sTr2XvXMqSyoEw"></amp-iframe>`,
+		},
+		{
+			`<iframe src="https://rutube.ru/video/a10e53b86e8f349080f718d3f00d4f4a/"></iframe>`,
+			`rutube: malformed url: https://rutube.ru/video/a10e53b86e8f349080f718d3f00d4f4a/`,
+		},
+		{
+			`<iframe src="https://rutube.ru/play/embed/a10e53b86e8f349080f718d3f00d4f4a\"></iframe>`,
+			`rutube: malformed url: https://rutube.ru/play/embed/a10e53b86e8f349080f718d3f00d4f4a\`,
+		},
+		{
+			`<iframe src="https://rutube.ru.example.com/play/embed/a10e53b86e8f349080f718d3f00d4f4a"></iframe>`,
+			`rutube: wrong host: rutube.ru.example.com`,
+		},
+		{
+			`<div>rutube.ru</div>`,
+			`rutube: no source of embed`,
+		},
+	}
+
+	for i, test := range tests {
+		got, err := RutubeToAMP([]byte(test.input))
+		if err != nil {
+			if fmt.Sprint(err) != test.want {
+				t.Errorf("\n[%d]RutubeToAMP() = %q,\nwant ERR    %q\n", i+1, err, test.want)
+			}
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("\n[%d]RutubeToAMP() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+	}
+}
+
+func TestVkVideoToAMP(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{
+			`<iframe src="https://vk.com/video_ext.php?oid=-22822305&id=456242110&hash=e037414127166efe&hd=2" width="853" height="480" allow="autoplay; encrypted-media; fullscreen; picture-in-picture;" frameborder="0" allowfullscreen></iframe>`,
+			`<amp-iframe width="853" height="480" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" allow="autoplay; encrypted-media; fullscreen; picture-in-picture" allowfullscreen src="https://vk.com/video_ext.php?oid=-22822305&id=456242110&hash=e037414127166efe"></amp-iframe>`,
+		},
+		{
+			`<iframe src="https://vkvideo.ru/video_ext.php?oid=1&id=2"></iframe>`,
+			`<amp-iframe width="640" height="360" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" allow="autoplay; encrypted-media; fullscreen; picture-in-picture" allowfullscreen src="https://vk.com/video_ext.php?oid=1&id=2"></amp-iframe>`,
+		},
+		{
+			`<iframe src="https://vk.com/video_ext.php?oid=club1&id=2"></iframe>`,
+			`vkvideo: malformed embed: club1`,
+		},
+		{
+			`<iframe src="https://vk.com/video_ext.php?oid=-1&id=-2"></iframe>`,
+			`vkvideo: malformed embed: -2`,
+		},
+		{
+			`<iframe src="https://vk.com/video_ext.php?oid=-1&id=2&hash=%22%3E"></iframe>`,
+			`vkvideo: malformed embed: ">`,
+		},
+		{
+			`<iframe src="https://vk.com/video.php?oid=-1&id=2"></iframe>`,
+			`vkvideo: malformed url: https://vk.com/video.php?oid=-1&id=2`,
+		},
+		{
+			`<iframe src="https://example.com/video_ext.php?oid=-1&id=2"></iframe>`,
+			`vkvideo: wrong host: example.com`,
+		},
+	}
+
+	for i, test := range tests {
+		got, err := VkVideoToAMP([]byte(test.input))
+		if err != nil {
+			if fmt.Sprint(err) != test.want {
+				t.Errorf("\n[%d]VkVideoToAMP() = %q,\nwant ERR    %q\n", i+1, err, test.want)
+			}
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("\n[%d]VkVideoToAMP() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+	}
+}
+
+func TestVideoPlayersToTurbo(t *testing.T) {
+	var tests = []struct {
+		input   string
+		convert func([]byte) ([]byte, error)
+		want    string
+	}{
+		{`<iframe width="720" height="405" src="https://rutube.ru/play/embed/a10e53b86e8f349080f718d3f00d4f4a" frameBorder="0" allow="clipboard-write; autoplay" webkitAllowFullScreen mozallowfullscreen allowFullScreen></iframe>`, RutubeToTurbo, `<iframe width="720" height="405" allowfullscreen="true" frameborder="0" src="https://rutube.ru/play/embed/a10e53b86e8f349080f718d3f00d4f4a"></iframe>`},
+		{`<iframe src="http://rutube.ru/play/embed/10588620"></iframe>`, RutubeToTurbo, `<iframe allowfullscreen="true" frameborder="0" src="https://rutube.ru/play/embed/10588620"></iframe>`},
+		{`<iframe src="https://vk.com/video_ext.php?oid=-22822305&id=456242110&hash=e037414127166efe&hd=2" width="853" height="480" allow="autoplay; encrypted-media; fullscreen; picture-in-picture;" frameborder="0" allowfullscreen></iframe>`, VkVideoToTurbo, `<iframe width="853" height="480" allowfullscreen="true" frameborder="0" src="https://vk.com/video_ext.php?oid=-22822305&id=456242110&hash=e037414127166efe"></iframe>`},
+		{`<iframe src="https://vk.com/video_ext.php?oid=-1"></iframe>`, VkVideoToTurbo, `vkvideo: malformed embed`},
+		{`<iframe src="https://player.vimeo.com/video/76979871?autoplay=1&muted=1" width="640" height="360" frameborder="0" allow="autoplay; fullscreen; picture-in-picture" allowfullscreen></iframe>`, VimeoToTurbo, `<iframe width="640" height="360" allowfullscreen="true" frameborder="0" src="https://player.vimeo.com/video/76979871?autoplay=1&muted=1"></iframe>`},
+		{`<iframe src="https://player.vimeo.com/video/76979871?h=8272103f6e"></iframe>`, VimeoToTurbo, `<iframe allowfullscreen="true" frameborder="0" src="https://player.vimeo.com/video/76979871?h=8272103f6e"></iframe>`},
+		{`<iframe frameborder="0" width="480" height="270" src="https://www.dailymotion.com/embed/video/x7tgad0?autoplay=1&mute=1&queue-enable=false" allowfullscreen allow="autoplay"></iframe>`, DailymotionToTurbo, `<iframe width="480" height="270" allowfullscreen="true" frameborder="0" src="https://www.dailymotion.com/embed/video/x7tgad0?autoplay=1&mute=1&queue-enable=false"></iframe>`},
+		{`<iframe src="https://www.dailymotion.com/embed/video/"></iframe>`, DailymotionToTurbo, `dailymotion: malformed url: https://www.dailymotion.com/embed/video/`},
+	}
+
+	for i, test := range tests {
+		got, err := test.convert([]byte(test.input))
+		if err != nil {
+			if fmt.Sprint(err) != test.want {
+				t.Errorf("\n[%d]ToTurbo() = %q,\nwant ERR    %q\n", i+1, err, test.want)
+			}
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("\n[%d]ToTurbo() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+	}
+}
+
+func TestVimeoToAMP(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{
+			`<iframe src="https://player.vimeo.com/video/76979871?autoplay=1&muted=1" width="640" height="360" frameborder="0" allow="autoplay; fullscreen; picture-in-picture" allowfullscreen></iframe>`,
+			`<amp-vimeo layout="responsive" height="360" width="640" autoplay data-videoid="76979871"></amp-vimeo>`,
+		},
+		{
+			`<iframe src="https://player.vimeo.com/video/76979871?dnt=1"></iframe>`,
+			`<amp-vimeo layout="responsive" height="360" width="640" data-do-not-track data-videoid="76979871"></amp-vimeo>`,
+		},
+		{
+			// unlisted video
+			`<iframe src="https://player.vimeo.com/video/76979871?h=8272103f6e&autoplay=1" width="640" height="360"></iframe>`,
+			`<amp-iframe width="640" height="360" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" allow="autoplay; encrypted-media; fullscreen; picture-in-picture" allowfullscreen src="https://player.vimeo.com/video/76979871?autoplay=1&h=8272103f6e"></amp-iframe>`,
+		},
+		{
+			`<iframe src="https://player.vimeo.com/video/76979871?h=%22"></iframe>`,
+			`vimeo: malformed url: https://player.vimeo.com/video/76979871?h=%22`,
+		},
+		{
+			`<iframe src="https://player.vimeo.com/album/76979871"></iframe>`,
+			`vimeo: malformed url: https://player.vimeo.com/album/76979871`,
+		},
+		{
+			`<iframe src="https://vimeo.com/76979871"></iframe>`,
+			`vimeo: wrong host: vimeo.com`,
+		},
+	}
+
+	for i, test := range tests {
+		got, err := VimeoToAMP([]byte(test.input))
+		if err != nil {
+			if fmt.Sprint(err) != test.want {
+				t.Errorf("\n[%d]VimeoToAMP() = %q,\nwant ERR    %q\n", i+1, err, test.want)
+			}
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("\n[%d]VimeoToAMP() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+	}
+}
+
+func TestDailymotionToAMP(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{
+			`<iframe frameborder="0" width="480" height="270" src="https://www.dailymotion.com/embed/video/x7tgad0?autoplay=1&mute=1&queue-enable=false" allowfullscreen allow="autoplay"></iframe>`,
+			`<amp-dailymotion layout="responsive" height="270" width="480" autoplay data-mute="1" data-param-queue-enable="false" data-videoid="x7tgad0"></amp-dailymotion>`,
+		},
+		{
+			`<iframe src="https://www.dailymotion.com/embed/video/x7tgad0?start=30&ui-logo=false&autoplay=0"></iframe>`,
+			`<amp-dailymotion layout="responsive" height="360" width="640" data-start="30" data-ui-logo="false" data-videoid="x7tgad0"></amp-dailymotion>`,
+		},
+		{
+			`<iframe src="https://www.dailymotion.com/embed/video/x7tgad0?%22%3E=1&title=%22%3E"></iframe>`,
+			`<amp-dailymotion layout="responsive" height="360" width="640" data-param-title="&#34;&gt;" data-videoid="x7tgad0"></amp-dailymotion>`,
+		},
+		{
+			`<iframe src="https://www.dailymotion.com/embed/playlist/x6hynp"></iframe>`,
+			`dailymotion: malformed url: https://www.dailymotion.com/embed/playlist/x6hynp`,
+		},
+		{
+			`<iframe src="https://dailymotion.com.example.com/embed/video/x7tgad0"></iframe>`,
+			`dailymotion: wrong host: dailymotion.com.example.com`,
+		},
+	}
+
+	for i, test := range tests {
+		got, err := DailymotionToAMP([]byte(test.input))
+		if err != nil {
+			if fmt.Sprint(err) != test.want {
+				t.Errorf("\n[%d]DailymotionToAMP() = %q,\nwant ERR    %q\n", i+1, err, test.want)
+			}
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("\n[%d]DailymotionToAMP() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+	}
+}
+
+func TestPinterestToAMP(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{
+			`<a data-pin-do="embedPin" data-pin-width="medium" href="https://www.pinterest.com/pin/99360735500167749/"></a>
+<script async defer src="//assets.pinterest.com/js/pinit.js"></script>`,
+			`<amp-pinterest height="476" width="345" data-width="medium" data-do="embedPin" data-url="https://www.pinterest.com/pin/99360735500167749/"></amp-pinterest>`,
+		},
+		{
+			`<a data-pin-do="embedPin" href="https://ru.pinterest.com/pin/99360735500167749"></a>`,
+			`<amp-pinterest height="326" width="236" data-do="embedPin" data-url="https://www.pinterest.com/pin/99360735500167749/"></amp-pinterest>`,
+		},
+		{
+			`<a data-pin-do="embedBoard" data-pin-board-width="400" data-pin-scale-height="240" data-pin-scale-width="80" href="https://www.pinterest.com/pinterest/official-news/"></a>`,
+			`<amp-pinterest height="28" width="160" data-do="buttonFollow" data-href="https://www.pinterest.com/pinterest/official-news/" data-label="official-news"></amp-pinterest>`,
+		},
+		{
+			`<a data-pin-do="embedUser" data-pin-board-width="400" href="https://www.pinterest.com/pinterest/"></a>`,
+			`<amp-pinterest height="28" width="160" data-do="buttonFollow" data-href="https://www.pinterest.com/pinterest/" data-label="pinterest"></amp-pinterest>`,
+		},
+		{
+			`<a data-pin-do="embedPin" href="https://www.pinterest.com/pinterest/official-news/"></a>`,
+			`pinterest: malformed url: https://www.pinterest.com/pinterest/official-news/`,
+		},
+		{
+			`<a data-pin-do="embedPin" href="https://pinterest.example.com/pin/99360735500167749/"></a>`,
+			`pinterest: wrong host: pinterest.example.com`,
+		},
+		{
+			`<a data-pin-do="buttonPin" href="https://www.pinterest.com/pin/create/button/"></a>`,
+			`pinterest: unsupported embed: buttonPin`,
+		},
+		{
+			`<a data-pin-do="embedPin"></a>`,
+			`pinterest: no source of embed`,
+		},
+	}
+
+	for i, test := range tests {
+		got, err := PinterestToAMP([]byte(test.input))
+		if err != nil {
+			if fmt.Sprint(err) != test.want {
+				t.Errorf("\n[%d]PinterestToAMP() = %q,\nwant ERR    %q\n", i+1, err, test.want)
+			}
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("\n[%d]PinterestToAMP() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+	}
+}
+
+func TestPinterestToTurbo(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{
+			`<a data-pin-do="embedPin" data-pin-width="medium" href="https://www.pinterest.com/pin/99360735500167749/"></a>
+<script async defer src="//assets.pinterest.com/js/pinit.js"></script>`,
+			`<iframe width="345" height="476" frameborder="0" src="https://assets.pinterest.com/ext/embed.html?id=99360735500167749"></iframe>`,
+		},
+		{
+			`<a data-pin-do="embedBoard" href="https://www.pinterest.com/pinterest/official-news/"></a>`,
+			`<a href="https://www.pinterest.com/pinterest/official-news/">https://www.pinterest.com/pinterest/official-news/</a>`,
+		},
+		{
+			`<a data-pin-do="embedUser" href="https://www.pinterest.com/p/"></a>`,
+			`pinterest: malformed url: https://www.pinterest.com/p/`,
+		},
+	}
+
+	for i, test := range tests {
+		got, err := PinterestToTurbo([]byte(test.input))
+		if err != nil {
+			if fmt.Sprint(err) != test.want {
+				t.Errorf("\n[%d]PinterestToTurbo() = %q,\nwant ERR    %q\n", i+1, err, test.want)
+			}
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("\n[%d]PinterestToTurbo() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+	}
+
+	article, err := ArticleToTurbo([]byte(`<p>text</p>
+<p><a data-pin-do="embedUser" href="https://www.pinterest.com/pinterest/"></a></p>
+<a data-pin-do="embedPin" data-pin-width="medium" href="https://www.pinterest.com/pin/99360735500167749/"></a>
+<script async defer src="//assets.pinterest.com/js/pinit.js"></script>`))
+	if err != nil {
+		t.Fatalf("ArticleToTurbo() ERROR: %q", err)
+	}
+	if len(article.Failures) > 0 || len(article.Embeds) != 2 {
+		t.Errorf("ArticleToTurbo() = %q, %v", article.Failures, article.Embeds)
+	}
+	if diagnostics := ValidateTurbo(article.Body); len(diagnostics) > 0 {
+		t.Errorf("ValidateTurbo(ArticleToTurbo()) = %q", diagnostics)
+	}
+}
+
+func TestRedditToAMP(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{
+			`<blockquote class="reddit-embed-bq" data-embed-height="316"><a href="https://www.reddit.com/r/aww/comments/15l2uzq/my_cat_when_he_hears_the_treat_bag/">My cat when he hears the treat bag</a><br> by<a href="https://www.reddit.com/user/catlover/">u/catlover</a> in<a href="https://www.reddit.com/r/aww/">aww</a></blockquote><script async="" src="https://embed.reddit.com/widgets.js" charset="UTF-8"></script>`,
+			`<amp-reddit layout="responsive" height="316" width="300" data-embedtype="post" data-src="https://www.reddit.com/r/aww/comments/15l2uzq/my_cat_when_he_hears_the_treat_bag/"></amp-reddit>`,
+		},
+		{
+			`<blockquote class="reddit-card" data-card-created="1474385284"><a href="https://www.reddit.com/r/me_irl/comments/52rmir/me_irl/?ref=share&ref_source=embed">me_irl</a> from <a href="http://www.reddit.com/r/me_irl">me_irl</a></blockquote>
+<script async src="//embed.redditmedia.com/widgets/platform.js" charset="UTF-8"></script>`,
+			`<amp-reddit layout="responsive" height="400" width="300" data-embedtype="post" data-src="https://www.reddit.com/r/me_irl/comments/52rmir/me_irl/"></amp-reddit>`,
+		},
+		{
+			`<div class="reddit-embed" data-embed-media="www.redditmedia.com" data-embed-parent="true" data-embed-live="false" data-embed-uuid="24a3e4c6-07ac-4b2c-a2d5-0e2a9c2e1e3f" data-embed-created="2016-09-26T21:26:17.823Z"><a href="https://www.reddit.com/r/sports/comments/54loj1/50_cents_awful_1st_pitch_given_a_historical/d8306kw/">Comment</a> from discussion <a href="https://www.reddit.com/r/sports/comments/54loj1/50_cents_awful_1st_pitch_given_a_historical/">50 Cent's awful 1st pitch</a>.</div>`,
+			`<amp-reddit layout="responsive" height="400" width="300" data-embedtype="comment" data-embedcreated="2016-09-26T21:26:17.823Z" data-embedlive="false" data-embedparent="true" data-uuid="24a3e4c6-07ac-4b2c-a2d5-0e2a9c2e1e3f" data-src="https://www.reddit.com/r/sports/comments/54loj1/50_cents_awful_1st_pitch_given_a_historical/d8306kw/"></amp-reddit>`,
+		},
+		{
+			`<blockquote class="reddit-embed-bq" data-embed-showtitle="true"><a href="https://www.reddit.com/r/aww/comments/15l2uzq/comment/jvaxm3k/">Comment</a></blockquote>`,
+			`<amp-reddit layout="responsive" height="400" width="300" data-embedtype="comment" data-src="https://www.reddit.com/r/aww/comments/15l2uzq/comment/jvaxm3k/"></amp-reddit>`,
+		},
+		{
+			`<blockquote class="reddit-embed-bq"><a href="https://www.reddit.com/r/aww/">aww</a></blockquote>`,
+			`reddit: malformed url: https://www.reddit.com/r/aww/`,
+		},
+		{
+			`<blockquote class="reddit-embed-bq"><a href="https://reddit.com.example.com/r/aww/comments/15l2uzq/">aww</a></blockquote>`,
+			`reddit: wrong host: reddit.com.example.com`,
+		},
+		{
+			`<blockquote class="reddit-embed-bq">aww</blockquote>`,
+			`reddit: no source of embed`,
+		},
+	}
+
+	for i, test := range tests {
+		got, err := RedditToAMP([]byte(test.input))
+		if err != nil {
+			if fmt.Sprint(err) != test.want {
+				t.Errorf("\n[%d]RedditToAMP() = %q,\nwant ERR    %q\n", i+1, err, test.want)
+			}
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("\n[%d]RedditToAMP() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+	}
+}
+
+func TestRedditToTurbo(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{
+			`<blockquote class="reddit-embed-bq" data-embed-height="316"><a href="https://www.reddit.com/r/aww/comments/15l2uzq/my_cat_when_he_hears_the_treat_bag/">My cat when he hears the treat bag</a><br> by<a href="https://www.reddit.com/user/catlover/">u/catlover</a> in<a href="https://www.reddit.com/r/aww/">aww</a></blockquote><script async="" src="https://embed.reddit.com/widgets.js" charset="UTF-8"></script>`,
+			`<blockquote class="reddit-embed-bq" data-embed-height="316"><a href="https://www.reddit.com/r/aww/comments/15l2uzq/my_cat_when_he_hears_the_treat_bag/">My cat when he hears the treat bag</a><br> by<a href="https://www.reddit.com/user/catlover/">u/catlover</a> in<a href="https://www.reddit.com/r/aww/">aww</a></blockquote><script async="" src="https://embed.reddit.com/widgets.js" charset="UTF-8"></script>`,
+		},
+		{
+			`<blockquote class="reddit-embed-bq"><a href="https://www.reddit.com/user/catlover/">catlover</a></blockquote>`,
+			`reddit: malformed url: https://www.reddit.com/user/catlover/`,
+		},
+	}
+
+	for i, test := range tests {
+		got, err := RedditToTurbo([]byte(test.input))
+		if err != nil {
+			if fmt.Sprint(err) != test.want {
+				t.Errorf("\n[%d]RedditToTurbo() = %q,\nwant ERR    %q\n", i+1, err, test.want)
+			}
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("\n[%d]RedditToTurbo() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+	}
+
+	article, err := ArticleToTurbo([]byte(`<p>text</p><blockquote class="reddit-embed-bq" data-embed-height="316"><a href="https://www.reddit.com/r/aww/comments/15l2uzq/my_cat_when_he_hears_the_treat_bag/">My cat when he hears the treat bag</a><br> by<a href="https://www.reddit.com/user/catlover/">u/catlover</a> in<a href="https://www.reddit.com/r/aww/">aww</a></blockquote><script async="" src="https://embed.reddit.com/widgets.js" charset="UTF-8"></script>`))
+	if err != nil {
+		t.Fatalf("ArticleToTurbo() ERROR: %q", err)
+	}
+	if len(article.Failures) > 0 || len(article.Embeds) != 1 || article.Embeds[0].Kind != "post" {
+		t.Errorf("ArticleToTurbo() = %q, %v", article.Failures, article.Embeds)
+	}
+	if diagnostics := ValidateTurbo(article.Body); len(diagnostics) > 0 {
+		t.Errorf("ValidateTurbo(ArticleToTurbo()) = %q", diagnostics)
+	}
+}
+
+func TestAudioToAMP(t *testing.T) {
+	var tests = []struct {
+		input   string
+		convert func([]byte) ([]byte, error)
+		want    string
+	}{
+		{`<iframe width="100%" height="166" scrolling="no" frameborder="no" allow="autoplay" src="https://w.soundcloud.com/player/?url=https%3A//api.soundcloud.com/tracks/293&color=%23ff5500&auto_play=false&hide_related=false&show_comments=true&show_user=true&show_reposts=false&show_teaser=true"></iframe>`, SoundcloudToAMP, `<amp-soundcloud height="166" layout="fixed-height" data-trackid="293" data-color="ff5500"></amp-soundcloud>`},
+		{
+			`<iframe src="https://w.soundcloud.com/player/?url=https%3A//api.soundcloud.com/tracks/soundcloud%253Atracks%253A293&visual=true"></iframe>`,
+			SoundcloudToAMP,
+			`<amp-soundcloud height="300" layout="fixed-height" data-trackid="293" data-visual="true"></amp-soundcloud>`,
+		},
+		{
+			`<iframe src="https://w.soundcloud.com/player/?url=https%3A//api.soundcloud.com/users/293"></iframe>`,
+			SoundcloudToAMP,
+			`soundcloud: malformed url: https://api.soundcloud.com/users/293`,
+		},
+		{
+			`<iframe src="https://w.soundcloud.com/player/?url=https%3A//api.soundcloud.com/tracks/293%3Fsecret_token%3D%2522"></iframe>`,
+			SoundcloudToAMP,
+			`soundcloud: malformed embed: "`,
+		},
+		{
+			`<iframe src="https://w.soundcloud.com/player/?url=https%3A//soundcloud.com/tracks/293"></iframe>`,
+			SoundcloudToAMP,
+			`soundcloud: wrong host: soundcloud.com`,
+		},
+		{`<iframe frameborder="0" style="border:none;width:100%;height:180px;" width="100%" height="180" src="https://music.yandex.ru/iframe/#track/17325218/2007004">Слушайте <a href='https://music.yandex.ru/album/2007004/track/17325218'>Группа крови</a> — <a href='https://music.yandex.ru/artist/161373'>Кино</a> на Яндекс Музыке</iframe>`, YandexMusicToAMP, `<amp-iframe height="180" layout="fixed-height" sandbox="allow-scripts allow-same-origin allow-popups" frameborder="0" allow="autoplay" src="https://music.yandex.ru/iframe/#track/17325218/2007004"></amp-iframe>`},
+		{
+			`<iframe frameborder="0" width="100%" height="450" src="https://music.yandex.ru/iframe/album/2007004"></iframe>`,
+			YandexMusicToAMP,
+			`<amp-iframe height="450" layout="fixed-height" sandbox="allow-scripts allow-same-origin allow-popups" frameborder="0" allow="autoplay" src="https://music.yandex.ru/iframe/#album/2007004"></amp-iframe>`,
+		},
+		{
+			`<iframe src="https://music.yandex.com/iframe/#playlist/yamusic-daily/1003"></iframe>`,
+			YandexMusicToAMP,
+			`<amp-iframe height="450" layout="fixed-height" sandbox="allow-scripts allow-same-origin allow-popups" frameborder="0" allow="autoplay" src="https://music.yandex.ru/iframe/#playlist/yamusic-daily/1003"></amp-iframe>`,
+		},
+		{
+			`<iframe src="https://music.yandex.ru/iframe/#artist/161373"></iframe>`,
+			YandexMusicToAMP,
+			`yandexmusic: malformed url: https://music.yandex.ru/iframe/#artist/161373`,
+		},
+		{
+			`<iframe src="https://music.yandex.example.com/iframe/#album/2007004"></iframe>`,
+			YandexMusicToAMP,
+			`yandexmusic: wrong host: music.yandex.example.com`,
+		},
+		{`<iframe allow="autoplay *; encrypted-media *; fullscreen *; clipboard-write" frameborder="0" height="175" style="width:100%;max-width:660px;overflow:hidden;border-radius:10px;" sandbox="allow-forms allow-popups allow-same-origin allow-scripts allow-storage-access-by-user-activation allow-top-navigation-by-user-activation" src="https://embed.podcasts.apple.com/us/podcast/the-daily/id1200361736?i=1000650123456"></iframe>`, ApplePodcastsToAMP, `<amp-iframe height="175" layout="fixed-height" sandbox="allow-scripts allow-same-origin allow-popups" frameborder="0" allow="autoplay" src="https://embed.podcasts.apple.com/us/podcast/the-daily/id1200361736?i=1000650123456"></amp-iframe>`},
+		{
+			`<iframe src="https://embed.podcasts.apple.com/ru/podcast/%D0%BF%D0%BE%D0%B4%D0%BA%D0%B0%D1%81%D1%82/id1200361736"></iframe>`,
+			ApplePodcastsToAMP,
+			`<amp-iframe height="450" layout="fixed-height" sandbox="allow-scripts allow-same-origin allow-popups" frameborder="0" allow="autoplay" src="https://embed.podcasts.apple.com/ru/podcast/%D0%BF%D0%BE%D0%B4%D0%BA%D0%B0%D1%81%D1%82/id1200361736"></amp-iframe>`,
+		},
+		{
+			`<iframe src="https://embed.podcasts.apple.com/us/podcast/the-daily/id1200361736?i=latest"></iframe>`,
+			ApplePodcastsToAMP,
+			`applepodcasts: malformed embed: latest`,
+		},
+		{
+			`<iframe src="https://podcasts.apple.com/us/podcast/the-daily/id1200361736"></iframe>`,
+			ApplePodcastsToAMP,
+			`applepodcasts: wrong host: podcasts.apple.com`,
+		},
+	}
+
+	for i, test := range tests {
+		got, err := test.convert([]byte(test.input))
+		if err != nil {
+			if fmt.Sprint(err) != test.want {
+				t.Errorf("\n[%d]ToAMP() = %q,\nwant ERR    %q\n", i+1, err, test.want)
+			}
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("\n[%d]ToAMP() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+	}
+}
+
+func TestAudioToTurbo(t *testing.T) {
+	var tests = []struct {
+		input   string
+		convert func([]byte) ([]byte, error)
+		want    string
+	}{
+		{`<iframe width="100%" height="166" scrolling="no" frameborder="no" allow="autoplay" src="https://w.soundcloud.com/player/?url=https%3A//api.soundcloud.com/tracks/293&color=%23ff5500&auto_play=false&hide_related=false&show_comments=true&show_user=true&show_reposts=false&show_teaser=true"></iframe>`, SoundcloudToTurbo, `<iframe height="166" frameborder="0" src="https://w.soundcloud.com/player/?color=%23ff5500&url=https%3A%2F%2Fapi.soundcloud.com%2Ftracks%2F293"></iframe>`},
+		{`<iframe frameborder="0" style="border:none;width:100%;height:180px;" width="100%" height="180" src="https://music.yandex.ru/iframe/#track/17325218/2007004">Слушайте <a href='https://music.yandex.ru/album/2007004/track/17325218'>Группа крови</a> — <a href='https://music.yandex.ru/artist/161373'>Кино</a> на Яндекс Музыке</iframe>`, YandexMusicToTurbo, `<iframe height="180" frameborder="0" src="https://music.yandex.ru/iframe/#track/17325218/2007004"></iframe>`},
+		{`<iframe allow="autoplay *; encrypted-media *; fullscreen *; clipboard-write" frameborder="0" height="175" style="width:100%;max-width:660px;overflow:hidden;border-radius:10px;" sandbox="allow-forms allow-popups allow-same-origin allow-scripts allow-storage-access-by-user-activation allow-top-navigation-by-user-activation" src="https://embed.podcasts.apple.com/us/podcast/the-daily/id1200361736?i=1000650123456"></iframe>`, ApplePodcastsToTurbo, `<iframe height="175" frameborder="0" src="https://embed.podcasts.apple.com/us/podcast/the-daily/id1200361736?i=1000650123456"></iframe>`},
+		{`<iframe src="https://music.yandex.ru/album/2007004"></iframe>`, YandexMusicToTurbo, `yandexmusic: malformed url: https://music.yandex.ru/album/2007004`},
+	}
+
+	for i, test := range tests {
+		got, err := test.convert([]byte(test.input))
+		if err != nil {
+			if fmt.Sprint(err) != test.want {
+				t.Errorf("\n[%d]ToTurbo() = %q,\nwant ERR    %q\n", i+1, err, test.want)
+			}
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("\n[%d]ToTurbo() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+	}
+}
+
+func TestSocialPostsToAMP(t *testing.T) {
+	var tests = []struct {
+		input   string
+		convert func([]byte) ([]byte, error)
+		want    string
+	}{
+		{`<blockquote class="text-post-media" data-text-post-permalink="https://www.threads.net/@rgru_official/post/C8Hk2pLNx3Q" data-text-post-version="0" id="ig-tp-C8Hk2pLNx3Q"><a href="https://www.threads.net/@rgru_official/post/C8Hk2pLNx3Q">Post by @rgru_official</a></blockquote><script async src="https://www.threads.net/embed.js"></script>`, ThreadsToAMP, `<amp-iframe width="480" height="400" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" src="https://www.threads.com/@rgru_official/post/C8Hk2pLNx3Q/embed"></amp-iframe>`},
+		{`<blockquote class="text-post-media" data-text-post-permalink="https://www.threads.com/@rgru.official/post/C8Hk2pLNx3Q/"></blockquote>`, ThreadsToAMP, `<amp-iframe width="480" height="400" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" src="https://www.threads.com/@rgru.official/post/C8Hk2pLNx3Q/embed"></amp-iframe>`},
+		{`<blockquote class="text-post-media" data-text-post-permalink="https://www.instagram.com/@rgru_official/post/C8Hk2pLNx3Q"></blockquote>`, ThreadsToAMP, `threads: wrong host: www.instagram.com`},
+		{`<blockquote class="text-post-media" data-text-post-permalink="https://www.threads.net/@rgru_official"></blockquote>`, ThreadsToAMP, `threads: malformed url: https://www.threads.net/@rgru_official`},
+		{`<blockquote class="text-post-media"></blockquote>`, ThreadsToAMP, `threads: no source of embed`},
+		{`<iframe src="https://mastodon.social/@Gargron/113457432853185130/embed" class="mastodon-embed" style="max-width: 100%; border: 0" width="400" allowfullscreen="allowfullscreen"></iframe><script src="https://mastodon.social/embed.js" async="async"></script>`, MastodonToAMP, `<amp-iframe width="400" height="400" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" src="https://mastodon.social/@Gargron/113457432853185130/embed"></amp-iframe>`},
+		{`<blockquote class="mastodon-embed" data-embed-url="https://fosstodon.org/users/rgru/statuses/113457432853185130/embed"><a href="https://fosstodon.org/@rgru/113457432853185130">Post</a></blockquote>`, MastodonToAMP, `<amp-iframe width="480" height="400" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" src="https://fosstodon.org/@rgru/113457432853185130/embed"></amp-iframe>`},
+		{`<iframe src="http://mastodon.social/@Gargron/113457432853185130/embed"></iframe>`, MastodonToAMP, `mastodon: insecure scheme: http://mastodon.social/@Gargron/113457432853185130/embed`},
+		{`<iframe src="https://mastodon.social/@Gargron/113457432853185130"></iframe>`, MastodonToAMP, `mastodon: malformed url: https://mastodon.social/@Gargron/113457432853185130`},
+		{`<blockquote class="bluesky-embed" data-bluesky-uri="at://did:plc:z72i7hdynmk6r22z27h6tvur/app.bsky.feed.post/3lbwvqbbkzc2y" data-bluesky-cid="bafyreihc"><p lang="en">Post</p>&mdash; Bluesky (<a href="https://bsky.app/profile/did:plc:z72i7hdynmk6r22z27h6tvur?ref_src=embed">@bsky.app</a>)</blockquote><script async src="https://embed.bsky.app/static/embed.js" charset="utf-8"></script>`, BlueskyToAMP, `<amp-iframe width="480" height="400" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" src="https://embed.bsky.app/embed/did:plc:z72i7hdynmk6r22z27h6tvur/app.bsky.feed.post/3lbwvqbbkzc2y"></amp-iframe>`},
+		{`<blockquote class="bluesky-embed" data-bluesky-uri="at://did:plc:z72i7hdynmk6r22z27h6tvur/app.bsky.actor.profile/self"></blockquote>`, BlueskyToAMP, `bluesky: malformed url: at://did:plc:z72i7hdynmk6r22z27h6tvur/app.bsky.actor.profile/self`},
+		{`<blockquote class="bluesky-embed"></blockquote>`, BlueskyToAMP, `bluesky: no source of embed`},
+	}
+
+	for i, test := range tests {
+		got, err := test.convert([]byte(test.input))
+		if err != nil {
+			if fmt.Sprint(err) != test.want {
+				t.Errorf("\n[%d]ToAMP() = %q,\nwant ERR    %q\n", i+1, err, test.want)
+			}
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("\n[%d]ToAMP() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+	}
+}
+
+func TestSocialPostsToTurbo(t *testing.T) {
+	var tests = []struct {
+		input   string
+		convert func([]byte) ([]byte, error)
+		want    string
+	}{
+		{`<blockquote class="text-post-media" data-text-post-permalink="https://www.threads.net/@rgru_official/post/C8Hk2pLNx3Q" data-text-post-version="0" id="ig-tp-C8Hk2pLNx3Q"><a href="https://www.threads.net/@rgru_official/post/C8Hk2pLNx3Q">Post by @rgru_official</a></blockquote><script async src="https://www.threads.net/embed.js"></script>`, ThreadsToTurbo, `<iframe frameborder="0" src="https://www.threads.com/@rgru_official/post/C8Hk2pLNx3Q/embed"></iframe>`},
+		{`<iframe src="https://mastodon.social/@Gargron/113457432853185130/embed" class="mastodon-embed" style="max-width: 100%; border: 0" width="400" allowfullscreen="allowfullscreen"></iframe><script src="https://mastodon.social/embed.js" async="async"></script>`, MastodonToTurbo, `<iframe width="400" frameborder="0" src="https://mastodon.social/@Gargron/113457432853185130/embed"></iframe>`},
+		{`<blockquote class="bluesky-embed" data-bluesky-uri="at://did:plc:z72i7hdynmk6r22z27h6tvur/app.bsky.feed.post/3lbwvqbbkzc2y" data-bluesky-cid="bafyreihc"><p lang="en">Post</p>&mdash; Bluesky (<a href="https://bsky.app/profile/did:plc:z72i7hdynmk6r22z27h6tvur?ref_src=embed">@bsky.app</a>)</blockquote><script async src="https://embed.bsky.app/static/embed.js" charset="utf-8"></script>`, BlueskyToTurbo, `<iframe frameborder="0" src="https://embed.bsky.app/embed/did:plc:z72i7hdynmk6r22z27h6tvur/app.bsky.feed.post/3lbwvqbbkzc2y"></iframe>`},
+	}
+
+	for i, test := range tests {
+		got, err := test.convert([]byte(test.input))
+		if err != nil {
+			if fmt.Sprint(err) != test.want {
+				t.Errorf("\n[%d]ToTurbo() = %q,\nwant ERR    %q\n", i+1, err, test.want)
+			}
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("\n[%d]ToTurbo() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+	}
+
+	// scripts of the embeds are not needed for iframes and removed silently
+	article, err := ArticleToTurbo([]byte(`<p>text</p><iframe src="https://mastodon.social/@Gargron/113457432853185130/embed" class="mastodon-embed" style="max-width: 100%; border: 0" width="400" allowfullscreen="allowfullscreen"></iframe><script src="https://mastodon.social/embed.js" async="async"></script><blockquote class="bluesky-embed" data-bluesky-uri="at://did:plc:z72i7hdynmk6r22z27h6tvur/app.bsky.feed.post/3lbwvqbbkzc2y" data-bluesky-cid="bafyreihc"><p lang="en">Post</p>&mdash; Bluesky (<a href="https://bsky.app/profile/did:plc:z72i7hdynmk6r22z27h6tvur?ref_src=embed">@bsky.app</a>)</blockquote><script async src="https://embed.bsky.app/static/embed.js" charset="utf-8"></script>`))
+	if err != nil {
+		t.Fatalf("ArticleToTurbo() ERROR: %q", err)
+	}
+	if len(article.Failures) > 0 || bytes.Contains(article.Body, []byte(`<script`)) {
+		t.Errorf("ArticleToTurbo() = %q, failures %v", article.Body, article.Failures)
+	}
+}
+
+func TestOkToAMP(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{
+			`<iframe width="560" height="315" src="//ok.ru/videoembed/2478134036998?nochat=1" frameborder="0" allow="autoplay" allowfullscreen></iframe>`,
+			`<amp-iframe width="560" height="315" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" allow="autoplay; encrypted-media; fullscreen; picture-in-picture" allowfullscreen src="https://ok.ru/videoembed/2478134036998"></amp-iframe>`,
+		},
+		{
+			`<div id="ok_content_widget"></div>
+<script>
+!function (d, id, did, st) {
+  var js = d.createElement("script");
+  js.src = "https://connect.ok.ru/connect.js";
+  js.onload = js.onreadystatechange = function () {
+  if (!this.readyState || this.readyState == "loaded" || this.readyState == "complete") {
+    if (!this.executed) {
+      this.executed = true;
+      setTimeout(function () {
+        OK.CONNECT.insertContentWidget(id,did,st);
+      }, 0);
+    }
+  }};
+  d.documentElement.appendChild(js);
+}(document,"ok_content_widget","{\"topicId\":\"153789034467\",\"groupId\":\"52175189753888\"}","{\"width\":550}");
+</script>`,
+			`<a href="https://ok.ru/group/52175189753888/topic/153789034467">https://ok.ru/group/52175189753888/topic/153789034467</a>`,
+		},
+		{
+			`<script src="https://connect.ok.ru/connect.js"></script><script>OK.CONNECT.insertContentWidget("ok_video", "{\"movieId\":\"2478134036998\"}", "{\"width\":\"640\",\"height\":\"360\"}");</script>`,
+			`<amp-iframe width="640" height="360" sandbox="allow-scripts allow-same-origin allow-popups" layout="responsive" frameborder="0" allow="autoplay; encrypted-media; fullscreen; picture-in-picture" allowfullscreen src="https://ok.ru/videoembed/2478134036998"></amp-iframe>`,
+		},
+		{
+			`<script>OK.CONNECT.insertContentWidget("ok_post", "{\"topicId\":\"66123\",\"userId\":\"570012\"}", "{}");</script>`,
+			`<a href="https://ok.ru/profile/570012/statuses/66123">https://ok.ru/profile/570012/statuses/66123</a>`,
+		},
+		{
+			`<script>OK.CONNECT.insertContentWidget("ok_post", "{\"topicId\":\"66123\",\"groupId\":\"-570012\"}", "{}");</script>`,
+			`odnoklassniki: malformed embed: -570012`,
+		},
+		{
+			`<script>OK.CONNECT.insertContentWidget("ok_post", "{\"topicId\":\"abc\",\"groupId\":\"570012\"}", "{}");</script>`,
+			`odnoklassniki: malformed embed: abc`,
+		},
+		{
+			`<script>OK.CONNECT.insertContentWidget("ok_post", "{}", "{}");</script>`,
+			`odnoklassniki: no source of embed`,
+		},
+		{
+			`<iframe src="https://ok.ru.example.com/videoembed/2478134036998"></iframe>`,
+			`odnoklassniki: wrong host: ok.ru.example.com`,
+		},
+		{
+			`<iframe src="https://ok.ru/videoembed/247813403699a"></iframe>`,
+			`odnoklassniki: malformed url: https://ok.ru/videoembed/247813403699a`,
+		},
+	}
+
+	for i, test := range tests {
+		got, err := OkToAMP([]byte(test.input))
+		if err != nil {
+			if fmt.Sprint(err) != test.want {
+				t.Errorf("\n[%d]OkToAMP() = %q,\nwant ERR    %q\n", i+1, err, test.want)
+			}
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("\n[%d]OkToAMP() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+	}
+}
+
+func TestOkToTurbo(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{
+			`<iframe width="560" height="315" src="//ok.ru/videoembed/2478134036998" frameborder="0" allow="autoplay" allowfullscreen></iframe>`,
+			`<iframe width="560" height="315" allowfullscreen="true" frameborder="0" src="https://ok.ru/videoembed/2478134036998"></iframe>`,
+		},
+		{
+			`<div id="ok_content_widget"></div>
+<script>
+!function (d, id, did, st) {
+  var js = d.createElement("script");
+  js.src = "https://connect.ok.ru/connect.js";
+  js.onload = js.onreadystatechange = function () {
+  if (!this.readyState || this.readyState == "loaded" || this.readyState == "complete") {
+    if (!this.executed) {
+      this.executed = true;
+      setTimeout(function () {
+        OK.CONNECT.insertContentWidget(id,did,st);
+      }, 0);
+    }
+  }};
+  d.documentElement.appendChild(js);
+}(document,"ok_content_widget","{\"topicId\":\"153789034467\",\"groupId\":\"52175189753888\"}","{\"width\":550}");
+</script>`,
+			`<a href="https://ok.ru/group/52175189753888/topic/153789034467">https://ok.ru/group/52175189753888/topic/153789034467</a>`,
+		},
+	}
+
+	for i, test := range tests {
+		got, err := OkToTurbo([]byte(test.input))
+		if err != nil {
+			if fmt.Sprint(err) != test.want {
+				t.Errorf("\n[%d]OkToTurbo() = %q,\nwant ERR    %q\n", i+1, err, test.want)
+			}
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("\n[%d]OkToTurbo() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+	}
+
+	article, err := ArticleToTurbo([]byte(`<p>text</p><div id="ok_content_widget"></div>
+<script>
+!function (d, id, did, st) {
+  var js = d.createElement("script");
+  js.src = "https://connect.ok.ru/connect.js";
+  js.onload = js.onreadystatechange = function () {
+  if (!this.readyState || this.readyState == "loaded" || this.readyState == "complete") {
+    if (!this.executed) {
+      this.executed = true;
+      setTimeout(function () {
+        OK.CONNECT.insertContentWidget(id,did,st);
+      }, 0);
+    }
+  }};
+  d.documentElement.appendChild(js);
+}(document,"ok_content_widget","{\"topicId\":\"153789034467\",\"groupId\":\"52175189753888\"}","{\"width\":550}");
+</script>`))
+	if err != nil {
+		t.Fatalf("ArticleToTurbo() ERROR: %q", err)
+	}
+	if len(article.Failures) > 0 || len(article.Embeds) != 1 || article.Embeds[0].Kind != "groupTopic" || article.Embeds[0].OwnerID != "52175189753888" {
+		t.Errorf("ArticleToTurbo() = %q, embeds %v, failures %v", article.Body, article.Embeds, article.Failures)
+	}
+}
+
+func TestChartsToAMP(t *testing.T) {
+	var tests = []struct {
+		input   string
+		convert func([]byte) ([]byte, error)
+		want    string
+	}{
+		{`<iframe title="Inflation in Russia" aria-label="Interactive line chart" id="datawrapper-chart-k4Qe7" src="https://datawrapper.dwcdn.net/k4Qe7/3/" scrolling="no" frameborder="0" style="width: 0; min-width: 100% !important; border: none;" height="447" data-external="1"></iframe><script type="text/javascript">!function(){"use strict";window.addEventListener("message",(function(a){if(void 0!==a.data["datawrapper-height"]){var e=document.querySelectorAll("iframe");for(var t in a.data["datawrapper-height"])for(var r=0;r<e.length;r++)if(e[r].contentWindow===a.source){var i=a.data["datawrapper-height"][t]+"px";e[r].style.height=i}}}))}();</script>`, DatawrapperToAMP, `<amp-iframe height="447" layout="fixed-height" sandbox="allow-scripts allow-same-origin allow-popups" resizable frameborder="0" src="https://datawrapper.dwcdn.net/k4Qe7/3/"><div overflow tabindex="0" role="button" aria-label="Show more">Show more</div></amp-iframe>`},
+		{`<iframe src="https://datawrapper.dwcdn.net/k4Qe7/"></iframe>`, DatawrapperToAMP, `<amp-iframe height="400" layout="fixed-height" sandbox="allow-scripts allow-same-origin allow-popups" resizable frameborder="0" src="https://datawrapper.dwcdn.net/k4Qe7/"><div overflow tabindex="0" role="button" aria-label="Show more">Show more</div></amp-iframe>`},
+		{`<iframe src="https://datawrapper.dwcdn.net/k4Qe7/3/embed.js"></iframe>`, DatawrapperToAMP, `datawrapper: malformed url: https://datawrapper.dwcdn.net/k4Qe7/3/embed.js`},
+		{`<div class="flourish-embed flourish-chart" data-src="visualisation/1234567"><script src="https://public.flourish.studio/resources/embed.js"></script></div>`, FlourishToAMP, `<amp-iframe height="575" layout="fixed-height" sandbox="allow-scripts allow-same-origin allow-popups" resizable frameborder="0" src="https://flo.uri.sh/visualisation/1234567/embed"><div overflow tabindex="0" role="button" aria-label="Show more">Show more</div></amp-iframe>`},
+		{`<div class="flourish-embed" data-src="story/98765" data-height="700px"></div>`, FlourishToAMP, `<amp-iframe height="700" layout="fixed-height" sandbox="allow-scripts allow-same-origin allow-popups" resizable frameborder="0" src="https://flo.uri.sh/story/98765/embed"><div overflow tabindex="0" role="button" aria-label="Show more">Show more</div></amp-iframe>`},
+		{`<iframe src="https://flo.uri.sh/visualisation/1234567/embed" height="620"></iframe>`, FlourishToAMP, `<amp-iframe height="620" layout="fixed-height" sandbox="allow-scripts allow-same-origin allow-popups" resizable frameborder="0" src="https://flo.uri.sh/visualisation/1234567/embed"><div overflow tabindex="0" role="button" aria-label="Show more">Show more</div></amp-iframe>`},
+		{`<div class="flourish-embed" data-src="template/1234567"></div>`, FlourishToAMP, `flourish: malformed url: template/1234567`},
+		{`<div class="flourish-embed"></div>`, FlourishToAMP, `flourish: no source of embed`},
+		{`<div class="infogram-embed" data-id="_/k6Fr3mXQ2Lw1Ns7ZpA0c" data-type="interactive" data-title="Budget"></div><script>!function(e,n,i,s){var d="InfogramEmbeds";var o=e.getElementsByTagName(n)[0];if(window[d]&&window[d].initialized)window[d].process&&window[d].process();else if(!e.getElementById(i)){var r=e.createElement(n);r.async=1,r.id=i,r.src=s,o.parentNode.insertBefore(r,o)}}(document,"script","infogram-async","https://e.infogram.com/js/dist/embed-loader-min.js");</script>`, InfogramToAMP, `<amp-iframe height="500" layout="fixed-height" sandbox="allow-scripts allow-same-origin allow-popups" resizable frameborder="0" src="https://e.infogram.com/_/k6Fr3mXQ2Lw1Ns7ZpA0c?src=embed"><div overflow tabindex="0" role="button" aria-label="Show more">Show more</div></amp-iframe>`},
+		{`<iframe src="https://e.infogram.com/budget-2024-1h0r6rzwz8yl4jz?src=embed" height="812"></iframe>`, InfogramToAMP, `<amp-iframe height="812" layout="fixed-height" sandbox="allow-scripts allow-same-origin allow-popups" resizable frameborder="0" src="https://e.infogram.com/budget-2024-1h0r6rzwz8yl4jz?src=embed"><div overflow tabindex="0" role="button" aria-label="Show more">Show more</div></amp-iframe>`},
+		{`<iframe src="https://infogram.example.com/budget"></iframe>`, InfogramToAMP, `infogram: wrong host: infogram.example.com`},
+		{`<div class="infogram-embed" data-id="budget&quot; onload=&quot;alert(1)"></div>`, InfogramToAMP, `infogram: malformed embed: budget" onload="alert(1)`},
+	}
+
+	for i, test := range tests {
+		got, err := test.convert([]byte(test.input))
+		if err != nil {
+			if fmt.Sprint(err) != test.want {
+				t.Errorf("\n[%d]ToAMP() = %q,\nwant ERR    %q\n", i+1, err, test.want)
+			}
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("\n[%d]ToAMP() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+	}
+
+	// loader and resize scripts are removed silently
+	article, err := ArticleToAMP([]byte(`<p>text</p><iframe title="Inflation in Russia" aria-label="Interactive line chart" id="datawrapper-chart-k4Qe7" src="https://datawrapper.dwcdn.net/k4Qe7/3/" scrolling="no" frameborder="0" style="width: 0; min-width: 100% !important; border: none;" height="447" data-external="1"></iframe><script type="text/javascript">!function(){"use strict";window.addEventListener("message",(function(a){if(void 0!==a.data["datawrapper-height"]){var e=document.querySelectorAll("iframe");for(var t in a.data["datawrapper-height"])for(var r=0;r<e.length;r++)if(e[r].contentWindow===a.source){var i=a.data["datawrapper-height"][t]+"px";e[r].style.height=i}}}))}();</script><div class="flourish-embed flourish-chart" data-src="visualisation/1234567"><script src="https://public.flourish.studio/resources/embed.js"></script></div><div class="infogram-embed" data-id="_/k6Fr3mXQ2Lw1Ns7ZpA0c" data-type="interactive" data-title="Budget"></div><script>!function(e,n,i,s){var d="InfogramEmbeds";var o=e.getElementsByTagName(n)[0];if(window[d]&&window[d].initialized)window[d].process&&window[d].process();else if(!e.getElementById(i)){var r=e.createElement(n);r.async=1,r.id=i,r.src=s,o.parentNode.insertBefore(r,o)}}(document,"script","infogram-async","https://e.infogram.com/js/dist/embed-loader-min.js");</script>`))
+	if err != nil {
+		t.Fatalf("ArticleToAMP() ERROR: %q", err)
+	}
+	if len(article.Failures) > 0 || len(article.Embeds) != 3 || bytes.Contains(article.Body, []byte(`<script`)) {
+		t.Errorf("ArticleToAMP() = %q, embeds %v, failures %v", article.Body, article.Embeds, article.Failures)
+	}
+	if diagnostics := Validate(article.Body); len(diagnostics) > 0 {
+		t.Errorf("Validate(ArticleToAMP()) = %q", diagnostics)
+	}
+}
+
+func TestChartsToTurbo(t *testing.T) {
+	var tests = []struct {
+		input   string
+		convert func([]byte) ([]byte, error)
+		want    string
+	}{
+		{`<iframe title="Inflation in Russia" aria-label="Interactive line chart" id="datawrapper-chart-k4Qe7" src="https://datawrapper.dwcdn.net/k4Qe7/3/" scrolling="no" frameborder="0" style="width: 0; min-width: 100% !important; border: none;" height="447" data-external="1"></iframe><script type="text/javascript">!function(){"use strict";window.addEventListener("message",(function(a){if(void 0!==a.data["datawrapper-height"]){var e=document.querySelectorAll("iframe");for(var t in a.data["datawrapper-height"])for(var r=0;r<e.length;r++)if(e[r].contentWindow===a.source){var i=a.data["datawrapper-height"][t]+"px";e[r].style.height=i}}}))}();</script>`, DatawrapperToTurbo, `<iframe height="447" frameborder="0" scrolling="no" src="https://datawrapper.dwcdn.net/k4Qe7/3/"></iframe>`},
+		{`<div class="flourish-embed flourish-chart" data-src="visualisation/1234567"><script src="https://public.flourish.studio/resources/embed.js"></script></div>`, FlourishToTurbo, `<iframe height="575" frameborder="0" scrolling="no" src="https://flo.uri.sh/visualisation/1234567/embed"></iframe>`},
+		{`<div class="infogram-embed" data-id="_/k6Fr3mXQ2Lw1Ns7ZpA0c" data-type="interactive" data-title="Budget"></div><script>!function(e,n,i,s){var d="InfogramEmbeds";var o=e.getElementsByTagName(n)[0];if(window[d]&&window[d].initialized)window[d].process&&window[d].process();else if(!e.getElementById(i)){var r=e.createElement(n);r.async=1,r.id=i,r.src=s,o.parentNode.insertBefore(r,o)}}(document,"script","infogram-async","https://e.infogram.com/js/dist/embed-loader-min.js");</script>`, InfogramToTurbo, `<iframe height="500" frameborder="0" scrolling="no" src="https://e.infogram.com/_/k6Fr3mXQ2Lw1Ns7ZpA0c?src=embed"></iframe>`},
+	}
+
+	for i, test := range tests {
+		got, err := test.convert([]byte(test.input))
+		if err != nil {
+			if fmt.Sprint(err) != test.want {
+				t.Errorf("\n[%d]ToTurbo() = %q,\nwant ERR    %q\n", i+1, err, test.want)
+			}
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("\n[%d]ToTurbo() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+	}
+
+	article, err := ArticleToTurbo([]byte(`<p>text</p><iframe title="Inflation in Russia" aria-label="Interactive line chart" id="datawrapper-chart-k4Qe7" src="https://datawrapper.dwcdn.net/k4Qe7/3/" scrolling="no" frameborder="0" style="width: 0; min-width: 100% !important; border: none;" height="447" data-external="1"></iframe><script type="text/javascript">!function(){"use strict";window.addEventListener("message",(function(a){if(void 0!==a.data["datawrapper-height"]){var e=document.querySelectorAll("iframe");for(var t in a.data["datawrapper-height"])for(var r=0;r<e.length;r++)if(e[r].contentWindow===a.source){var i=a.data["datawrapper-height"][t]+"px";e[r].style.height=i}}}))}();</script><div class="flourish-embed flourish-chart" data-src="visualisation/1234567"><script src="https://public.flourish.studio/resources/embed.js"></script></div><div class="infogram-embed" data-id="_/k6Fr3mXQ2Lw1Ns7ZpA0c" data-type="interactive" data-title="Budget"></div><script>!function(e,n,i,s){var d="InfogramEmbeds";var o=e.getElementsByTagName(n)[0];if(window[d]&&window[d].initialized)window[d].process&&window[d].process();else if(!e.getElementById(i)){var r=e.createElement(n);r.async=1,r.id=i,r.src=s,o.parentNode.insertBefore(r,o)}}(document,"script","infogram-async","https://e.infogram.com/js/dist/embed-loader-min.js");</script>`))
+	if err != nil {
+		t.Fatalf("ArticleToTurbo() ERROR: %q", err)
+	}
+	if len(article.Failures) > 0 || len(article.Embeds) != 3 || bytes.Contains(article.Body, []byte(`<script`)) {
+		t.Errorf("ArticleToTurbo() = %q, embeds %v, failures %v", article.Body, article.Embeds, article.Failures)
+	}
+	if diagnostics := ValidateTurbo(article.Body); len(diagnostics) > 0 {
+		t.Errorf("ValidateTurbo(ArticleToTurbo()) = %q", diagnostics)
+	}
+}
+
+func TestFbSDKToAMP(t *testing.T) {
+	var tests = []struct {
+		input string
+		want  string
+	}{
+		{
+			`<div id="fb-root"></div><script async defer crossorigin="anonymous" src="https://connect.facebook.net/ru_RU/sdk.js#xfbml=1&version=v19.0"></script><div class="fb-post" data-href="https://www.facebook.com/stcnk/posts/3384458724928901" data-width="500" data-show-text="true"><blockquote cite="https://www.facebook.com/stcnk/posts/3384458724928901" class="fb-xfbml-parse-ignore">Post</blockquote></div>`,
+			`<amp-facebook height="500" width="500" layout="responsive" data-href="https://www.facebook.com/stcnk/posts/3384458724928901"></amp-facebook>`,
+		},
+		{
+			`<div class="fb-video" data-href="https://www.facebook.com/nasaearth/videos/456540998570328/" data-width="560" data-height="315" data-allowfullscreen="true"></div>`,
+			`<amp-facebook height="315" width="560" layout="responsive" data-embed-as="video" data-href="https://www.facebook.com/nasaearth/videos/456540998570328/"></amp-facebook>`,
+		},
+		{
+			`<div class="fb-comments" data-href="https://rg.ru/2024/05/27/news.html" data-width="" data-numposts="5"></div>`,
+			`<amp-facebook-comments height="657" width="486" layout="responsive" data-numposts="5" data-href="https://rg.ru/2024/05/27/news.html"></amp-facebook-comments>`,
+		},
+		{
+			`<div class="fb-page" data-href="https://www.facebook.com/rgru" data-tabs="timeline" data-width="500" data-height="" data-small-header="false" data-adapt-container-width="true" data-hide-cover="false" data-show-facepile="true"></div>`,
+			`<amp-facebook-page height="130" width="500" layout="responsive" data-hide-cover="false" data-show-facepile="true" data-small-header="false" data-tabs="timeline" data-href="https://www.facebook.com/rgru"></amp-facebook-page>`,
+		},
+		{
+			`<div class="fb-like" data-href="https://rg.ru/?a=1&amp;b=2" data-width="" data-layout="button_count" data-action="like" data-size="small" data-share="true"></div>`,
+			`<amp-facebook-like height="28" layout="fixed-height" data-action="like" data-layout="button_count" data-share="true" data-size="small" data-href="https://rg.ru/?a=1&amp;b=2"></amp-facebook-like>`,
+		},
+		{
+			`<div class="fb-page" data-href="https://rg.ru/rgru"></div>`,
+			`facebook: wrong host: rg.ru`,
+		},
+		{
+			`<div class="fb-comments" data-href="javascript:alert(1)"></div>`,
+			`facebook: malformed url: javascript:alert(1)`,
+		},
+		{
+			`<div class="fb-comments" data-numposts="5"></div>`,
+			`facebook: no source of embed`,
+		},
+	}
+
+	for i, test := range tests {
+		got, err := FbToAMP([]byte(test.input))
+		if err != nil {
+			if fmt.Sprint(err) != test.want {
+				t.Errorf("\n[%d]FbToAMP() = %q,\nwant ERR    %q\n", i+1, err, test.want)
+			}
+			continue
+		}
+
+		if string(got) != test.want {
+			t.Errorf("\n[%d]FbToAMP() = %q,\nwant        %q\n", i+1, got, test.want)
+		}
+	}
+
+	article, err := ArticleToAMP([]byte(`<p>text</p><div id="fb-root"></div><script async defer crossorigin="anonymous" src="https://connect.facebook.net/ru_RU/sdk.js#xfbml=1&version=v19.0"></script><div class="fb-post" data-href="https://www.facebook.com/stcnk/posts/3384458724928901" data-width="500" data-show-text="true"><blockquote cite="https://www.facebook.com/stcnk/posts/3384458724928901" class="fb-xfbml-parse-ignore">Post</blockquote></div><div class="fb-comments" data-href="https://rg.ru/2024/05/27/news.html" data-width="" data-numposts="5"></div>`))
+	if err != nil {
+		t.Fatalf("ArticleToAMP() ERROR: %q", err)
+	}
+	if len(article.Failures) > 0 || len(article.Embeds) != 2 || article.Embeds[1].Kind != "comments" {
+		t.Errorf("ArticleToAMP() = %q, embeds %v, failures %v", article.Body, article.Embeds, article.Failures)
+	}
+	if diagnostics := Validate(article.Body); len(diagnostics) > 0 {
+		t.Errorf("Validate(ArticleToAMP()) = %q", diagnostics)
+	}
+}
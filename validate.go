@@ -0,0 +1,472 @@
+package turboamper
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Diagnostic is a problem found by validator
+type Diagnostic struct {
+	// Line and Column are 1-based position of the problem, column is counted in runes
+	Line   int
+	Column int
+	// Tag is a name of the element with the problem, it may be empty
+	Tag     string
+	Message string
+}
+
+func (d Diagnostic) String() string {
+	if d.Tag == "" {
+		return fmt.Sprintf("%d:%d: %s", d.Line, d.Column, d.Message)
+	}
+
+	return fmt.Sprintf("%d:%d: %s: %s", d.Line, d.Column, d.Tag, d.Message)
+}
+
+// Layouts of AMP components, see https://amp.dev/documentation/guides-and-tutorials/learn/amp-html-layout/
+const (
+	layoutNodisplay   = "nodisplay"
+	layoutFixed       = "fixed"
+	layoutResponsive  = "responsive"
+	layoutFixedHeight = "fixed-height"
+	layoutFill        = "fill"
+	layoutContainer   = "container"
+	layoutFlexItem    = "flex-item"
+	layoutIntrinsic   = "intrinsic"
+)
+
+// ampRule is validation rule of AMP component
+type ampRule struct {
+	// required are attributes required by the component, alternatives are separated by |
+	required []string
+	// layouts are layouts supported by the component
+	layouts []string
+	// secure are attributes which should be https urls
+	secure []string
+}
+
+// embedLayouts are layouts supported by most of embed components
+var embedLayouts = []string{layoutFill, layoutFixed, layoutFixedHeight, layoutFlexItem, layoutNodisplay, layoutResponsive}
+
+// ampRules is a subset of AMP validator rules for components made by turboamper,
+// see https://github.com/ampproject/amphtml/tree/main/extensions
+var ampRules = map[string]ampRule{
+	"amp-img": {
+		required: []string{"src|srcset"},
+		layouts:  []string{layoutFill, layoutFixed, layoutFixedHeight, layoutFlexItem, layoutIntrinsic, layoutNodisplay, layoutResponsive},
+	},
+	"amp-iframe": {
+		required: []string{"src|srcdoc"},
+		layouts:  []string{layoutFill, layoutFixed, layoutFixedHeight, layoutFlexItem, layoutIntrinsic, layoutNodisplay, layoutResponsive},
+		secure:   []string{"src"},
+	},
+	"amp-vk": {
+		required: []string{"data-embedtype"},
+		layouts:  []string{layoutFlexItem, layoutFixed, layoutFixedHeight, layoutResponsive},
+	},
+	"amp-facebook": {
+		required: []string{"data-href"},
+		layouts:  embedLayouts,
+		secure:   []string{"data-href"},
+	},
+	"amp-instagram": {
+		required: []string{"data-shortcode|src"},
+		layouts:  embedLayouts,
+	},
+	"amp-twitter": {
+		required: []string{"data-tweetid|data-momentid|data-timeline-source-type"},
+		layouts:  embedLayouts,
+	},
+	"amp-youtube": {
+		required: []string{"data-videoid|data-live-channelid"},
+		layouts:  embedLayouts,
+	},
+	"amp-tiktok": {
+		required: []string{"data-src"},
+		layouts:  embedLayouts,
+	},
+	"amp-vimeo": {
+		required: []string{"data-videoid"},
+		layouts:  embedLayouts,
+	},
+	"amp-dailymotion": {
+		required: []string{"data-videoid"},
+		layouts:  embedLayouts,
+	},
+	"amp-pinterest": {
+		required: []string{"data-do"},
+		layouts:  embedLayouts,
+	},
+	"amp-reddit": {
+		required: []string{"data-embedtype", "data-src"},
+		layouts:  embedLayouts,
+	},
+	"amp-soundcloud": {
+		required: []string{"data-trackid|data-playlistid"},
+		layouts:  []string{layoutFixedHeight},
+	},
+	"amp-facebook-comments": {
+		required: []string{"data-href"},
+		layouts:  embedLayouts,
+	},
+	"amp-facebook-page": {
+		required: []string{"data-href"},
+		layouts:  embedLayouts,
+	},
+	"amp-facebook-like": {
+		required: []string{"data-href"},
+		layouts:  embedLayouts,
+	},
+	"amp-video": {
+		layouts: []string{layoutFill, layoutFixed, layoutFixedHeight, layoutFlexItem, layoutIntrinsic, layoutNodisplay, layoutResponsive},
+	},
+	"amp-audio": {
+		layouts: []string{layoutFixed, layoutFixedHeight, layoutNodisplay},
+	},
+	"amp-playbuzz": {
+		required: []string{"src|data-item"},
+		layouts:  []string{layoutResponsive, layoutFixedHeight},
+		secure:   []string{"src"},
+	},
+}
+
+// forbiddenTags are tags which AMP does not allow, values are explanations
+var forbiddenTags = map[string]string{
+	"script":   "only AMP runtime, component and JSON-LD scripts are allowed",
+	"style":    "only amp-boilerplate and amp-custom styles are allowed",
+	"iframe":   "use amp-iframe",
+	"frame":    "tag is not allowed",
+	"frameset": "tag is not allowed",
+	"object":   "tag is not allowed",
+	"embed":    "tag is not allowed",
+	"param":    "tag is not allowed",
+	"applet":   "tag is not allowed",
+	"img":      "use amp-img",
+	"video":    "use amp-video",
+	"audio":    "use amp-audio",
+}
+
+// Validate checks given AMP html against embedded subset of AMP validator rules:
+// forbidden tags, required attributes and layouts of components, width and height,
+// https sources and closing of components. Whole documents are also checked
+// for required markup and component scripts. It returns nothing if html is valid.
+func Validate(ampHTML []byte) []Diagnostic {
+	v := ampValidator{input: ampHTML, required: make(map[string]bool), scripts: make(map[string]bool)}
+	v.run()
+
+	return v.diagnostics
+}
+
+type openTag struct {
+	name   string
+	offset int
+}
+
+type ampValidator struct {
+	input       []byte
+	diagnostics []Diagnostic
+	// open are opened AMP components waiting for their end tags
+	open     []openTag
+	noscript int
+
+	// html is a root of whole document
+	html *openTag
+	// required are found requirements of whole document
+	required map[string]bool
+	// scripts are found scripts of components
+	scripts map[string]bool
+	// offset is an offset of current token
+	offset int
+}
+
+// Requirements of whole AMP document
+const (
+	reqCharset     = `<meta charset="utf-8">`
+	reqViewport    = `<meta name="viewport">`
+	reqCanonical   = `<link rel="canonical">`
+	reqRuntime     = `<script async src="https://cdn.ampproject.org/v0.js">`
+	reqBoilerplate = `<style amp-boilerplate>`
+)
+
+func (v *ampValidator) run() {
+	z := html.NewTokenizer(bytes.NewReader(v.input))
+	for {
+		tt := z.Next()
+		size := len(z.Raw())
+		if tt == html.ErrorToken {
+			if z.Err() != io.EOF {
+				v.report(v.offset, "", z.Err().Error())
+			} else if size > 0 && z.Raw()[0] == '<' {
+				v.report(v.offset, "", "unexpected end of input inside tag")
+			}
+			break
+		}
+
+		token := z.Token()
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			v.startTag(token, tt == html.SelfClosingTagToken)
+		case html.EndTagToken:
+			v.endTag(token)
+		}
+		v.offset += size
+	}
+
+	for _, tag := range v.open {
+		v.report(tag.offset, tag.name, "tag is not closed")
+	}
+
+	if v.html != nil {
+		v.document()
+	}
+}
+
+// startTag checks start tag of element
+func (v *ampValidator) startTag(token html.Token, selfClosing bool) {
+	name := token.Data
+
+	for _, a := range token.Attr {
+		if len(a.Key) > 2 && strings.HasPrefix(a.Key, "on") {
+			v.report(v.offset, name, "event handler attribute "+a.Key+" is not allowed")
+		}
+	}
+
+	switch name {
+	case "html":
+		v.html = &openTag{name: name, offset: v.offset}
+		if !hasAttr(token, "⚡") && !hasAttr(token, "amp") {
+			v.report(v.offset, name, "missing attribute ⚡ or amp")
+		}
+		return
+	case "noscript":
+		v.noscript++
+		return
+	case "meta":
+		if strings.EqualFold(attr(token, "charset"), "utf-8") {
+			v.required[reqCharset] = true
+		}
+		if attr(token, "name") == "viewport" {
+			v.required[reqViewport] = true
+		}
+		return
+	case "link":
+		if attr(token, "rel") == "canonical" {
+			v.required[reqCanonical] = true
+		}
+		return
+	case "script":
+		v.script(token)
+		return
+	case "style":
+		switch {
+		case hasAttr(token, "amp-boilerplate"):
+			v.required[reqBoilerplate] = true
+			return
+		case hasAttr(token, "amp-custom"):
+			return
+		}
+	}
+
+	if reason, ok := forbiddenTags[name]; ok {
+		// images and media are allowed as a fallback for browsers without scripts
+		if v.noscript == 0 || name == "script" || name == "style" {
+			v.report(v.offset, name, reason)
+		}
+		return
+	}
+
+	if !strings.HasPrefix(name, "amp-") {
+		return
+	}
+
+	if !selfClosing {
+		v.open = append(v.open, openTag{name: name, offset: v.offset})
+	} else {
+		v.report(v.offset, name, "tag should be closed by end tag")
+	}
+
+	rule, ok := ampRules[name]
+	if !ok {
+		return
+	}
+
+	for _, required := range rule.required {
+		found := false
+		for _, key := range strings.Split(required, "|") {
+			if hasAttr(token, key) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.report(v.offset, name, "missing attribute "+strings.Replace(required, "|", " or ", -1))
+		}
+	}
+
+	for _, key := range rule.secure {
+		if val := attr(token, key); val != "" && !strings.HasPrefix(val, "https://") {
+			v.report(v.offset, name, "attribute "+key+" should be https url")
+		}
+	}
+
+	v.layout(token, rule)
+}
+
+// layout checks layout of component and its width and height
+func (v *ampValidator) layout(token html.Token, rule ampRule) {
+	name := token.Data
+	width, height := attr(token, "width"), attr(token, "height")
+
+	layout := strings.ToLower(attr(token, "layout"))
+	if layout == "" {
+		// the same way AMP runtime infers the layout
+		switch {
+		case width != "" && height != "":
+			layout = layoutFixed
+		case height != "" && (width == "" || width == "auto"):
+			layout = layoutFixedHeight
+		default:
+			layout = layoutContainer
+		}
+	}
+
+	supported := false
+	for _, l := range rule.layouts {
+		if l == layout {
+			supported = true
+			break
+		}
+	}
+	if !supported {
+		if layout == layoutContainer {
+			v.report(v.offset, name, "missing attributes width and height")
+		} else {
+			v.report(v.offset, name, "layout "+layout+" is not supported")
+		}
+		return
+	}
+
+	switch layout {
+	case layoutFixed, layoutResponsive, layoutIntrinsic:
+		if width == "" || width == "auto" {
+			v.report(v.offset, name, "missing attribute width for layout "+layout)
+		}
+		if height == "" {
+			v.report(v.offset, name, "missing attribute height for layout "+layout)
+		}
+	case layoutFixedHeight:
+		if height == "" {
+			v.report(v.offset, name, "missing attribute height for layout "+layout)
+		}
+		if width != "" && width != "auto" {
+			v.report(v.offset, name, "attribute width should be auto or absent for layout "+layout)
+		}
+	}
+}
+
+// script checks that the script is AMP runtime, component or JSON-LD
+func (v *ampValidator) script(token html.Token) {
+	src := attr(token, "src")
+
+	switch {
+	case attr(token, "type") == "application/ld+json" && src == "":
+	case src == "https://cdn.ampproject.org/v0.js":
+		v.required[reqRuntime] = true
+	case hasAttr(token, "custom-element"):
+		element := attr(token, "custom-element")
+		if !strings.HasPrefix(src, "https://cdn.ampproject.org/v0/"+element+"-") {
+			v.report(v.offset, "script", "wrong src of "+element+" script")
+		}
+		v.scripts[element] = true
+	case hasAttr(token, "custom-template"):
+	default:
+		v.report(v.offset, "script", forbiddenTags["script"])
+	}
+}
+
+// endTag closes AMP component
+func (v *ampValidator) endTag(token html.Token) {
+	name := token.Data
+	if name == "noscript" && v.noscript > 0 {
+		v.noscript--
+		return
+	}
+	if !strings.HasPrefix(name, "amp-") {
+		return
+	}
+
+	for i := len(v.open) - 1; i >= 0; i-- {
+		if v.open[i].name != name {
+			continue
+		}
+		// components opened after this one are not closed
+		for _, tag := range v.open[i+1:] {
+			v.report(tag.offset, tag.name, "tag is not closed")
+		}
+		v.open = v.open[:i]
+		return
+	}
+
+	v.report(v.offset, name, "unexpected end tag")
+}
+
+// document checks requirements of whole AMP document
+func (v *ampValidator) document() {
+	for _, req := range []string{reqCharset, reqViewport, reqCanonical, reqRuntime, reqBoilerplate} {
+		if !v.required[req] {
+			v.report(v.html.offset, "", "missing "+req)
+		}
+	}
+
+	for _, element := range CustomElements(v.input) {
+		if !v.scripts[element] {
+			v.report(v.html.offset, element, "missing script of the component")
+		}
+	}
+}
+
+// report adds diagnostic at given offset of input
+func (v *ampValidator) report(offset int, tag, message string) {
+	v.diagnostics = append(v.diagnostics, newDiagnostic(v.input, offset, tag, message))
+}
+
+// newDiagnostic returns diagnostic at given offset of input
+func newDiagnostic(input []byte, offset int, tag, message string) Diagnostic {
+	line, column := 1, 1
+	for _, r := range string(input[:offset]) {
+		if r == '\n' {
+			line++
+			column = 1
+			continue
+		}
+		column++
+	}
+
+	return Diagnostic{Line: line, Column: column, Tag: tag, Message: message}
+}
+
+// attr returns value of the token attribute
+func attr(token html.Token, key string) string {
+	for _, a := range token.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+
+	return ""
+}
+
+// hasAttr tells if the token has given attribute
+func hasAttr(token html.Token, key string) bool {
+	for _, a := range token.Attr {
+		if a.Key == key {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,272 @@
+package turboamper
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// turboTags are elements allowed in turbo:content,
+// see https://yandex.ru/dev/turbo/doc/rss/markup.html
+var turboTags = map[string]bool{
+	"header": true, "menu": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"p": true, "br": true, "hr": true, "div": true, "span": true,
+	"ul": true, "ol": true, "li": true, "dl": true, "dt": true, "dd": true,
+	"b": true, "strong": true, "i": true, "em": true, "u": true, "s": true,
+	"sup": true, "sub": true, "ins": true, "del": true, "small": true, "big": true,
+	"mark": true, "q": true, "cite": true, "abbr": true, "code": true, "pre": true,
+	"a": true, "blockquote": true, "button": true,
+	"figure": true, "figcaption": true, "img": true, "video": true, "source": true, "iframe": true,
+	"table": true, "caption": true, "thead": true, "tbody": true, "tfoot": true, "tr": true, "th": true, "td": true,
+	"script": true,
+}
+
+// turboHeaderTags are elements allowed in header
+var turboHeaderTags = map[string]bool{
+	"h1": true, "h2": true, "figure": true, "img": true, "figcaption": true, "menu": true, "a": true, "div": true,
+}
+
+// turboMediaTags are elements which figure should contain
+var turboMediaTags = map[string]bool{
+	"img": true, "video": true, "iframe": true,
+}
+
+// turboEmbedClasses are classes of social network blocks which Yandex Turbo shows as is
+var turboEmbedClasses = map[string]bool{
+	"instagram-media": true,
+	"twitter-tweet":   true,
+	"reddit-card":     true,
+	"reddit-embed-bq": true,
+}
+
+// voidTags are elements without end tags
+var voidTags = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// ValidateTurbo checks given content of Yandex Turbo item against Turbo markup rules:
+// allowed elements, header and figure structure, iframe requirements, scripts and
+// social network blocks. It returns nothing if content is valid.
+func ValidateTurbo(content []byte) []Diagnostic {
+	v := turboValidator{input: content}
+	v.run()
+
+	return v.diagnostics
+}
+
+// turboElement is an opened element
+type turboElement struct {
+	name   string
+	offset int
+	// media tells if the figure has media inside
+	media bool
+	// title tells if the header has h1 inside
+	title bool
+	// embed tells if the element is social network block
+	embed bool
+}
+
+type turboValidator struct {
+	input       []byte
+	diagnostics []Diagnostic
+	open        []*turboElement
+	headers     int
+	offset      int
+}
+
+func (v *turboValidator) run() {
+	z := html.NewTokenizer(bytes.NewReader(v.input))
+	for {
+		tt := z.Next()
+		size := len(z.Raw())
+		if tt == html.ErrorToken {
+			if z.Err() != io.EOF {
+				v.report(v.offset, "", z.Err().Error())
+			} else if size > 0 && z.Raw()[0] == '<' {
+				v.report(v.offset, "", "unexpected end of input inside tag")
+			}
+			break
+		}
+
+		token := z.Token()
+		switch tt {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			v.startTag(token)
+			if tt == html.StartTagToken && !voidTags[token.Data] {
+				v.push(token)
+			}
+			if token.Data == "script" {
+				if tt == html.StartTagToken {
+					v.script(token, z, size)
+					continue
+				}
+				v.sdk(v.offset, attr(token, "src"))
+			}
+		case html.EndTagToken:
+			v.endTag(token.Data, v.offset+size)
+		}
+		v.offset += size
+	}
+
+	for len(v.open) > 0 {
+		v.endTag(v.open[len(v.open)-1].name, len(v.input))
+	}
+}
+
+// startTag checks start tag of element
+func (v *turboValidator) startTag(token html.Token) {
+	name := token.Data
+
+	if !turboTags[name] {
+		v.report(v.offset, name, "tag is not allowed in Yandex Turbo")
+		return
+	}
+
+	for _, a := range token.Attr {
+		if len(a.Key) > 2 && strings.HasPrefix(a.Key, "on") {
+			v.report(v.offset, name, "event handler attribute "+a.Key+" is not allowed")
+		}
+	}
+
+	if header := v.parent("header"); header != nil {
+		if !turboHeaderTags[name] {
+			v.report(v.offset, name, "tag is not allowed in header")
+		}
+		if name == "h1" {
+			header.title = true
+		}
+	}
+	if figure := v.parent("figure"); figure != nil && turboMediaTags[name] {
+		figure.media = true
+	}
+
+	switch name {
+	case "header":
+		v.headers++
+		if v.headers > 1 {
+			v.report(v.offset, name, "only one header is allowed")
+		}
+	case "figcaption":
+		if v.parent("figure") == nil {
+			v.report(v.offset, name, "figcaption should be inside figure")
+		}
+	case "img":
+		if attr(token, "src") == "" {
+			v.report(v.offset, name, "missing attribute src")
+		}
+	case "iframe":
+		src := attr(token, "src")
+		switch {
+		case src == "":
+			v.report(v.offset, name, "missing attribute src")
+		case !strings.HasPrefix(src, "https://"):
+			v.report(v.offset, name, "attribute src should be https url")
+		}
+	}
+}
+
+// script checks that the script loads SDK of social network or draws known widget.
+// It consumes script text, so the offset is moved to the end tag of script.
+func (v *turboValidator) script(token html.Token, z *html.Tokenizer, size int) {
+	start := v.offset
+	end := start + size
+
+	tt := z.Next()
+	if tt == html.TextToken {
+		end += len(z.Raw())
+		tt = z.Next()
+	}
+	if tt == html.EndTagToken {
+		end += len(z.Raw())
+	}
+
+	if src := attr(token, "src"); src != "" {
+		v.sdk(start, src)
+	} else {
+		v.embed(start, "script", v.input[start:end])
+	}
+
+	v.endTag("script", end)
+	v.offset = end
+}
+
+// sdk checks that the script of given src is SDK of social network
+func (v *turboValidator) sdk(offset int, src string) {
+	if src == "" {
+		v.report(offset, "script", "missing attribute src")
+		return
+	}
+	if urlPtr, err := url.Parse(src); err != nil || !sdkHosts[urlPtr.Hostname()] {
+		v.report(offset, "script", "script of "+src+" is not supported")
+	}
+}
+
+// push opens element
+func (v *turboValidator) push(token html.Token) {
+	element := &turboElement{name: token.Data, offset: v.offset}
+	if token.Data == "blockquote" {
+		for _, class := range strings.Fields(attr(token, "class")) {
+			if turboEmbedClasses[class] {
+				element.embed = true
+			}
+		}
+	}
+	v.open = append(v.open, element)
+}
+
+// endTag closes element which ends at given offset
+func (v *turboValidator) endTag(name string, end int) {
+	for i := len(v.open) - 1; i >= 0; i-- {
+		element := v.open[i]
+		if element.name != name {
+			continue
+		}
+		v.open = v.open[:i]
+
+		switch {
+		case name == "header" && !element.title:
+			v.report(element.offset, name, "header should contain h1")
+		case name == "figure" && !element.media:
+			v.report(element.offset, name, "figure should contain img, video or iframe")
+		case element.embed:
+			v.embed(element.offset, name, v.input[element.offset:end])
+		}
+		return
+	}
+}
+
+// embed checks social network block by its provider
+func (v *turboValidator) embed(offset int, tag string, snippet []byte) {
+	_, _, err := represent(snippet, Provider.Turbo)
+	if err == nil {
+		return
+	}
+	if errors.Is(err, ErrUnknownEmbed) {
+		v.report(offset, tag, "unknown embed is not supported")
+		return
+	}
+
+	v.report(offset, tag, err.Error())
+}
+
+// parent returns the nearest opened element with given name
+func (v *turboValidator) parent(name string) *turboElement {
+	for i := len(v.open) - 1; i >= 0; i-- {
+		if v.open[i].name == name {
+			return v.open[i]
+		}
+	}
+
+	return nil
+}
+
+// report adds diagnostic at given offset of input
+func (v *turboValidator) report(offset int, tag, message string) {
+	v.diagnostics = append(v.diagnostics, newDiagnostic(v.input, offset, tag, message))
+}